@@ -0,0 +1,19 @@
+package api
+
+import (
+	"github.com/chryscloud/go-microkit-plugins/auth"
+	jwtModels "github.com/chryscloud/go-microkit-plugins/models/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// jwtUserID returns ID of the authenticated user (empty if JWT is disabled)
+func jwtUserID(c *gin.Context) string {
+	claims, ok := c.Get(auth.JWTClaimsContextKey)
+	if !ok {
+		return ""
+	}
+	if userClaim, ok := claims.(*jwtModels.UserClaim); ok {
+		return userClaim.ID
+	}
+	return ""
+}
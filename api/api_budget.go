@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+	"github.com/mailio/mailio-nft-server/util"
+)
+
+type BudgetAPI struct {
+	service  *service.BudgetService
+	validate *validator.Validate
+}
+
+func NewBudgetAPI(service *service.BudgetService) *BudgetAPI {
+	return &BudgetAPI{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Get budget
+// @Security     ApiKeyAuth
+// @Summary      Get spending budget
+// @Description  Returns global spending budget settings of the broker wallet and todays spending (in native currency)
+// @Tags         Budget
+// @Success      200  {object}  model.BudgetStatus
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/budget [get]
+func (ba *BudgetAPI) GetBudget(c *gin.Context) {
+	status, err := ba.service.GetStatus()
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Put budget
+// @Security     ApiKeyAuth
+// @Summary      Set spending budget
+// @Description  Sets daily limit, per transaction fee ceiling and default catalog limit in native currency (e.g. "0.5"). Empty value means no limit
+// @Tags         Budget
+// @Param        budget  body      model.BudgetSettings  true  "budget settings"
+// @Success      200     {object}  model.BudgetSettings
+// @Failure      400     {object}  api.JSONError  "invalid input"
+// @Failure      500     {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/budget [put]
+func (ba *BudgetAPI) PutBudget(c *gin.Context) {
+	settings := &model.BudgetSettings{}
+	if err := c.ShouldBindJSON(settings); err != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	for _, amount := range []string{settings.DailyLimit, settings.MaxTxFee, settings.DefaultCatalogLimit} {
+		if amount == "" {
+			continue
+		}
+		if _, err := util.NativeToWei(amount); err != nil {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	settings, err := ba.service.PutSettings(settings)
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// Get catalog budget
+// @Security     ApiKeyAuth
+// @Summary      Get catalog spending budget
+// @Description  Returns effective spending limit of the catalog and total spent on it (in native currency)
+// @Tags         Budget
+// @Param        catalogId  path      string  true  "catalog id"
+// @Success      200        {object}  model.CatalogBudgetStatus
+// @Failure      500        {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/budget/catalog/{catalogId} [get]
+func (ba *BudgetAPI) GetCatalogBudget(c *gin.Context) {
+	status, err := ba.service.GetCatalogStatus(c.Param("catalogId"))
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Put catalog budget
+// @Security     ApiKeyAuth
+// @Summary      Set catalog spending budget
+// @Description  Overrides the default catalog limit for a single catalog (in native currency)
+// @Tags         Budget
+// @Param        catalogId  path      string               true  "catalog id"
+// @Param        budget     body      model.CatalogBudget  true  "catalog budget"
+// @Success      200        {object}  model.CatalogBudget
+// @Failure      400        {object}  api.JSONError  "invalid input"
+// @Failure      500        {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/budget/catalog/{catalogId} [put]
+func (ba *BudgetAPI) PutCatalogBudget(c *gin.Context) {
+	budget := &model.CatalogBudget{}
+	if err := c.ShouldBindJSON(budget); err != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if err := ba.validate.Struct(budget); err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := util.NativeToWei(budget.Limit); err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	budget.CatalogId = c.Param("catalogId")
+	budget, err := ba.service.PutCatalogBudget(budget)
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, budget)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -24,12 +25,13 @@ func NewNftCatalogAPI(service *service.NftCatalogService) *NftCatalogAPI {
 
 // Get Catalog
 // @Summary      Get Catalog
-// @Description  Get Catalog by id
+// @Description  Get published Catalog by id in the language requested by lang or Accept-Language (falls back to the default language)
 // @Tags         Catalog
-// @Param        id   path      string  true  "id"
-// @Success      200  {object}  model.Catalog
-// @Failure      404  {object}  api.JSONError  "catalog not found"
-// @Failure      500    {object}  api.JSONError  "internal server error"
+// @Param        id    path      string  true   "id"
+// @Param        lang  query     string  false  "language (e.g. de)"
+// @Success      200   {object}  model.Catalog
+// @Failure      404   {object}  api.JSONError  "catalog not found"
+// @Failure      500   {object}  api.JSONError  "internal server error"
 // @Accept       json
 // @Produce      json
 // @Router       /v1/catalog/{id} [get]
@@ -44,13 +46,19 @@ func (ca *NftCatalogAPI) GetCatalog(c *gin.Context) {
 		AbortWithError(c, http.StatusInternalServerError, "internal server error")
 		return
 	}
-	c.JSON(http.StatusOK, cat)
+	if !cat.IsPublished() {
+		AbortWithError(c, http.StatusNotFound, "catalog not found")
+		return
+	}
+	lang := cat.MatchLanguage(preferredLanguages(c))
+	c.Header("Content-Language", lang)
+	c.JSON(http.StatusOK, cat.Localized(lang))
 }
 
 // Upsert catalog
 // @Security     ApiKeyAuth
 // @Summary      Upsert Catalog
-// @Description  When ID is given with the POST object then it's an update, otherwise insert
+// @Description  When ID is given with the POST object then it's an update, otherwise insert. New catalogs are drafts unless status is given (status transitions are enforced). Updates without publishAt keep the stored publishAt
 // @Tags         Catalog
 // @Param        catalog  body      model.Catalog  true  "catalog"
 // @Success      200      {object}  model.Catalog
@@ -66,14 +74,17 @@ func (ca *NftCatalogAPI) PutCatalog(c *gin.Context) {
 		AbortWithError(c, http.StatusBadRequest, "invalid json body")
 		return
 	}
-	err := ca.validate.Struct(cat)
-	if err != nil {
+	if err := ca.service.ValidateCatalog(cat); err != nil {
 		AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	cat, err = ca.service.PutCatalog(cat)
+	cat, err := ca.service.PutCatalog(cat, jwtUserID(c))
 	if err != nil {
+		if errors.Is(err, model.ErrInvalidStatus) {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		AbortWithError(c, http.StatusInternalServerError, "internal server error")
 		return
 	}
@@ -82,17 +93,185 @@ func (ca *NftCatalogAPI) PutCatalog(c *gin.Context) {
 
 // List Catalogs
 // @Summary      List Catalog
-// @Description  List Catalogs
+// @Description  Search published Catalogs by type, keyword and full text (name and description in all languages) with number of matching catalogs per type. Catalogs are returned in the language requested by lang or Accept-Language
 // @Tags         Catalog
-// @Param        limit  query     int  false  "limit"
-// @Success      200    {array}   model.Catalog
-// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Param        type     query     string  false  "catalog type"
+// @Param        keyword  query     string  false  "keyword"
+// @Param        q        query     string  false  "full text search over name and description"
+// @Param        sort     query     string  false  "relevance, created (default), modified or popularity"
+// @Param        order    query     string  false  "asc or desc (default)"
+// @Param        limit    query     int     false  "page size (max 100)"
+// @Param        cursor   query     string  false  "next cursor of the previous page"
+// @Param        lang     query     string  false  "language (e.g. de)"
+// @Success      200      {object}  model.CatalogSearchResult
+// @Failure      400      {object}  api.JSONError  "invalid search"
+// @Failure      500      {object}  api.JSONError  "internal server error"
 // @Accept       json
 // @Produce      json
 // @Router       /v1/catalog [get]
 func (ca *NftCatalogAPI) ListCatalogs(c *gin.Context) {
+	search, err := ca.catalogSearch(c)
+	if err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	search.Status = model.CatalogStatusPublished
+	result, err := ca.service.SearchCatalogs(search)
+	if err != nil {
+		if err == model.ErrInvalidCursor {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	languages := preferredLanguages(c)
+	for i, cat := range result.Catalogs {
+		result.Catalogs[i] = cat.Localized(cat.MatchLanguage(languages))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Admin list catalogs
+// @Security     ApiKeyAuth
+// @Summary      List catalogs (admin)
+// @Description  Search catalogs in all lifecycle states (same filters as the public list)
+// @Tags         Catalog
+// @Param        status   query     string  false  "status (draft, scheduled, published, archived)"
+// @Param        type     query     string  false  "catalog type"
+// @Param        keyword  query     string  false  "keyword"
+// @Param        q        query     string  false  "full text search over name and description"
+// @Param        sort     query     string  false  "relevance, created (default), modified or popularity"
+// @Param        order    query     string  false  "asc or desc (default)"
+// @Param        limit    query     int     false  "page size (max 100)"
+// @Param        cursor   query     string  false  "next cursor of the previous page"
+// @Success      200      {object}  model.CatalogSearchResult
+// @Failure      400      {object}  api.JSONError  "invalid search"
+// @Failure      500      {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/admin/catalog [get]
+func (ca *NftCatalogAPI) AdminListCatalogs(c *gin.Context) {
+	search, err := ca.catalogSearch(c)
+	if err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	result, err := ca.service.SearchCatalogs(search)
+	if err != nil {
+		if err == model.ErrInvalidCursor {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// catalogSearch reads and validates search query parameters
+func (ca *NftCatalogAPI) catalogSearch(c *gin.Context) (*model.CatalogSearch, error) {
+	search := &model.CatalogSearch{
+		Type:    c.Query("type"),
+		Keyword: c.Query("keyword"),
+		Query:   c.Query("q"),
+		Sort:    c.Query("sort"),
+		Order:   c.Query("order"),
+		Status:  c.Query("status"),
+		Cursor:  c.Query("cursor"),
+	}
+	limit, err := pageSize(c)
+	if err != nil {
+		return nil, err
+	}
+	search.Limit = limit
+	if err := ca.validate.Struct(search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// Admin get catalog
+// @Security     ApiKeyAuth
+// @Summary      Get catalog (admin)
+// @Description  Get catalog in any lifecycle state by id
+// @Tags         Catalog
+// @Param        id   path      string  true  "id"
+// @Success      200  {object}  model.Catalog
+// @Failure      404  {object}  api.JSONError  "catalog not found"
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/admin/catalog/{id} [get]
+func (ca *NftCatalogAPI) AdminGetCatalog(c *gin.Context) {
+	cat, err := ca.service.GetCatalog(c.Param("id"))
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "catalog not found")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+// Change catalog status
+// @Security     ApiKeyAuth
+// @Summary      Change catalog status
+// @Description  Allowed transitions: draft -> scheduled, published, archived; scheduled -> draft, published, archived; published -> archived; archived -> draft, published. Scheduled requires publishAt in the future
+// @Tags         Catalog
+// @Param        id      path      string                     true  "id"
+// @Param        status  body      model.CatalogStatusChange  true  "status"
+// @Success      200     {object}  model.Catalog
+// @Failure      400     {object}  api.JSONError  "invalid status transition"
+// @Failure      404     {object}  api.JSONError  "catalog not found"
+// @Failure      500     {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/admin/catalog/{id}/status [put]
+func (ca *NftCatalogAPI) SetStatus(c *gin.Context) {
+	change := &model.CatalogStatusChange{}
+	if err := c.ShouldBindJSON(change); err != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if err := ca.validate.Struct(change); err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	cat, err := ca.service.SetStatus(c.Param("id"), change, jwtUserID(c))
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "catalog not found")
+			return
+		}
+		if errors.Is(err, model.ErrInvalidStatus) {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+// List catalog revisions
+// @Security     ApiKeyAuth
+// @Summary      List catalog revisions
+// @Description  Revisions of the catalog with author and changed fields (newest first)
+// @Tags         Catalog
+// @Param        id     path      string  true   "catalog id"
+// @Param        limit  query     int     false  "limit"
+// @Success      200    {array}   model.CatalogRevision
+// @Failure      400    {object}  api.JSONError  "invalid limit"
+// @Failure      500    {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/catalog/{id}/revisions [get]
+func (ca *NftCatalogAPI) ListRevisions(c *gin.Context) {
 	limitStr := c.Query("limit")
-	limit := 500
+	limit := 50
 	if limitStr != "" {
 		l, cErr := strconv.Atoi(limitStr)
 		if cErr != nil {
@@ -101,10 +280,112 @@ func (ca *NftCatalogAPI) ListCatalogs(c *gin.Context) {
 		}
 		limit = l
 	}
-	cats, err := ca.service.ListAllCatalogs(limit)
+	revisions, err := ca.service.ListRevisions(c.Param("id"), limit)
 	if err != nil {
 		AbortWithError(c, http.StatusInternalServerError, "internal server error")
 		return
 	}
-	c.JSON(http.StatusOK, cats)
+	c.JSON(http.StatusOK, revisions)
+}
+
+// Get catalog revision
+// @Security     ApiKeyAuth
+// @Summary      Get catalog revision
+// @Description  Revision of the catalog with the catalog snapshot
+// @Tags         Catalog
+// @Param        id        path      string  true  "catalog id"
+// @Param        revision  path      int     true  "revision"
+// @Success      200       {object}  model.CatalogRevision
+// @Failure      400       {object}  api.JSONError  "invalid revision"
+// @Failure      404       {object}  api.JSONError  "revision not found"
+// @Failure      500       {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/catalog/{id}/revisions/{revision} [get]
+func (ca *NftCatalogAPI) GetRevision(c *gin.Context) {
+	revision, rErr := strconv.Atoi(c.Param("revision"))
+	if rErr != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid revision")
+		return
+	}
+	rev, err := ca.service.GetRevision(c.Param("id"), revision)
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "revision not found")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, rev)
+}
+
+// Diff catalog revisions
+// @Security     ApiKeyAuth
+// @Summary      Diff catalog revisions
+// @Description  Changed fields between two revisions of the catalog
+// @Tags         Catalog
+// @Param        id    path      string  true   "catalog id"
+// @Param        from  query     int     true   "revision"
+// @Param        to    query     int     false  "revision (latest by default)"
+// @Success      200   {object}  model.CatalogRevisionDiff
+// @Failure      400   {object}  api.JSONError  "invalid revision"
+// @Failure      404   {object}  api.JSONError  "revision not found"
+// @Failure      500   {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/catalog/{id}/diff [get]
+func (ca *NftCatalogAPI) DiffRevisions(c *gin.Context) {
+	from, fErr := strconv.Atoi(c.Query("from"))
+	to, tErr := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if fErr != nil || tErr != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid revision")
+		return
+	}
+	diff, err := ca.service.DiffRevisions(c.Param("id"), from, to)
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "revision not found")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// Restore catalog revision
+// @Security     ApiKeyAuth
+// @Summary      Restore catalog revision
+// @Description  Stores the catalog as it was at the given revision (as a new revision). The restored catalog is validated with the current rules
+// @Tags         Catalog
+// @Param        id        path      string  true  "catalog id"
+// @Param        revision  path      int     true  "revision"
+// @Success      200       {object}  model.Catalog
+// @Failure      400       {object}  api.JSONError  "invalid revision or restored catalog"
+// @Failure      404       {object}  api.JSONError  "revision not found"
+// @Failure      500       {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/catalog/{id}/revisions/{revision}/restore [post]
+func (ca *NftCatalogAPI) RestoreRevision(c *gin.Context) {
+	revision, rErr := strconv.Atoi(c.Param("revision"))
+	if rErr != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid revision")
+		return
+	}
+	cat, err := ca.service.RestoreRevision(c.Param("id"), revision, jwtUserID(c))
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "revision not found")
+			return
+		}
+		if errors.Is(err, model.ErrInvalidCatalog) || errors.Is(err, model.ErrInvalidStatus) {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, cat)
 }
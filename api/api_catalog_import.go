@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+// max size of the catalogs import file
+const catalogImportMaxSize = 10 << 20
+
+// content types of the catalog export formats
+var catalogExportContentTypes = map[string]string{
+	model.CatalogFormatJSON: "application/json",
+	model.CatalogFormatYAML: "application/x-yaml",
+	model.CatalogFormatCSV:  "text/csv",
+}
+
+type NftCatalogImportAPI struct {
+	service *service.NftCatalogImportService
+}
+
+func NewNftCatalogImportAPI(service *service.NftCatalogImportService) *NftCatalogImportAPI {
+	return &NftCatalogImportAPI{
+		service: service,
+	}
+}
+
+// Import catalogs
+// @Security     ApiKeyAuth
+// @Summary      Import catalogs
+// @Description  Upserts catalogs of a JSON, YAML or CSV file (matched by id or externalRef) validated with the same rules as catalog upsert. Runs as a dry-run unless dryRun=false. Nothing is stored if any row is invalid. Images referenced by imageFile are uploaded with the file before any catalog is stored. Catalogs are stored in file order until a write fails (stored rows are flagged), unchanged catalogs are skipped so the import can be re-run
+// @Tags         Catalog
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file    formData  file    true   "catalogs (.json, .yaml, .yml or .csv)"
+// @Param        images  formData  file    false  "images referenced by imageFile of the catalogs (repeated)"
+// @Param        format  query     string  false  "json, yaml or csv (by file extension by default)"
+// @Param        dryRun  query     bool    false  "only validate (default true)"
+// @Success      200     {object}  model.CatalogImportResult
+// @Failure      400     {object}  api.JSONError  "invalid import file"
+// @Failure      500     {object}  api.JSONError  "internal server error"
+// @Router       /v1/admin/catalogs/import [post]
+func (ci *NftCatalogImportAPI) Import(c *gin.Context) {
+	dryRun, dErr := strconv.ParseBool(c.DefaultQuery("dryRun", "true"))
+	if dErr != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid dryRun")
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		AbortWithError(c, http.StatusBadRequest, "no import file received")
+		return
+	}
+	format := c.DefaultQuery("format", service.CatalogFormat(file.Filename))
+	if format == "" {
+		AbortWithError(c, http.StatusBadRequest, "unknown import format")
+		return
+	}
+	data, err := readImportFile(file)
+	if err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid multipart form")
+		return
+	}
+	images := map[string]*multipart.FileHeader{}
+	for _, image := range form.File["images"] {
+		if _, ok := images[image.Filename]; ok {
+			AbortWithError(c, http.StatusBadRequest, "duplicate image file name "+image.Filename)
+			return
+		}
+		images[image.Filename] = image
+	}
+
+	result, err := ci.service.Import(data, format, images, dryRun, jwtUserID(c))
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidImport) {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Export catalogs
+// @Security     ApiKeyAuth
+// @Summary      Export catalogs
+// @Description  Exports catalogs in the import format (JSON, YAML or CSV with JSON encoded nested fields)
+// @Tags         Catalog
+// @Produce      json
+// @Param        format  query     string  false  "json (default), yaml or csv"
+// @Param        status  query     string  false  "status (draft, scheduled, published, archived)"
+// @Success      200     {array}   model.Catalog
+// @Failure      400     {object}  api.JSONError  "invalid format or status"
+// @Failure      500     {object}  api.JSONError  "internal server error"
+// @Router       /v1/admin/catalogs/export [get]
+func (ci *NftCatalogImportAPI) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", model.CatalogFormatJSON)
+	contentType, ok := catalogExportContentTypes[format]
+	if !ok {
+		AbortWithError(c, http.StatusBadRequest, "invalid format")
+		return
+	}
+	status := c.Query("status")
+	if _, ok := model.CatalogStatusTransitions[status]; status != "" && !ok {
+		AbortWithError(c, http.StatusBadRequest, "invalid status")
+		return
+	}
+	var out bytes.Buffer
+	if err := ci.service.Export(&out, format, status); err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename=catalogs."+format)
+	c.Data(http.StatusOK, contentType, out.Bytes())
+}
+
+func readImportFile(file *multipart.FileHeader) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		lc.Log.Error("failed to read import file", err)
+		return nil, errors.New("failed to read import file")
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(f, catalogImportMaxSize+1))
+	if err != nil {
+		lc.Log.Error("failed to read import file", err)
+		return nil, errors.New("failed to read import file")
+	}
+	if len(data) > catalogImportMaxSize {
+		return nil, errors.New("import file too large")
+	}
+	return data, nil
+}
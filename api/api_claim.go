@@ -3,7 +3,6 @@ package api
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
@@ -19,14 +18,16 @@ import (
 type ClaimAPI struct {
 	service             *service.NftClaimService
 	catalogService      *service.NftCatalogService
+	funnelService       *service.FunnelService
 	validate            *validator.Validate
 	httpClientReCaptcha *resty.Client
 }
 
-func NewClaimAPI(service *service.NftClaimService, catalogService *service.NftCatalogService) *ClaimAPI {
+func NewClaimAPI(service *service.NftClaimService, catalogService *service.NftCatalogService, funnelService *service.FunnelService) *ClaimAPI {
 	return &ClaimAPI{
 		service:             service,
 		catalogService:      catalogService,
+		funnelService:       funnelService,
 		validate:            validator.New(),
 		httpClientReCaptcha: resty.New().SetHostURL(lc.Conf.ReCaptchaV3.Host),
 	}
@@ -35,29 +36,41 @@ func NewClaimAPI(service *service.NftClaimService, catalogService *service.NftCa
 // List Claims
 // @Summary      List Claims
 // @Security     ApiKeyAuth
-// @Description  List Latest claims
+// @Description  List latest claims newest first, optionally of a catalog, wallet or visitor created within a time range (page size is capped to 100)
 // @Tags         Claiming
-// @Param        limit  query     int  false  "limit"
-// @Success      200    {array}   model.Claim
-// @Failure      500    {object}  api.JSONError  "internal server error"
+// @Param        catalogId  query     string  false  "claims of the catalog"
+// @Param        wallet     query     string  false  "claims of the wallet"
+// @Param        visitorId  query     string  false  "claims of the visitor"
+// @Param        since      query     int     false  "created at or after (unix ms)"
+// @Param        until      query     int     false  "created before (unix ms)"
+// @Param        limit      query     int     false  "page size"
+// @Param        cursor     query     string  false  "next cursor of the previous page"
+// @Param        total      query     bool    false  "count all selected claims"
+// @Success      200        {object}  model.ClaimPage
+// @Failure      400        {object}  api.JSONError  "invalid filter, limit or cursor"
+// @Failure      500        {object}  api.JSONError  "internal server error"
 // @Accept       json
 // @Produce      json
 // @Router       /v1/claim [get]
 func (ca *ClaimAPI) ListClaims(c *gin.Context) {
+	limit, lErr := pageSize(c)
+	if lErr != nil {
+		AbortWithError(c, http.StatusBadRequest, lErr.Error())
+		return
+	}
 
-	limitStr := c.Query("limit")
-	limit := 50
-	if limitStr != "" {
-		l, cErr := strconv.Atoi(limitStr)
-		if cErr != nil {
-			AbortWithError(c, http.StatusBadRequest, "invalid limit")
-			return
-		}
-		limit = l
+	filter, fErr := claimFilter(c)
+	if fErr != nil {
+		AbortWithError(c, http.StatusBadRequest, fErr.Error())
+		return
 	}
 
-	claims, err := ca.service.ListClaims(limit)
+	claims, err := ca.service.ListClaimsPage(filter, limit, c.Query("cursor"), c.Query("total") == "true")
 	if err != nil {
+		if err == model.ErrInvalidCursor {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		AbortWithError(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
@@ -66,36 +79,52 @@ func (ca *ClaimAPI) ListClaims(c *gin.Context) {
 
 // SafeMint new NFT
 // @Summary      Mint new NFT
-// @Description  Mints the new NFT based on the category selected. All NFTs are on Polygon
+// @Description  Mints the new NFT based on the category selected. All NFTs are on Polygon. Metadata is generated in the claim language (or negotiated with Accept-Language)
 // @Tags         Claiming
 // @Param        claim  body      model.Claim  true  "eip-712 signed claim"
 // @Success      200    {array}   model.Claim
 // @Failure      403    {object}  api.JSONError  "captacha failed"
 // @Failure      400    {object}  api.JSONError  "invalid input"
 // @Failure      500    {object}  api.JSONError  "internal server error"
+// @Failure      503    {object}  api.JSONError  "temporarily unavailable"
 // @Accept       json
 // @Produce      json
 // @Router       /v1/claim [post]
 func (ca *ClaimAPI) MintClaim(c *gin.Context) {
 	claim := &model.Claim{}
 	if err := c.ShouldBindJSON(claim); err != nil {
+		ca.funnelService.Failed(model.FunnelSubmitted, model.FunnelReasonInvalidInput, "", "", "")
 		AbortWithError(c, http.StatusBadRequest, "invalid json body")
 		return
 	}
 	err := ca.validate.Struct(claim)
 	if err != nil {
+		ca.funnelService.Failed(model.FunnelSubmitted, model.FunnelReasonInvalidInput, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
 		AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	catalog, cErr := ca.catalogService.GetCatalog(claim.CatalogId)
 	if cErr != nil {
+		ca.funnelService.Failed(model.FunnelSubmitted, model.FunnelReasonCatalogUnavailable, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
 		AbortWithError(c, http.StatusBadRequest, "Catalog invalid")
 		return
 	}
+	if !catalog.IsPublished() {
+		ca.funnelService.Failed(model.FunnelSubmitted, model.FunnelReasonCatalogUnavailable, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
+		AbortWithError(c, http.StatusBadRequest, "Catalog is not available for claiming")
+		return
+	}
+	// metadata and keywords in the claimant's language
+	if claim.Language != "" {
+		claim.Language = catalog.MatchLanguage([]string{claim.Language})
+	} else {
+		claim.Language = catalog.MatchLanguage(preferredLanguages(c))
+	}
 
 	_, fpErr := ca.service.GetVisitorClaimFingerprint(claim.CatalogId, claim.VisitorId)
 	if fpErr == nil {
+		ca.funnelService.Failed(model.FunnelSubmitted, model.FunnelReasonDuplicateFingerprint, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
 		AbortWithError(c, http.StatusBadRequest, "You've already claimed NFT for this catalog")
 		return
 	}
@@ -104,6 +133,7 @@ func (ca *ClaimAPI) MintClaim(c *gin.Context) {
 		AbortWithError(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	ca.funnelService.Passed(model.FunnelSubmitted, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
 
 	// validate captcha v3
 	reCaptchaResp, captchaErr := ca.httpClientReCaptcha.R().
@@ -115,19 +145,23 @@ func (ca *ClaimAPI) MintClaim(c *gin.Context) {
 			"response": claim.ReCaptchaToken,
 		}).Post("")
 	if captchaErr != nil {
+		ca.funnelService.Failed(model.FunnelCaptcha, model.FunnelReasonCaptchaError, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
 		AbortWithError(c, http.StatusInternalServerError, "Failed retrieving recaptcha response")
 		return
 	}
 	var reCaptcha model.ReCaptchaV3Response
 	reErr := json.Unmarshal(reCaptchaResp.Body(), &reCaptcha)
 	if reErr != nil {
+		ca.funnelService.Failed(model.FunnelCaptcha, model.FunnelReasonCaptchaError, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
 		AbortWithError(c, http.StatusInternalServerError, "Failed parsing recaptcha response")
 		return
 	}
 	if !reCaptcha.Success {
+		ca.funnelService.Failed(model.FunnelCaptcha, model.FunnelReasonCaptchaFailed, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
 		AbortWithError(c, http.StatusForbidden, "Failed captcha validation")
 		return
 	}
+	ca.funnelService.Passed(model.FunnelCaptcha, claim.CatalogId, claim.WalletAddress, claim.VisitorId)
 
 	_, claim, err = ca.service.MintForUser(claim, catalog)
 	if err != nil {
@@ -143,6 +177,14 @@ func (ca *ClaimAPI) MintClaim(c *gin.Context) {
 			AbortWithError(c, http.StatusBadRequest, "Invalid keywords. Please review the content again")
 			return
 		}
+		if err == model.ErrSoldOut {
+			AbortWithError(c, http.StatusBadRequest, "All NFTs of this catalog have been claimed")
+			return
+		}
+		if err == model.ErrBudget {
+			AbortWithError(c, http.StatusServiceUnavailable, "Claiming is temporarily unavailable. Please try again later")
+			return
+		}
 		AbortWithError(c, http.StatusInternalServerError, "Failed interacting with onchain contract")
 		return
 	}
@@ -184,22 +226,30 @@ func (nca *ClaimAPI) SigningPayload(c *gin.Context) {
 	address := c.Param("address")
 
 	// validate if catalogId exists
-	_, catErr := nca.catalogService.GetCatalog(catalogId)
+	catalog, catErr := nca.catalogService.GetCatalog(catalogId)
 	if catErr != nil {
 		if catErr == model.ErrNotFound {
+			nca.funnelService.Failed(model.FunnelPayload, model.FunnelReasonCatalogUnavailable, catalogId, address, "")
 			AbortWithError(c, http.StatusNotFound, "Catalog not found")
 			return
 		}
 		AbortWithError(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
+	if !catalog.IsPublished() {
+		nca.funnelService.Failed(model.FunnelPayload, model.FunnelReasonCatalogUnavailable, catalogId, address, "")
+		AbortWithError(c, http.StatusNotFound, "Catalog not found")
+		return
+	}
 	// validate if user hasnt already claimed the same category
 	_, errClaim := nca.service.GetClaim(catalogId, address)
 	if errClaim != model.ErrNotFound {
 		// has to be not found
+		nca.funnelService.Failed(model.FunnelPayload, model.FunnelReasonAlreadyClaimed, catalogId, address, "")
 		AbortWithError(c, http.StatusBadRequest, "You have already claimed this NFT")
 		return
 	}
+	nca.funnelService.Passed(model.FunnelPayload, catalogId, address, "")
 
 	// prepare data to sign according to EIP-712
 	sd := apitypes.TypedData{}
@@ -217,30 +267,32 @@ func (nca *ClaimAPI) SigningPayload(c *gin.Context) {
 }
 
 // @Summary      Get claimed tx log
-// @Description  Reads the claimed transaction log (all mailio claimed NFTs)
+// @Description  Reads the claimed transaction log (all mailio claimed NFTs, page size is capped to 100)
 // @Tags         Claiming
 // @Param        walletaddress  path      string  true   "users wallet address"
-// @Param        limit          query     string  false  "limit"
-// @Success      200            {array}   model.ClaimPreview
-// @Failure      500        {object}  api.JSONError  "internal server error"
+// @Param        limit          query     int     false  "page size"
+// @Param        cursor         query     string  false  "next cursor of the previous page"
+// @Param        total          query     bool    false  "count all claims of the wallet"
+// @Success      200            {object}  model.ClaimPreviewPage
+// @Failure      400            {object}  api.JSONError  "invalid limit or cursor"
+// @Failure      500            {object}  api.JSONError  "internal server error"
 // @Accept       json
 // @Produce      json
 // @Router       /v1/user/claims/{walletaddress} [get]
 func (nca *ClaimAPI) ListClaimsByUser(c *gin.Context) {
 	walletAddress := c.Param("walletaddress")
-	limitStr := c.Query("limit")
-	limit := 50
-	if limitStr != "" {
-		l, cErr := strconv.Atoi(limitStr)
-		if cErr != nil {
-			AbortWithError(c, http.StatusBadRequest, "invalid limit")
-			return
-		}
-		limit = l
+	limit, lErr := pageSize(c)
+	if lErr != nil {
+		AbortWithError(c, http.StatusBadRequest, lErr.Error())
+		return
 	}
 
-	claimPreviews, err := nca.service.ReadClaimedTransactionLogs(walletAddress, limit)
+	claimPreviews, err := nca.service.ReadClaimedTransactionLogs(walletAddress, limit, c.Query("cursor"), c.Query("total") == "true")
 	if err != nil {
+		if err == model.ErrInvalidCursor {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		AbortWithError(c, http.StatusInternalServerError, "Something went wrong")
 		return
 	}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+type ContractAdminAPI struct {
+	service  *service.ContractAdminService
+	validate *validator.Validate
+}
+
+func NewContractAdminAPI(service *service.ContractAdminService) *ContractAdminAPI {
+	return &ContractAdminAPI{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Contract status
+// @Security     ApiKeyAuth
+// @Summary      Contract status
+// @Description  Returns paused state of the Mailio NFT contract and roles of the broker and admin wallets
+// @Tags         Contract Admin
+// @Success      200  {object}  model.ContractStatus
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/status [get]
+func (caa *ContractAdminAPI) GetStatus(c *gin.Context) {
+	status, err := caa.service.GetStatus()
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "failed reading contract status")
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Contract role membership
+// @Security     ApiKeyAuth
+// @Summary      Contract role membership
+// @Description  Checks if account has the contract role
+// @Tags         Contract Admin
+// @Param        role     path      string  true  "MINTER_ROLE or PAUSER_ROLE"
+// @Param        account  path      string  true  "account address"
+// @Success      200      {object}  model.RoleMembership
+// @Failure      400      {object}  api.JSONError  "invalid input"
+// @Failure      500      {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/roles/{role}/{account} [get]
+func (caa *ContractAdminAPI) HasRole(c *gin.Context) {
+	input := &model.RoleChangeInput{
+		Role:    c.Param("role"),
+		Account: c.Param("account"),
+	}
+	if err := caa.validate.Struct(input); err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	membership, err := caa.service.HasRole(input.Role, input.Account)
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "failed reading contract role")
+		return
+	}
+	c.JSON(http.StatusOK, membership)
+}
+
+// Pause contract
+// @Security     ApiKeyAuth
+// @Summary      Pause contract
+// @Description  Pauses the Mailio NFT contract (signed by the admin wallet which requires PAUSER_ROLE)
+// @Tags         Contract Admin
+// @Success      200  {object}  model.ContractAdminTx
+// @Failure      403  {object}  api.JSONError  "admin wallet is missing the role"
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/pause [post]
+func (caa *ContractAdminAPI) Pause(c *gin.Context) {
+	tx, err := caa.service.Pause()
+	caa.respondWithTransaction(c, tx, err)
+}
+
+// Unpause contract
+// @Security     ApiKeyAuth
+// @Summary      Unpause contract
+// @Description  Unpauses the Mailio NFT contract (signed by the admin wallet which requires PAUSER_ROLE)
+// @Tags         Contract Admin
+// @Success      200  {object}  model.ContractAdminTx
+// @Failure      403  {object}  api.JSONError  "admin wallet is missing the role"
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/unpause [post]
+func (caa *ContractAdminAPI) Unpause(c *gin.Context) {
+	tx, err := caa.service.Unpause()
+	caa.respondWithTransaction(c, tx, err)
+}
+
+// Grant contract role
+// @Security     ApiKeyAuth
+// @Summary      Grant contract role
+// @Description  Grants MINTER_ROLE or PAUSER_ROLE to the account (signed by the admin wallet)
+// @Tags         Contract Admin
+// @Param        role  body      model.RoleChangeInput  true  "role and account"
+// @Success      200   {object}  model.ContractAdminTx
+// @Failure      400   {object}  api.JSONError  "invalid input"
+// @Failure      403   {object}  api.JSONError  "admin wallet is missing the role"
+// @Failure      500   {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/roles/grant [post]
+func (caa *ContractAdminAPI) GrantRole(c *gin.Context) {
+	input, ok := caa.bindRoleChangeInput(c)
+	if !ok {
+		return
+	}
+	tx, err := caa.service.GrantRole(input)
+	caa.respondWithTransaction(c, tx, err)
+}
+
+// Revoke contract role
+// @Security     ApiKeyAuth
+// @Summary      Revoke contract role
+// @Description  Revokes MINTER_ROLE or PAUSER_ROLE from the account (signed by the admin wallet)
+// @Tags         Contract Admin
+// @Param        role  body      model.RoleChangeInput  true  "role and account"
+// @Success      200   {object}  model.ContractAdminTx
+// @Failure      400   {object}  api.JSONError  "invalid input"
+// @Failure      403   {object}  api.JSONError  "admin wallet is missing the role"
+// @Failure      500   {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/roles/revoke [post]
+func (caa *ContractAdminAPI) RevokeRole(c *gin.Context) {
+	input, ok := caa.bindRoleChangeInput(c)
+	if !ok {
+		return
+	}
+	tx, err := caa.service.RevokeRole(input)
+	caa.respondWithTransaction(c, tx, err)
+}
+
+// List contract admin transactions
+// @Security     ApiKeyAuth
+// @Summary      List contract admin transactions
+// @Description  Lists transactions sent by the admin wallet (latest first)
+// @Tags         Contract Admin
+// @Param        limit  query     int  false  "limit"
+// @Success      200    {array}   model.ContractAdminTx
+// @Failure      500    {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/transactions [get]
+func (caa *ContractAdminAPI) ListTransactions(c *gin.Context) {
+	limitStr := c.Query("limit")
+	limit := 50
+	if limitStr != "" {
+		l, cErr := strconv.Atoi(limitStr)
+		if cErr != nil {
+			AbortWithError(c, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = l
+	}
+	txs, err := caa.service.ListTransactions(limit)
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, txs)
+}
+
+func (caa *ContractAdminAPI) bindRoleChangeInput(c *gin.Context) (*model.RoleChangeInput, bool) {
+	input := &model.RoleChangeInput{}
+	if err := c.ShouldBindJSON(input); err != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid json body")
+		return nil, false
+	}
+	if err := caa.validate.Struct(input); err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return input, true
+}
+
+func (caa *ContractAdminAPI) respondWithTransaction(c *gin.Context, tx *model.ContractAdminTx, err error) {
+	if err != nil {
+		if err == model.ErrMissingRole {
+			AbortWithError(c, http.StatusForbidden, "Admin wallet is missing the required contract role")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "Failed interacting with onchain contract")
+		return
+	}
+	c.JSON(http.StatusOK, tx)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+type ContractUpgradeAPI struct {
+	service  *service.ContractUpgradeService
+	validate *validator.Validate
+}
+
+func NewContractUpgradeAPI(service *service.ContractUpgradeService) *ContractUpgradeAPI {
+	return &ContractUpgradeAPI{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Check contract upgrade
+// @Security     ApiKeyAuth
+// @Summary      Check contract upgrade
+// @Description  Checks proxiableUUID of the new implementation, compatibility of its ABI (required) with the embedded MailioNFT ABI and simulates the upgrade
+// @Tags         Contract Admin
+// @Param        upgrade  body      model.ContractUpgradeInput  true  "new implementation"
+// @Success      200      {object}  model.ContractUpgradeCheck
+// @Failure      400      {object}  api.JSONError  "invalid input"
+// @Failure      500      {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/upgrade/check [post]
+func (cua *ContractUpgradeAPI) CheckUpgrade(c *gin.Context) {
+	input, ok := cua.bindUpgradeInput(c)
+	if !ok {
+		return
+	}
+	check, err := cua.service.Check(input)
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "Failed interacting with onchain contract")
+		return
+	}
+	c.JSON(http.StatusOK, check)
+}
+
+// Upgrade contract
+// @Security     ApiKeyAuth
+// @Summary      Upgrade contract
+// @Description  Runs all upgrade checks, upgrades the proxy to the new implementation and waits for the Upgraded event
+// @Tags         Contract Admin
+// @Param        upgrade  body      model.ContractUpgradeInput  true  "new implementation"
+// @Success      200      {object}  model.ContractUpgrade
+// @Failure      400      {object}  api.JSONError  "invalid input or upgrade checks failed"
+// @Failure      500      {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/upgrade [post]
+func (cua *ContractUpgradeAPI) Upgrade(c *gin.Context) {
+	input, ok := cua.bindUpgradeInput(c)
+	if !ok {
+		return
+	}
+	upgrade, check, err := cua.service.Upgrade(input)
+	if err != nil {
+		if err == model.ErrUpgradeCheck {
+			AbortWithError(c, http.StatusBadRequest, "Upgrade checks failed: "+strings.Join(check.Errors, "; "))
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "Failed interacting with onchain contract")
+		return
+	}
+	c.JSON(http.StatusOK, upgrade)
+}
+
+// List contract upgrades
+// @Security     ApiKeyAuth
+// @Summary      List contract upgrades
+// @Description  Lists recorded upgrades of the Mailio NFT proxy (latest first)
+// @Tags         Contract Admin
+// @Param        limit  query     int  false  "limit"
+// @Success      200    {array}   model.ContractUpgrade
+// @Failure      500    {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/upgrades [get]
+func (cua *ContractUpgradeAPI) ListUpgrades(c *gin.Context) {
+	limitStr := c.Query("limit")
+	limit := 50
+	if limitStr != "" {
+		l, cErr := strconv.Atoi(limitStr)
+		if cErr != nil {
+			AbortWithError(c, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = l
+	}
+	upgrades, err := cua.service.ListUpgrades(limit)
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, upgrades)
+}
+
+// Contract implementation
+// @Security     ApiKeyAuth
+// @Summary      Contract implementation
+// @Description  Returns the stored implementation address of the Mailio NFT proxy
+// @Tags         Contract Admin
+// @Success      200  {object}  model.ContractImplementation
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/implementation [get]
+func (cua *ContractUpgradeAPI) GetImplementation(c *gin.Context) {
+	impl, err := cua.service.GetImplementation()
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, impl)
+}
+
+func (cua *ContractUpgradeAPI) bindUpgradeInput(c *gin.Context) (*model.ContractUpgradeInput, bool) {
+	input := &model.ContractUpgradeInput{}
+	if err := c.ShouldBindJSON(input); err != nil {
+		AbortWithError(c, http.StatusBadRequest, "invalid json body")
+		return nil, false
+	}
+	if err := cua.validate.Struct(input); err != nil {
+		AbortWithError(c, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return input, true
+}
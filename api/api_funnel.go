@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+type FunnelAPI struct {
+	service *service.FunnelService
+}
+
+func NewFunnelAPI(service *service.FunnelService) *FunnelAPI {
+	return &FunnelAPI{
+		service: service,
+	}
+}
+
+// Claim funnel
+// @Security     ApiKeyAuth
+// @Summary      Claim funnel
+// @Description  Conversion rates of claim steps (payload, submitted, captcha, signature, keywords, mint, confirmed) with failure reason codes, in total and per catalog
+// @Tags         Stats
+// @Param        catalogId  query     string  false  "events of the catalog"
+// @Param        from       query     int     false  "recorded at or after (unix ms, default 7 days before to)"
+// @Param        to         query     int     false  "recorded before (unix ms, default now)"
+// @Success      200        {object}  model.FunnelReport
+// @Failure      400        {object}  api.JSONError  "invalid time range"
+// @Failure      500        {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/admin/stats/funnel [get]
+func (fa *FunnelAPI) Report(c *gin.Context) {
+	from, to, rErr := timeRange(c)
+	if rErr != nil {
+		AbortWithError(c, http.StatusBadRequest, rErr.Error())
+		return
+	}
+	report, err := fa.service.Report(c.Query("catalogId"), from, to)
+	if err != nil {
+		if err == model.ErrInvalidRange {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
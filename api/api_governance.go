@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+type GovernanceAPI struct {
+	service *service.GovernanceService
+}
+
+func NewGovernanceAPI(service *service.GovernanceService) *GovernanceAPI {
+	return &GovernanceAPI{
+		service: service,
+	}
+}
+
+// List governance events
+// @Security     ApiKeyAuth
+// @Summary      List governance events
+// @Description  Lists on-chain role, admin, pause and upgrade events of the Mailio NFT contract (latest first). Unexpected events have the alert field set
+// @Tags         Contract Admin
+// @Param        limit  query     int     false  "limit"
+// @Param        event  query     string  false  "event name (RoleGranted, RoleRevoked, AdminChanged, Paused, Unpaused, Upgraded)"
+// @Success      200    {array}   model.GovernanceEvent
+// @Failure      400    {object}  api.JSONError  "invalid limit"
+// @Failure      500    {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/contract/events [get]
+func (ga *GovernanceAPI) ListEvents(c *gin.Context) {
+	limitStr := c.Query("limit")
+	limit := 50
+	if limitStr != "" {
+		l, cErr := strconv.Atoi(limitStr)
+		if cErr != nil {
+			AbortWithError(c, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = l
+	}
+	events, err := ga.service.ListEvents(limit, c.Query("event"))
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
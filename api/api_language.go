@@ -0,0 +1,50 @@
+package api
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// preferredLanguages returns languages requested with the lang query parameter followed by
+// languages of the Accept-Language header (ordered by quality)
+func preferredLanguages(c *gin.Context) []string {
+	languages := []string{}
+	if lang := strings.TrimSpace(c.Query("lang")); lang != "" {
+		languages = append(languages, lang)
+	}
+
+	type weighted struct {
+		lang    string
+		quality float64
+	}
+	accepted := []weighted{}
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.TrimSpace(fields[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			accepted = append(accepted, weighted{lang: lang, quality: quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+	for _, a := range accepted {
+		languages = append(languages, a.lang)
+	}
+	return languages
+}
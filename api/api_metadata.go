@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+type NftMetadataAPI struct {
+	metadataService *service.NftMetadataService
+	claimService    *service.NftClaimService
+	catalogService  *service.NftCatalogService
+}
+
+func NewNftMetadataAPI(metadataService *service.NftMetadataService, claimService *service.NftClaimService, catalogService *service.NftCatalogService) *NftMetadataAPI {
+	return &NftMetadataAPI{
+		metadataService: metadataService,
+		claimService:    claimService,
+		catalogService:  catalogService,
+	}
+}
+
+// Get token metadata
+// @Summary      Get token metadata
+// @Description  ERC-721 metadata of the token (also served at /metadata/{tokenId}). Token id is either on-chain token id or claim id (walletAddress_catalogId). Redirects to IPFS if metadata was frozen
+// @Tags         Metadata
+// @Param        tokenId  path      string  true  "on-chain token id or claim id"
+// @Success      200      {object}  model.Erc721Json
+// @Success      302      {string}  string  "redirect to frozen metadata on IPFS"
+// @Failure      404      {object}  api.JSONError  "token not found"
+// @Failure      500      {object}  api.JSONError  "internal server error"
+// @Produce      json
+// @Router       /v1/metadata/{tokenId} [get]
+func (nma *NftMetadataAPI) GetMetadata(c *gin.Context) {
+	tokenId := c.Param("tokenId")
+	catalogId, owner, err := nma.metadataService.ResolveToken(tokenId)
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "token not found")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	// on-chain tokens may have been transferred, so their original claim is looked up by the token
+	var claim *model.Claim
+	if strings.Contains(tokenId, "_") {
+		claim, err = nma.claimService.GetClaimByOwner(catalogId, owner)
+	} else {
+		claim, err = nma.claimService.GetClaimByToken(catalogId, tokenId, owner)
+	}
+	if err != nil {
+		if err != model.ErrNotFound {
+			AbortWithError(c, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		if strings.Contains(tokenId, "_") {
+			AbortWithError(c, http.StatusNotFound, "token not found")
+			return
+		}
+		// token minted outside of the bridge
+		claim = &model.Claim{CatalogId: catalogId, WalletAddress: owner}
+	}
+	if claim.MetadataCid != "" {
+		c.Redirect(http.StatusFound, strings.TrimSuffix(lc.Conf.BlockchainConfig.InfuraIpfsGateway, "/")+"/ipfs/"+claim.MetadataCid)
+		return
+	}
+
+	catalog, err := nma.catalogService.GetCatalog(catalogId)
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "token not found")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, nma.metadataService.BuildMetadata(catalog, claim))
+}
+
+// Preview token metadata
+// @Security     ApiKeyAuth
+// @Summary      Preview token metadata
+// @Description  Renders ERC-721 metadata the next claimant of the catalog would receive (edition following the last assigned or minted edition)
+// @Tags         Metadata
+// @Param        id      path      string  true   "catalog id"
+// @Param        wallet  query     string  false  "wallet address of the claimant"
+// @Param        lang    query     string  false  "language of the claimant"
+// @Success      200     {object}  model.Erc721Json
+// @Failure      404     {object}  api.JSONError  "catalog not found"
+// @Failure      500     {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/catalog/{id}/metadata/preview [get]
+func (nma *NftMetadataAPI) PreviewMetadata(c *gin.Context) {
+	catalog, err := nma.catalogService.GetCatalog(c.Param("id"))
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "catalog not found")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	maxEditions, err := nma.claimService.MaxEditions()
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "Failed interacting with onchain contract")
+		return
+	}
+	edition, err := nma.claimService.PreviewEdition(catalog.ID)
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "Failed interacting with onchain contract")
+		return
+	}
+	claim := &model.Claim{
+		CatalogId:     catalog.ID,
+		WalletAddress: c.Query("wallet"),
+		Edition:       edition,
+		MaxEditions:   maxEditions,
+		Language:      catalog.MatchLanguage(preferredLanguages(c)),
+		Created:       time.Now().UnixMilli(),
+	}
+	if claim.WalletAddress == "" {
+		claim.WalletAddress = "0x0000000000000000000000000000000000000000"
+	}
+	c.JSON(http.StatusOK, nma.metadataService.BuildMetadata(catalog, claim))
+}
@@ -3,9 +3,12 @@ package api
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
 	"github.com/mailio/mailio-nft-server/service"
 )
 
@@ -22,16 +25,29 @@ func NewNftImagesAPI(service *service.NftImagesService) *NftImagesAPI {
 // Nft images list
 // @Tags         Nft Images
 // @Security     ApiKeyAuth
-// @Summary      List all pinned images
-// @Description  List all images pinned to infura
+// @Summary      List uploaded images
+// @Description  Paginated list of images uploaded through the bridge (newest first) with number of catalogs using them
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  model.NftImage
-// @Failure      400  {object}  api.JSONError  "failed to read file"
-// @Failure      500  {object}  api.JSONError  "upload failed"
+// @Param        page      query     int     false  "page (starting with 1)"
+// @Param        pageSize  query     int     false  "page size (max 100)"
+// @Param        search    query     string  false  "search by name, CID, MIME type or uploader"
+// @Success      200       {object}  model.NftImageList
+// @Failure      400       {object}  api.JSONError  "invalid page or page size"
+// @Failure      500       {object}  api.JSONError  "failed to list images"
 // @Router       /v1/nftimage/list [get]
 func (ni *NftImagesAPI) List(c *gin.Context) {
-	list, err := ni.service.List()
+	page, pErr := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if pErr != nil || page < 1 {
+		AbortWithError(c, http.StatusBadRequest, "invalid page")
+		return
+	}
+	pageSize, sErr := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if sErr != nil || pageSize < 1 || pageSize > 100 {
+		AbortWithError(c, http.StatusBadRequest, "invalid page size")
+		return
+	}
+	list, err := ni.service.List(page, pageSize, c.Query("search"))
 	if err != nil {
 		c.AbortWithError(http.StatusInternalServerError, errors.New("failed to list images"))
 		return
@@ -43,13 +59,13 @@ func (ni *NftImagesAPI) List(c *gin.Context) {
 // @Tags         Nft Images
 // @Security     ApiKeyAuth
 // @Summary      Upload to IPFS
-// @Description  Upload file to IPFS (on Infura) and pin it
+// @Description  Checks sniffed type, size and pixel limits, strips image metadata (EXIF, GPS) and pins the image with its optimized version and thumbnail to all pinning providers
 // @ID           file.upload
 // @Accept       multipart/form-data
 // @Produce      json
 // @Param        image  formData  file  true  "image file"
 // @Success      200    {object}  model.NftImageUploadResponse
-// @Failure      400    {object}  api.JSONError  "failed to read file"
+// @Failure      400    {object}  api.JSONError  "failed to read file or invalid image"
 // @Failure      500    {object}  api.JSONError  "upload failed"
 // @Router       /v1/nftimage/upload [post]
 func (ni *NftImagesAPI) Upload(c *gin.Context) {
@@ -59,25 +75,39 @@ func (ni *NftImagesAPI) Upload(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, errors.New("no image received"))
 		return
 	}
-	resp, err := ni.service.Upload(file)
+	resp, err := ni.service.Upload(file, jwtUserID(c))
 	if err != nil {
+		if errors.Is(err, model.ErrInvalidImage) {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		c.AbortWithError(http.StatusInternalServerError, errors.New("failed to upload image"))
 		return
 	}
 	c.JSON(http.StatusOK, resp)
 }
 
+// PinReferencesError is returned when removing pinned content referenced by catalogs or claims
+type PinReferencesError struct {
+	Code       int                   `json:"code"`
+	Message    string                `json:"message"`
+	References []*model.PinReference `json:"references"`
+}
+
 // Nft delete image
 // @Tags         Nft Images
 // @Security     ApiKeyAuth
 // @Summary      Delete pinned image
-// @Description  Delete pinned image from local service (infura)
-// @Param        hash  path  string  true  "image hash"
+// @Description  Delete pinned image from all pinning providers. Content referenced by catalogs or claims is removed only with force and reason (forced removals are logged for audit)
+// @Param        hash    path   string  true   "image hash"
+// @Param        force   query  bool    false  "remove even if referenced by catalogs or claims"
+// @Param        reason  query  string  false  "reason of the forced removal (required with force)"
 // @Accept       json
 // @Produce      json
 // @Success      200  {object}  model.NftPins
-// @Failure      400  {object}  api.JSONError  "failed to read file"
-// @Failure      500  {object}  api.JSONError  "upload failed"
+// @Failure      400  {object}  api.JSONError           "no hash received or missing reason"
+// @Failure      409  {object}  api.PinReferencesError  "pinned content is referenced"
+// @Failure      500  {object}  api.JSONError           "failed to remove pin"
 // @Router       /v1/nftimage/{hash} [delete]
 func (ni *NftImagesAPI) RemovePin(c *gin.Context) {
 	hash := c.Param("hash")
@@ -85,10 +115,55 @@ func (ni *NftImagesAPI) RemovePin(c *gin.Context) {
 		c.AbortWithError(http.StatusBadRequest, errors.New("no hash received"))
 		return
 	}
-	removedPins, err := ni.service.RemovePin(hash)
+	force := c.Query("force") == "true"
+	reason := strings.TrimSpace(c.Query("reason"))
+	if force && reason == "" {
+		AbortWithError(c, http.StatusBadRequest, "reason is required for forced removal")
+		return
+	}
+	removedPins, references, err := ni.service.RemovePin(hash, force, reason, jwtUserID(c))
 	if err != nil {
+		if err == model.ErrPinReferenced {
+			c.AbortWithStatusJSON(http.StatusConflict, &PinReferencesError{
+				Code:       http.StatusConflict,
+				Message:    err.Error(),
+				References: references,
+			})
+			return
+		}
 		c.AbortWithError(http.StatusInternalServerError, errors.New("failed to remove pin"))
 		return
 	}
 	c.JSON(http.StatusOK, removedPins)
 }
+
+// Nft pin removals audit log
+// @Tags         Nft Images
+// @Security     ApiKeyAuth
+// @Summary      List forced pin removals
+// @Description  Audit log of forced removals of pinned content with their outcome (newest first)
+// @Param        limit  query  int  false  "limit"
+// @Accept       json
+// @Produce      json
+// @Success      200  {array}   model.PinRemoval
+// @Failure      400  {object}  api.JSONError  "invalid limit"
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Router       /v1/nftimage/removals [get]
+func (ni *NftImagesAPI) ListRemovals(c *gin.Context) {
+	limitStr := c.Query("limit")
+	limit := 50
+	if limitStr != "" {
+		l, cErr := strconv.Atoi(limitStr)
+		if cErr != nil {
+			AbortWithError(c, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = l
+	}
+	removals, err := ni.service.ListRemovals(limit)
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, removals)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mailio/mailio-nft-server/model"
+)
+
+// pageSize returns the limit query parameter capped to model.MaxPageSize (model.DefaultPageSize if not given)
+func pageSize(c *gin.Context) (int, error) {
+	limitStr := c.Query("limit")
+	if limitStr == "" {
+		return model.DefaultPageSize, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		return 0, errors.New("invalid limit")
+	}
+	if limit > model.MaxPageSize {
+		limit = model.MaxPageSize
+	}
+	return limit, nil
+}
+
+// claimFilter returns the claim filter of catalogId, wallet, visitorId, since and until (ms) query parameters
+func claimFilter(c *gin.Context) (*model.ClaimFilter, error) {
+	filter := &model.ClaimFilter{
+		CatalogId:     c.Query("catalogId"),
+		WalletAddress: c.Query("wallet"),
+		VisitorId:     c.Query("visitorId"),
+	}
+	for name, value := range map[string]*int64{"since": &filter.Since, "until": &filter.Until} {
+		str := c.Query(name)
+		if str == "" {
+			continue
+		}
+		t, err := strconv.ParseInt(str, 10, 64)
+		if err != nil || t < 0 {
+			return nil, errors.New("invalid " + name)
+		}
+		*value = t
+	}
+	return filter, nil
+}
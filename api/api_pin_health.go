@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+type PinHealthAPI struct {
+	service *service.PinHealthService
+}
+
+func NewPinHealthAPI(service *service.PinHealthService) *PinHealthAPI {
+	return &PinHealthAPI{
+		service: service,
+	}
+}
+
+// Pin health report
+// @Security     ApiKeyAuth
+// @Summary      Pin health report
+// @Description  Returns the latest check of catalog images and claim metadata CIDs (pinned with all providers and retrievable through the IPFS gateway)
+// @Tags         Nft Images
+// @Success      200  {object}  model.PinHealthReport
+// @Failure      404  {object}  api.JSONError  "no pin health check run yet"
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/pin/health [get]
+func (pha *PinHealthAPI) GetReport(c *gin.Context) {
+	report, err := pha.service.GetReport()
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "no pin health check run yet")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Check pin health
+// @Security     ApiKeyAuth
+// @Summary      Check pin health
+// @Description  Checks all catalog images and claim metadata CIDs now, re-pins missing content and returns the report
+// @Tags         Nft Images
+// @Success      200  {object}  model.PinHealthReport
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/pin/health/check [post]
+func (pha *PinHealthAPI) Check(c *gin.Context) {
+	report, err := pha.service.Check()
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
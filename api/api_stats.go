@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+type StatsAPI struct {
+	service *service.NftStatsService
+}
+
+func NewStatsAPI(service *service.NftStatsService) *StatsAPI {
+	return &StatsAPI{
+		service: service,
+	}
+}
+
+// Claim statistics
+// @Security     ApiKeyAuth
+// @Summary      Claim statistics
+// @Description  Claims of a catalog (all catalogs by default) in hourly or daily UTC buckets with unique wallets and visitors, mint outcomes and mint latency. Range is capped to 744 buckets
+// @Tags         Stats
+// @Param        catalogId  query     string  false  "claims of the catalog"
+// @Param        from       query     int     false  "created at or after (unix ms, default 7 days or 24 hours before to)"
+// @Param        to         query     int     false  "created before (unix ms, default now)"
+// @Param        interval   query     string  false  "bucket interval: hour or day (default)"
+// @Success      200        {object}  model.ClaimStats
+// @Failure      400        {object}  api.JSONError  "invalid time range or interval"
+// @Failure      500        {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/admin/stats/claims [get]
+func (sa *StatsAPI) ClaimStats(c *gin.Context) {
+	from, to, rErr := timeRange(c)
+	if rErr != nil {
+		AbortWithError(c, http.StatusBadRequest, rErr.Error())
+		return
+	}
+	stats, err := sa.service.ClaimStats(c.Query("catalogId"), from, to, c.Query("interval"))
+	if err != nil {
+		if err == model.ErrInvalidRange {
+			AbortWithError(c, http.StatusBadRequest, "invalid time range or interval")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// Top catalogs
+// @Security     ApiKeyAuth
+// @Summary      Top catalogs
+// @Description  Catalogs with the most claims created within the time range of up to 744 days (limit is capped to 100)
+// @Tags         Stats
+// @Param        from   query     int  false  "created at or after (unix ms, default 7 days before to)"
+// @Param        to     query     int  false  "created before (unix ms, default now)"
+// @Param        limit  query     int  false  "number of catalogs"
+// @Success      200    {object}  model.CatalogRanking
+// @Failure      400    {object}  api.JSONError  "invalid time range or limit"
+// @Failure      500    {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/admin/stats/catalogs [get]
+func (sa *StatsAPI) TopCatalogs(c *gin.Context) {
+	limit, lErr := pageSize(c)
+	if lErr != nil {
+		AbortWithError(c, http.StatusBadRequest, lErr.Error())
+		return
+	}
+	from, to, rErr := timeRange(c)
+	if rErr != nil {
+		AbortWithError(c, http.StatusBadRequest, rErr.Error())
+		return
+	}
+	ranking, err := sa.service.TopCatalogs(from, to, limit)
+	if err != nil {
+		if err == model.ErrInvalidRange {
+			AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, ranking)
+}
+
+// Stats summary
+// @Security     ApiKeyAuth
+// @Summary      Stats summary
+// @Description  Broker balance, broker transactions waiting in the mempool, claims without a mint receipt and claim counts
+// @Tags         Stats
+// @Success      200  {object}  model.StatsSummary
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/admin/stats/summary [get]
+func (sa *StatsAPI) Summary(c *gin.Context) {
+	summary, err := sa.service.Summary()
+	if err != nil {
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// Catalog statistics
+// @Summary      Catalog statistics
+// @Description  Public claim counts of a published catalog
+// @Tags         Stats
+// @Param        id   path      string  true  "catalog id"
+// @Success      200  {object}  model.PublicCatalogStats
+// @Failure      404  {object}  api.JSONError  "catalog not found"
+// @Failure      500  {object}  api.JSONError  "internal server error"
+// @Accept       json
+// @Produce      json
+// @Router       /v1/catalog/{id}/stats [get]
+func (sa *StatsAPI) CatalogStats(c *gin.Context) {
+	stats, err := sa.service.PublicCatalogStats(c.Param("id"))
+	if err != nil {
+		if err == model.ErrNotFound {
+			AbortWithError(c, http.StatusNotFound, "catalog not found")
+			return
+		}
+		AbortWithError(c, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// timeRange returns from and to query parameters in unix ms (0 if not given)
+func timeRange(c *gin.Context) (int64, int64, error) {
+	bounds := [2]int64{}
+	for i, name := range []string{"from", "to"} {
+		str := c.Query(name)
+		if str == "" {
+			continue
+		}
+		t, err := strconv.ParseInt(str, 10, 64)
+		if err != nil || t < 0 {
+			return 0, 0, errors.New("invalid " + name)
+		}
+		bounds[i] = t
+	}
+	return bounds[0], bounds[1], nil
+}
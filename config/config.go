@@ -14,10 +14,25 @@ var Log mclog.Logger
 // Config - embedded global config definition
 type Config struct {
 	cfg.YamlConfig   `yaml:",inline"`
-	DatastorePath    string               `yaml:"datastore_path"`
-	EtherscanConfig  EtherscanSubConfig   `yaml:"etherscan"`
-	BlockchainConfig BlockchainSubConfig  `yaml:"blockchain"`
-	ReCaptchaV3      ReCaptchaV3SubConfig `yaml:"recaptcha"`
+	DatastorePath    string                 `yaml:"datastore_path"`
+	Storage          StorageSubConfig       `yaml:"storage"`
+	EtherscanConfig  EtherscanSubConfig     `yaml:"etherscan"`
+	BlockchainConfig BlockchainSubConfig    `yaml:"blockchain"`
+	ReCaptchaV3      ReCaptchaV3SubConfig   `yaml:"recaptcha"`
+	Notifications    NotificationsSubConfig `yaml:"notifications"`
+	Governance       GovernanceSubConfig    `yaml:"governance"`
+	Metadata         MetadataSubConfig      `yaml:"metadata"`
+	Pinning          PinningSubConfig       `yaml:"pinning"`
+	Images           ImagesSubConfig        `yaml:"images"`
+	TokenCounts      TokenCountsSubConfig   `yaml:"token_counts"`
+	Receipts         ReceiptsSubConfig      `yaml:"receipts"`
+	Search           SearchSubConfig        `yaml:"search"`
+	Funnel           FunnelSubConfig        `yaml:"funnel"`
+}
+
+type StorageSubConfig struct {
+	Backend string `yaml:"backend"` // storage of all server data: leveldb (default, datastore_path), memory, sqlite or postgres
+	Dsn     string `yaml:"dsn"`     // SQLite database file or Postgres connection string (sqlite and postgres backends)
 }
 
 type EtherscanSubConfig struct {
@@ -31,6 +46,7 @@ type BlockchainSubConfig struct {
 	MailioNFTProxyAddress     string                   `yaml:"mailio_nft_proxy"`
 	MailioNFTContractAddress  string                   `yaml:"mailio_nft_contract"`
 	MailioNFTBrokerPrivateKey string                   `yaml:"broker_private_key"`
+	MailioNFTAdminPrivateKey  string                   `yaml:"admin_private_key"` // signs contract administration transactions (pause, roles, ...)
 	Endpoint                  string                   `yaml:"endpoint"`
 	InfuraKey                 string                   `yaml:"infura_key"`
 	InfuraSecret              string                   `yaml:"infura_secret"`
@@ -50,6 +66,67 @@ type ReCaptchaV3SubConfig struct {
 	Host   string `yaml:"host"`
 }
 
+type NotificationsSubConfig struct {
+	WebhookUrl string `yaml:"webhook_url"` // Slack compatible incoming webhook for admin notifications
+}
+
+type GovernanceSubConfig struct {
+	StartBlock     uint64   `yaml:"start_block"`     // first block to watch for governance events (0 = current block)
+	PollInterval   int      `yaml:"poll_interval"`   // seconds between polls for new events (default 60)
+	KnownAddresses []string `yaml:"known_addresses"` // addresses expected to hold contract roles (broker and admin are always known)
+}
+
+type MetadataSubConfig struct {
+	BaseUrl        string `yaml:"base_url"`        // public URL of the metadata endpoint (e.g. https://nft.mail.io/metadata/)
+	FreezeInterval int    `yaml:"freeze_interval"` // seconds between freezing served metadata to IPFS (0 = disabled)
+	FreezeAfter    int    `yaml:"freeze_after"`    // hours after the claim served metadata is frozen to IPFS
+	CidVersion     int    `yaml:"cid_version"`     // CID version of uploaded metadata: 0 (default, Qm...) or 1 (raw leaves, base32)
+}
+
+type PinningSubConfig struct {
+	Providers      []PinningProviderSubConfig `yaml:"providers"`       // content is uploaded to the first provider supporting uploads and replicated to others (default: infura)
+	MinReplicas    int                        `yaml:"min_replicas"`    // min number of providers content must be pinned to (default 1)
+	HealthInterval int                        `yaml:"health_interval"` // seconds between pin health checks (0 = disabled)
+	GatewayTimeout int                        `yaml:"gateway_timeout"` // seconds to retrieve content through the IPFS gateway (default 30)
+}
+
+type PinningProviderSubConfig struct {
+	Name     string `yaml:"name"`     // unique name recorded as pin location (default: type)
+	Type     string `yaml:"type"`     // infura, kubo, pinata or pinning_service
+	Endpoint string `yaml:"endpoint"` // API endpoint
+	Key      string `yaml:"key"`      // infura project key
+	Secret   string `yaml:"secret"`   // infura project secret
+	Token    string `yaml:"token"`    // access token (pinata JWT or pinning services API token)
+}
+
+type ImagesSubConfig struct {
+	AllowedTypes  []string `yaml:"allowed_types"`  // sniffed MIME types accepted for upload (default: image/jpeg, image/png, image/gif)
+	MaxSize       int64    `yaml:"max_size"`       // max upload size in bytes (default 10MB)
+	MaxPixels     int      `yaml:"max_pixels"`     // max width * height of images, of all frames of GIF images (default 25 megapixels)
+	ThumbnailSize int      `yaml:"thumbnail_size"` // longer side of the thumbnail in pixels (default 256)
+	OptimizedSize int      `yaml:"optimized_size"` // longer side of the optimized image in pixels (default 1200)
+	JpegQuality   int      `yaml:"jpeg_quality"`   // quality of re-encoded JPEG images (default 85)
+}
+
+type TokenCountsSubConfig struct {
+	PollInterval    int `yaml:"poll_interval"`    // seconds between polls for mint events (default 15)
+	RefreshInterval int `yaml:"refresh_interval"` // seconds between full refreshes of all category counts (default 3600)
+	BatchSize       int `yaml:"batch_size"`       // max eth_calls within a single batch request (default 100)
+}
+
+type ReceiptsSubConfig struct {
+	PollInterval int `yaml:"poll_interval"` // seconds between polls for receipts of pending mint transactions (default 30)
+	Window       int `yaml:"window"`        // hours after the claim its receipt is polled for (default 24)
+}
+
+type SearchSubConfig struct {
+	ReloadInterval int `yaml:"reload_interval"` // seconds after the catalog search index is reloaded from storage (default 60)
+}
+
+type FunnelSubConfig struct {
+	Retention int `yaml:"retention"` // days funnel events are kept for reports (default 90)
+}
+
 func init() {
 	l, err := mclog.NewEntry2ZapLogger("mailio-nft-server")
 	if err != nil {
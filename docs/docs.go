@@ -1,4 +1,4 @@
-// Package docs GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
+// Package docs GENERATED BY SWAG; DO NOT EDIT
 // This file was generated by swaggo/swag
 package docs
 
@@ -21,37 +21,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/bridge/balance": {
+        "/v1/admin/catalog": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Gets the current balance of NFT bridge",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Nft Bridge"
-                ],
-                "summary": "Nft Contract",
-                "responses": {
-                    "500": {
-                        "description": "internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/api.JSONError"
-                        }
-                    }
-                }
-            }
-        },
-        "/v1/catalog": {
-            "get": {
-                "description": "List Catalogs",
+                "description": "Search catalogs in all lifecycle states (same filters as the public list)",
                 "consumes": [
                     "application/json"
                 ],
@@ -61,23 +38,68 @@ const docTemplate = `{
                 "tags": [
                     "Catalog"
                 ],
-                "summary": "List Catalog",
+                "summary": "List catalogs (admin)",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "status (draft, scheduled, published, archived)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "catalog type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "keyword",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "full text search over name and description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "relevance, created (default), modified or popularity",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default)",
+                        "name": "order",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "limit",
+                        "description": "page size (max 100)",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Catalog"
-                            }
+                            "$ref": "#/definitions/model.CatalogSearchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid search",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
                         }
                     },
                     "500": {
@@ -87,14 +109,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/v1/admin/catalog/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "When ID is given with the POST object then it's an update, otherwise insert",
+                "description": "Get catalog in any lifecycle state by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -104,16 +128,14 @@ const docTemplate = `{
                 "tags": [
                     "Catalog"
                 ],
-                "summary": "Upsert Catalog",
+                "summary": "Get catalog (admin)",
                 "parameters": [
                     {
-                        "description": "catalog",
-                        "name": "catalog",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Catalog"
-                        }
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -123,14 +145,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Catalog"
                         }
                     },
-                    "400": {
-                        "description": "invalid input",
-                        "schema": {
-                            "$ref": "#/definitions/api.JSONError"
-                        }
-                    },
-                    "401": {
-                        "description": "access denied",
+                    "404": {
+                        "description": "catalog not found",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
@@ -144,9 +160,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/catalog/{id}": {
-            "get": {
-                "description": "Get Catalog by id",
+        "/v1/admin/catalog/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Allowed transitions: draft -\u003e scheduled, published, archived; scheduled -\u003e draft, published, archived; published -\u003e archived; archived -\u003e draft, published. Scheduled requires publishAt in the future",
                 "consumes": [
                     "application/json"
                 ],
@@ -156,7 +177,7 @@ const docTemplate = `{
                 "tags": [
                     "Catalog"
                 ],
-                "summary": "Get Catalog",
+                "summary": "Change catalog status",
                 "parameters": [
                     {
                         "type": "string",
@@ -164,6 +185,15 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogStatusChange"
+                        }
                     }
                 ],
                 "responses": {
@@ -173,6 +203,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Catalog"
                         }
                     },
+                    "400": {
+                        "description": "invalid status transition",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
                     "404": {
                         "description": "catalog not found",
                         "schema": {
@@ -188,29 +224,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/claim": {
+        "/v1/admin/catalogs/export": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List Latest claims",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Exports catalogs in the import format (JSON, YAML or CSV with JSON encoded nested fields)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Claiming"
+                    "Catalog"
                 ],
-                "summary": "List Claims",
+                "summary": "Export catalogs",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "json (default), yaml or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "status (draft, scheduled, published, archived)",
+                        "name": "status",
                         "in": "query"
                     }
                 ],
@@ -220,10 +259,16 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Claim"
+                                "$ref": "#/definitions/model.Catalog"
                             }
                         }
                     },
+                    "400": {
+                        "description": "invalid format or status",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
                     "500": {
                         "description": "internal server error",
                         "schema": {
@@ -231,48 +276,62 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/v1/admin/catalogs/import": {
             "post": {
-                "description": "Mints the new NFT based on the category selected. All NFTs are on Polygon",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upserts catalogs of a JSON, YAML or CSV file (matched by id or externalRef) validated with the same rules as catalog upsert. Runs as a dry-run unless dryRun=false. Nothing is stored if any row is invalid. Images referenced by imageFile are uploaded with the file before any catalog is stored. Catalogs are stored in file order until a write fails (stored rows are flagged), unchanged catalogs are skipped so the import can be re-run",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Claiming"
+                    "Catalog"
                 ],
-                "summary": "Mint new NFT",
+                "summary": "Import catalogs",
                 "parameters": [
                     {
-                        "description": "eip-712 signed claim",
-                        "name": "claim",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Claim"
-                        }
+                        "type": "file",
+                        "description": "catalogs (.json, .yaml, .yml or .csv)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "images referenced by imageFile of the catalogs (repeated)",
+                        "name": "images",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json, yaml or csv (by file extension by default)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only validate (default true)",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Claim"
-                            }
+                            "$ref": "#/definitions/model.CatalogImportResult"
                         }
                     },
                     "400": {
-                        "description": "invalid input",
-                        "schema": {
-                            "$ref": "#/definitions/api.JSONError"
-                        }
-                    },
-                    "403": {
-                        "description": "captacha failed",
+                        "description": "invalid import file",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
@@ -286,9 +345,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/claim/{address}/payload/{catalogId}": {
+        "/v1/admin/stats/catalogs": {
             "get": {
-                "description": "gets the payload to sign by the user with their wallet",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Catalogs with the most claims created within the time range of up to 744 days (limit is capped to 100)",
                 "consumes": [
                     "application/json"
                 ],
@@ -296,26 +360,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Claiming"
+                    "Stats"
                 ],
-                "summary": "Nft Claim",
+                "summary": "Top catalogs",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "categoryId",
-                        "name": "catalogId",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "created at or after (unix ms, default 7 days before to)",
+                        "name": "from",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "address",
-                        "name": "address",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "created before (unix ms, default now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of catalogs",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogRanking"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid time range or limit",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
                     "500": {
                         "description": "internal server error",
                         "schema": {
@@ -325,9 +405,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/login": {
-            "post": {
-                "description": "User login and returns JWT token\nUser considered as admin, since only adding catalogs is allowed",
+        "/v1/admin/stats/claims": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Claims of a catalog (all catalogs by default) in hourly or daily UTC buckets with unique wallets and visitors, mint outcomes and mint latency. Range is capped to 744 buckets",
                 "consumes": [
                     "application/json"
                 ],
@@ -335,29 +420,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Stats"
                 ],
-                "summary": "Admin login",
+                "summary": "Claim statistics",
                 "parameters": [
                     {
-                        "description": "Email and Password required",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.EmailPasswordInput"
-                        }
+                        "type": "string",
+                        "description": "claims of the catalog",
+                        "name": "catalogId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "created at or after (unix ms, default 7 days or 24 hours before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "created before (unix ms, default now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "bucket interval: hour or day (default)",
+                        "name": "interval",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.JwtTokenOutput"
+                            "$ref": "#/definitions/model.ClaimStats"
                         }
                     },
-                    "401": {
-                        "description": "login failed",
+                    "400": {
+                        "description": "invalid time range or interval",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
@@ -371,14 +471,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/nftimage/list": {
+        "/v1/admin/stats/funnel": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List all images pinned to infura",
+                "description": "Conversion rates of claim steps (payload, submitted, captcha, signature, keywords, mint, confirmed) with failure reason codes, in total and per catalog",
                 "consumes": [
                     "application/json"
                 ],
@@ -386,24 +486,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Nft Images"
+                    "Stats"
                 ],
-                "summary": "List all pinned images",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Claim funnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "events of the catalog",
+                        "name": "catalogId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "recorded at or after (unix ms, default 7 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "recorded before (unix ms, default now)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.NftImage"
+                            "$ref": "#/definitions/model.FunnelReport"
                         }
                     },
                     "400": {
-                        "description": "failed to read file",
+                        "description": "invalid time range",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
                     },
                     "500": {
-                        "description": "upload failed",
+                        "description": "internal server error",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
@@ -411,49 +531,144 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/nftimage/upload": {
-            "post": {
+        "/v1/admin/stats/summary": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Upload file to IPFS (on Infura) and pin it",
+                "description": "Broker balance, broker transactions waiting in the mempool, claims without a mint receipt and claim counts",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Nft Images"
+                    "Stats"
                 ],
-                "summary": "Upload to IPFS",
-                "operationId": "file.upload",
+                "summary": "Stats summary",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.StatsSummary"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/bridge/balance": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets the current balance of NFT bridge",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nft Bridge"
+                ],
+                "summary": "Nft Contract",
+                "responses": {
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/budget": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns global spending budget settings of the broker wallet and todays spending (in native currency)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budget"
+                ],
+                "summary": "Get spending budget",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.BudgetStatus"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets daily limit, per transaction fee ceiling and default catalog limit in native currency (e.g. \"0.5\"). Empty value means no limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budget"
+                ],
+                "summary": "Set spending budget",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "image file",
-                        "name": "image",
-                        "in": "formData",
-                        "required": true
+                        "description": "budget settings",
+                        "name": "budget",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BudgetSettings"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.NftImageUploadResponse"
+                            "$ref": "#/definitions/model.BudgetSettings"
                         }
                     },
                     "400": {
-                        "description": "failed to read file",
+                        "description": "invalid input",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
                     },
                     "500": {
-                        "description": "upload failed",
+                        "description": "internal server error",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
@@ -461,14 +676,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/nftimage/{hash}": {
-            "delete": {
+        "/v1/budget/catalog/{catalogId}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete pinned image from local service (infura)",
+                "description": "Returns effective spending limit of the catalog and total spent on it (in native currency)",
                 "consumes": [
                     "application/json"
                 ],
@@ -476,14 +691,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Nft Images"
+                    "Budget"
                 ],
-                "summary": "Delete pinned image",
+                "summary": "Get catalog spending budget",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "image hash",
-                        "name": "hash",
+                        "description": "catalog id",
+                        "name": "catalogId",
                         "in": "path",
                         "required": true
                     }
@@ -492,17 +707,67 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.NftPins"
+                            "$ref": "#/definitions/model.CatalogBudgetStatus"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Overrides the default catalog limit for a single catalog (in native currency)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budget"
+                ],
+                "summary": "Set catalog spending budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "catalog id",
+                        "name": "catalogId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "catalog budget",
+                        "name": "budget",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogBudget"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogBudget"
                         }
                     },
                     "400": {
-                        "description": "failed to read file",
+                        "description": "invalid input",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
                     },
                     "500": {
-                        "description": "upload failed",
+                        "description": "internal server error",
                         "schema": {
                             "$ref": "#/definitions/api.JSONError"
                         }
@@ -510,9 +775,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/user/claims/{walletaddress}": {
+        "/v1/catalog": {
             "get": {
-                "description": "Reads the claimed transaction log (all mailio claimed NFTs)",
+                "description": "Search published Catalogs by type, keyword and full text (name and description in all languages) with number of matching catalogs per type. Catalogs are returned in the language requested by lang or Accept-Language",
                 "consumes": [
                     "application/json"
                 ],
@@ -520,32 +785,70 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Claiming"
+                    "Catalog"
                 ],
-                "summary": "Get claimed tx log",
+                "summary": "List Catalog",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "users wallet address",
-                        "name": "walletaddress",
-                        "in": "path",
-                        "required": true
+                        "description": "catalog type",
+                        "name": "type",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "limit",
+                        "description": "keyword",
+                        "name": "keyword",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "full text search over name and description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "relevance, created (default), modified or popularity",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc (default)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size (max 100)",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "language (e.g. de)",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.ClaimPreview"
-                            }
+                            "$ref": "#/definitions/model.CatalogSearchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid search",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
                         }
                     },
                     "500": {
@@ -555,270 +858,3288 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "api.JSONError": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer"
-                },
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.Catalog": {
-            "type": "object",
-            "required": [
-                "contentLink",
-                "description",
-                "keywords",
-                "name",
-                "type"
-            ],
-            "properties": {
-                "contentLink": {
-                    "type": "string",
-                    "maxLength": 2000,
-                    "minLength": 3
-                },
-                "created": {
-                    "type": "integer"
-                },
-                "description": {
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "When ID is given with the POST object then it's an update, otherwise insert. New catalogs are drafts unless status is given (status transitions are enforced). Updates without publishAt keep the stored publishAt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Catalog"
+                ],
+                "summary": "Upsert Catalog",
+                "parameters": [
+                    {
+                        "description": "catalog",
+                        "name": "catalog",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Catalog"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Catalog"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "401": {
+                        "description": "access denied",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/catalog/{id}": {
+            "get": {
+                "description": "Get published Catalog by id in the language requested by lang or Accept-Language (falls back to the default language)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Catalog"
+                ],
+                "summary": "Get Catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "language (e.g. de)",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Catalog"
+                        }
+                    },
+                    "404": {
+                        "description": "catalog not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/catalog/{id}/diff": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changed fields between two revisions of the catalog",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Catalog"
+                ],
+                "summary": "Diff catalog revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "catalog id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision (latest by default)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogRevisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid revision",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/catalog/{id}/metadata/preview": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Renders ERC-721 metadata the next claimant of the catalog would receive (edition following the last assigned or minted edition)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Metadata"
+                ],
+                "summary": "Preview token metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "catalog id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "wallet address of the claimant",
+                        "name": "wallet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "language of the claimant",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Erc721Json"
+                        }
+                    },
+                    "404": {
+                        "description": "catalog not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/catalog/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revisions of the catalog with author and changed fields (newest first)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Catalog"
+                ],
+                "summary": "List catalog revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "catalog id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.CatalogRevision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/catalog/{id}/revisions/{revision}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revision of the catalog with the catalog snapshot",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Catalog"
+                ],
+                "summary": "Get catalog revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "catalog id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CatalogRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid revision",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/catalog/{id}/revisions/{revision}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stores the catalog as it was at the given revision (as a new revision). The restored catalog is validated with the current rules",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Catalog"
+                ],
+                "summary": "Restore catalog revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "catalog id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Catalog"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid revision or restored catalog",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "404": {
+                        "description": "revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/catalog/{id}/stats": {
+            "get": {
+                "description": "Public claim counts of a published catalog",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Catalog statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "catalog id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PublicCatalogStats"
+                        }
+                    },
+                    "404": {
+                        "description": "catalog not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/claim": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List latest claims newest first, optionally of a catalog, wallet or visitor created within a time range (page size is capped to 100)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claiming"
+                ],
+                "summary": "List Claims",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "claims of the catalog",
+                        "name": "catalogId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "claims of the wallet",
+                        "name": "wallet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "claims of the visitor",
+                        "name": "visitorId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "created at or after (unix ms)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "created before (unix ms)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count all selected claims",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ClaimPage"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid filter, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Mints the new NFT based on the category selected. All NFTs are on Polygon. Metadata is generated in the claim language (or negotiated with Accept-Language)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claiming"
+                ],
+                "summary": "Mint new NFT",
+                "parameters": [
+                    {
+                        "description": "eip-712 signed claim",
+                        "name": "claim",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Claim"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Claim"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "403": {
+                        "description": "captacha failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "503": {
+                        "description": "temporarily unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/claim/{address}/payload/{catalogId}": {
+            "get": {
+                "description": "gets the payload to sign by the user with their wallet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claiming"
+                ],
+                "summary": "Nft Claim",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "categoryId",
+                        "name": "catalogId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists on-chain role, admin, pause and upgrade events of the Mailio NFT contract (latest first). Unexpected events have the alert field set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "List governance events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "event name (RoleGranted, RoleRevoked, AdminChanged, Paused, Unpaused, Upgraded)",
+                        "name": "event",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GovernanceEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/implementation": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the stored implementation address of the Mailio NFT proxy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Contract implementation",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractImplementation"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/pause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Pauses the Mailio NFT contract (signed by the admin wallet which requires PAUSER_ROLE)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Pause contract",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractAdminTx"
+                        }
+                    },
+                    "403": {
+                        "description": "admin wallet is missing the role",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/roles/grant": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Grants MINTER_ROLE or PAUSER_ROLE to the account (signed by the admin wallet)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Grant contract role",
+                "parameters": [
+                    {
+                        "description": "role and account",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RoleChangeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractAdminTx"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "403": {
+                        "description": "admin wallet is missing the role",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/roles/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes MINTER_ROLE or PAUSER_ROLE from the account (signed by the admin wallet)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Revoke contract role",
+                "parameters": [
+                    {
+                        "description": "role and account",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RoleChangeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractAdminTx"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "403": {
+                        "description": "admin wallet is missing the role",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/roles/{role}/{account}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks if account has the contract role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Contract role membership",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MINTER_ROLE or PAUSER_ROLE",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "account address",
+                        "name": "account",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RoleMembership"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns paused state of the Mailio NFT contract and roles of the broker and admin wallets",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Contract status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractStatus"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists transactions sent by the admin wallet (latest first)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "List contract admin transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ContractAdminTx"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/unpause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Unpauses the Mailio NFT contract (signed by the admin wallet which requires PAUSER_ROLE)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Unpause contract",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractAdminTx"
+                        }
+                    },
+                    "403": {
+                        "description": "admin wallet is missing the role",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/upgrade": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Runs all upgrade checks, upgrades the proxy to the new implementation and waits for the Upgraded event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Upgrade contract",
+                "parameters": [
+                    {
+                        "description": "new implementation",
+                        "name": "upgrade",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractUpgradeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractUpgrade"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input or upgrade checks failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/upgrade/check": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks proxiableUUID of the new implementation, compatibility of its ABI (required) with the embedded MailioNFT ABI and simulates the upgrade",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "Check contract upgrade",
+                "parameters": [
+                    {
+                        "description": "new implementation",
+                        "name": "upgrade",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractUpgradeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ContractUpgradeCheck"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/contract/upgrades": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists recorded upgrades of the Mailio NFT proxy (latest first)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract Admin"
+                ],
+                "summary": "List contract upgrades",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ContractUpgrade"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/login": {
+            "post": {
+                "description": "User login and returns JWT token\nUser considered as admin, since only adding catalogs is allowed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Admin login",
+                "parameters": [
+                    {
+                        "description": "Email and Password required",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.EmailPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.JwtTokenOutput"
+                        }
+                    },
+                    "401": {
+                        "description": "login failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/metadata/{tokenId}": {
+            "get": {
+                "description": "ERC-721 metadata of the token (also served at /metadata/{tokenId}). Token id is either on-chain token id or claim id (walletAddress_catalogId). Redirects to IPFS if metadata was frozen",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Metadata"
+                ],
+                "summary": "Get token metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "on-chain token id or claim id",
+                        "name": "tokenId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Erc721Json"
+                        }
+                    },
+                    "302": {
+                        "description": "redirect to frozen metadata on IPFS",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "token not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/nftimage/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Paginated list of images uploaded through the bridge (newest first) with number of catalogs using them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nft Images"
+                ],
+                "summary": "List uploaded images",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page (starting with 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size (max 100)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search by name, CID, MIME type or uploader",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.NftImageList"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid page or page size",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to list images",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/nftimage/removals": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Audit log of forced removals of pinned content with their outcome (newest first)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nft Images"
+                ],
+                "summary": "List forced pin removals",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PinRemoval"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/nftimage/upload": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks sniffed type, size and pixel limits, strips image metadata (EXIF, GPS) and pins the image with its optimized version and thumbnail to all pinning providers",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nft Images"
+                ],
+                "summary": "Upload to IPFS",
+                "operationId": "file.upload",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "image file",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.NftImageUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "failed to read file or invalid image",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "upload failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/nftimage/{hash}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete pinned image from all pinning providers. Content referenced by catalogs or claims is removed only with force and reason (forced removals are logged for audit)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nft Images"
+                ],
+                "summary": "Delete pinned image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "image hash",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove even if referenced by catalogs or claims",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "reason of the forced removal (required with force)",
+                        "name": "reason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.NftPins"
+                        }
+                    },
+                    "400": {
+                        "description": "no hash received or missing reason",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "409": {
+                        "description": "pinned content is referenced",
+                        "schema": {
+                            "$ref": "#/definitions/api.PinReferencesError"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to remove pin",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/pin/health": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the latest check of catalog images and claim metadata CIDs (pinned with all providers and retrievable through the IPFS gateway)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nft Images"
+                ],
+                "summary": "Pin health report",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PinHealthReport"
+                        }
+                    },
+                    "404": {
+                        "description": "no pin health check run yet",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/pin/health/check": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks all catalog images and claim metadata CIDs now, re-pins missing content and returns the report",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Nft Images"
+                ],
+                "summary": "Check pin health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PinHealthReport"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/user/claims/{walletaddress}": {
+            "get": {
+                "description": "Reads the claimed transaction log (all mailio claimed NFTs, page size is capped to 100)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Claiming"
+                ],
+                "summary": "Get claimed tx log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "users wallet address",
+                        "name": "walletaddress",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "count all claims of the wallet",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ClaimPreviewPage"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/api.JSONError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.JSONError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.PinReferencesError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "references": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PinReference"
+                    }
+                }
+            }
+        },
+        "model.ArticleDetails": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "publishedAt": {
+                    "description": "ms",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "readingMinutes": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "model.BudgetSettings": {
+            "type": "object",
+            "properties": {
+                "dailyLimit": {
+                    "description": "max spent per day (UTC) across all catalogs",
+                    "type": "string"
+                },
+                "defaultCatalogLimit": {
+                    "description": "max spent per catalog (when catalog has no budget of its own)",
+                    "type": "string"
+                },
+                "maxTxFee": {
+                    "description": "fee ceiling of a single mint transaction",
+                    "type": "string"
+                },
+                "modified": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.BudgetStatus": {
+            "type": "object",
+            "properties": {
+                "settings": {
+                    "$ref": "#/definitions/model.BudgetSettings"
+                },
+                "spentToday": {
+                    "description": "in native currency",
+                    "type": "string"
+                }
+            }
+        },
+        "model.Catalog": {
+            "type": "object",
+            "required": [
+                "contentLink",
+                "description",
+                "keywords",
+                "name",
+                "translations",
+                "type"
+            ],
+            "properties": {
+                "article": {
+                    "description": "only article catalogs",
+                    "$ref": "#/definitions/model.ArticleDetails"
+                },
+                "contentLink": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "minLength": 3
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "defaultLanguage": {
+                    "description": "language of the name, description, content link and keywords (default en)",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "minLength": 3
+                },
+                "externalRef": {
+                    "description": "reference of the catalog in an external system (matched by imports)",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "string"
+                },
+                "imageLink": {
+                    "description": "CID/hash of the image",
+                    "type": "string"
+                },
+                "keywords": {
+                    "description": "comma separated list of keywords",
+                    "type": "string",
+                    "maxLength": 1000,
+                    "minLength": 3
+                },
+                "metadataMode": {
+                    "description": "where token URI points to: ipfs (default) or https (metadata endpoint)",
+                    "type": "string",
+                    "enum": [
+                        "ipfs",
+                        "https"
+                    ]
+                },
+                "metadataTemplate": {
+                    "description": "metadata of the claimed tokens (default background and informed attribute if not set)",
+                    "$ref": "#/definitions/model.MetadataTemplate"
+                },
+                "modified": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "nftTokensUsed": {
+                    "description": "currently minted tokens for the catalog",
+                    "type": "integer"
+                },
+                "podcast": {
+                    "description": "only podcast catalogs",
+                    "$ref": "#/definitions/model.PodcastDetails"
+                },
+                "podcastEpisode": {
+                    "description": "only podcast-episode catalogs",
+                    "$ref": "#/definitions/model.PodcastEpisodeDetails"
+                },
+                "publishAt": {
+                    "description": "time (ms) a scheduled catalog is published",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "draft (default), scheduled, published or archived",
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "scheduled",
+                        "published",
+                        "archived"
+                    ]
+                },
+                "translations": {
+                    "description": "localized content by language tag (e.g. de, pt-BR)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.CatalogTranslation"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "video",
+                        "article",
+                        "podcast",
+                        "podcast-episode",
+                        "virtual-event"
+                    ]
+                },
+                "video": {
+                    "description": "only video catalogs",
+                    "$ref": "#/definitions/model.VideoDetails"
+                },
+                "videoLink": {
+                    "description": "YouTube or similar link",
+                    "type": "string"
+                },
+                "virtualEvent": {
+                    "description": "only virtual-event catalogs",
+                    "$ref": "#/definitions/model.VirtualEventDetails"
+                }
+            }
+        },
+        "model.CatalogBudget": {
+            "type": "object",
+            "required": [
+                "limit"
+            ],
+            "properties": {
+                "catalogId": {
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "max spent for the catalog in native currency",
+                    "type": "string"
+                },
+                "modified": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CatalogBudgetStatus": {
+            "type": "object",
+            "properties": {
+                "catalogId": {
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "effective limit in native currency",
+                    "type": "string"
+                },
+                "spent": {
+                    "description": "in native currency",
+                    "type": "string"
+                }
+            }
+        },
+        "model.CatalogFieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {},
+                "old": {}
+            }
+        },
+        "model.CatalogImportResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "imported": {
+                    "description": "true if all catalogs were stored",
+                    "type": "boolean"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CatalogImportRow"
+                    }
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CatalogImportRow": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "create, update or unchanged (empty if the row is invalid)",
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "externalRef": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "row": {
+                    "description": "position in the file (starting with 1, CSV header excluded)",
+                    "type": "integer"
+                },
+                "stored": {
+                    "description": "true if the catalog is stored (or unchanged) after the import",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.CatalogRanking": {
+            "type": "object",
+            "properties": {
+                "catalogs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CatalogRankingEntry"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CatalogRankingEntry": {
+            "type": "object",
+            "properties": {
+                "catalogId": {
+                    "type": "string"
+                },
+                "claims": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "uniqueWallets": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CatalogRevision": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "catalog": {
+                    "$ref": "#/definitions/model.Catalog"
+                },
+                "catalogId": {
+                    "type": "string"
+                },
+                "changes": {
+                    "description": "changes from the previous revision",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CatalogFieldChange"
+                    }
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "restoredFrom": {
+                    "description": "revision restored by this revision",
+                    "type": "integer"
+                },
+                "revision": {
+                    "description": "sequential number of the revision (starting with 1)",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CatalogRevisionDiff": {
+            "type": "object",
+            "properties": {
+                "catalogId": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CatalogFieldChange"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CatalogSearchResult": {
+            "type": "object",
+            "properties": {
+                "catalogs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Catalog"
+                    }
+                },
+                "facets": {
+                    "description": "number of matching catalogs per type (ignoring type filter)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "next": {
+                    "description": "cursor of the following page (empty on the last page)",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "number of matching catalogs",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CatalogStatusChange": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "publishAt": {
+                    "description": "required when scheduled (ms)",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "scheduled",
+                        "published",
+                        "archived"
+                    ]
+                }
+            }
+        },
+        "model.CatalogTranslation": {
+            "type": "object",
+            "properties": {
+                "contentLink": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "minLength": 3
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "minLength": 3
+                },
+                "keywords": {
+                    "description": "comma separated list of quiz keywords",
                     "type": "string",
                     "maxLength": 1000,
                     "minLength": 3
                 },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                }
+            }
+        },
+        "model.Claim": {
+            "type": "object",
+            "required": [
+                "catalogId",
+                "recaptchaToken",
+                "signature",
+                "visitorId",
+                "walletAddress"
+            ],
+            "properties": {
+                "budgetDay": {
+                    "description": "daily budget the fee is reserved in (day_YYYYMMDD)",
+                    "type": "string"
+                },
+                "catalogId": {
+                    "description": "categoryId to be claimed",
+                    "type": "string"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "edition": {
+                    "description": "edition number of the token within the catalog (#12 of 100)",
+                    "type": "integer"
+                },
+                "fee": {
+                    "description": "fee paid in wei (gas used * gas price, recorded when mined)",
+                    "type": "string"
+                },
+                "gasPrice": {
+                    "description": "gas price of the transaction",
+                    "type": "integer"
+                },
+                "keywords": {
+                    "description": "keywords (not need to be stored in db)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ClaimKeyword"
+                    }
+                },
+                "language": {
+                    "description": "language of the claimant (metadata and quiz keywords), negotiated if empty",
+                    "type": "string"
+                },
+                "mailioAddress": {
+                    "description": "optional mailio address",
+                    "type": "string"
+                },
+                "maxEditions": {
+                    "description": "max number of tokens within the catalog",
+                    "type": "integer"
+                },
+                "metadataCid": {
+                    "description": "CID of the metadata frozen to IPFS (https metadata mode)",
+                    "type": "string"
+                },
+                "mined": {
+                    "description": "time of the block including the mint transaction (ms)",
+                    "type": "integer"
+                },
+                "mintStatus": {
+                    "description": "outcome of the mint transaction: pending, success or failed",
+                    "type": "string"
+                },
+                "recaptchaToken": {
+                    "description": "recaptcha v3 token // required",
+                    "type": "string"
+                },
+                "reservedFee": {
+                    "description": "fee in wei reserved in budgets until the transaction is mined",
+                    "type": "string"
+                },
+                "signature": {
+                    "description": "signature of categoryId + nonce",
+                    "type": "string"
+                },
+                "tokenUri": {
+                    "description": "token uri",
+                    "type": "string"
+                },
+                "txHash": {
+                    "description": "transaction hash of the transaction",
+                    "type": "string"
+                },
+                "visitorId": {
+                    "description": "visitor id (part of datastore keys)",
+                    "type": "string"
+                },
+                "walletAddress": {
+                    "description": "publickey of the user retrieved from wallet",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ClaimKeyword": {
+            "type": "object",
+            "properties": {
+                "word": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ClaimPage": {
+            "type": "object",
+            "properties": {
+                "claims": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Claim"
+                    }
+                },
+                "next": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "number of all claims (only if requested)",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ClaimPreview": {
+            "type": "object",
+            "required": [
+                "catalogId",
+                "recaptchaToken",
+                "signature",
+                "visitorId",
+                "walletAddress"
+            ],
+            "properties": {
+                "budgetDay": {
+                    "description": "daily budget the fee is reserved in (day_YYYYMMDD)",
+                    "type": "string"
+                },
+                "catalogId": {
+                    "description": "categoryId to be claimed",
+                    "type": "string"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "edition": {
+                    "description": "edition number of the token within the catalog (#12 of 100)",
+                    "type": "integer"
+                },
+                "fee": {
+                    "description": "fee paid in wei (gas used * gas price, recorded when mined)",
+                    "type": "string"
+                },
+                "gasPrice": {
+                    "description": "gas price of the transaction",
+                    "type": "integer"
+                },
+                "keywords": {
+                    "description": "keywords (not need to be stored in db)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ClaimKeyword"
+                    }
+                },
+                "language": {
+                    "description": "language of the claimant (metadata and quiz keywords), negotiated if empty",
+                    "type": "string"
+                },
+                "mailioAddress": {
+                    "description": "optional mailio address",
+                    "type": "string"
+                },
+                "maxEditions": {
+                    "description": "max number of tokens within the catalog",
+                    "type": "integer"
+                },
+                "metadataCid": {
+                    "description": "CID of the metadata frozen to IPFS (https metadata mode)",
+                    "type": "string"
+                },
+                "mined": {
+                    "description": "time of the block including the mint transaction (ms)",
+                    "type": "integer"
+                },
+                "mintStatus": {
+                    "description": "outcome of the mint transaction: pending, success or failed",
+                    "type": "string"
+                },
+                "recaptchaToken": {
+                    "description": "recaptcha v3 token // required",
+                    "type": "string"
+                },
+                "reservedFee": {
+                    "description": "fee in wei reserved in budgets until the transaction is mined",
+                    "type": "string"
+                },
+                "signature": {
+                    "description": "signature of categoryId + nonce",
+                    "type": "string"
+                },
+                "tokenId": {
+                    "type": "integer"
+                },
+                "tokenUri": {
+                    "description": "token uri",
+                    "type": "string"
+                },
+                "txHash": {
+                    "description": "transaction hash of the transaction",
+                    "type": "string"
+                },
+                "txStatus": {
+                    "description": "1 = success, 0 = fail",
+                    "type": "integer"
+                },
+                "visitorId": {
+                    "description": "visitor id (part of datastore keys)",
+                    "type": "string"
+                },
+                "walletAddress": {
+                    "description": "publickey of the user retrieved from wallet",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ClaimPreviewPage": {
+            "type": "object",
+            "properties": {
+                "claims": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ClaimPreview"
+                    }
+                },
+                "next": {
+                    "type": "string"
+                },
+                "total": {
+                    "description": "number of all claims of the wallet (only if requested)",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ClaimStats": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ClaimStatsBucket"
+                    }
+                },
+                "catalogId": {
+                    "type": "string"
+                },
+                "claims": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "description": "reverted mint transactions",
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "ms",
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "hour or day (UTC)",
+                    "type": "string"
+                },
+                "mintLatency": {
+                    "$ref": "#/definitions/model.MintLatencyStats"
+                },
+                "pending": {
+                    "description": "mint transactions without a receipt yet",
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "description": "mint transactions included in a block with success status",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "ms",
+                    "type": "integer"
+                },
+                "uniqueVisitors": {
+                    "type": "integer"
+                },
+                "uniqueWallets": {
+                    "type": "integer"
+                },
+                "unknown": {
+                    "description": "claims without a recorded mint outcome (stored before outcomes were recorded)",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ClaimStatsBucket": {
+            "type": "object",
+            "properties": {
+                "claims": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "start": {
+                    "description": "ms",
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "unknown": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ContractAdminTx": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "action": {
+                    "description": "pause, unpause, grantRole, revokeRole",
+                    "type": "string"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "admin wallet address",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "txHash": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ContractImplementation": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "modified": {
+                    "type": "integer"
+                },
+                "txHash": {
+                    "description": "upgrade transaction which set the implementation",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ContractStatus": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "proxy address of the contract",
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "roles": {
+                    "description": "roles of the broker and admin wallets",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RoleMembership"
+                    }
+                }
+            }
+        },
+        "model.ContractUpgrade": {
+            "type": "object",
+            "properties": {
+                "blockNumber": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "implementation": {
+                    "description": "implementation address from the Upgraded event",
+                    "type": "string"
+                },
+                "previousImplementation": {
+                    "type": "string"
+                },
+                "txHash": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ContractUpgradeCheck": {
+            "type": "object",
+            "properties": {
+                "abiChecked": {
+                    "description": "true if ABI of the new implementation was parsed and compared",
+                    "type": "boolean"
+                },
+                "currentImplementation": {
+                    "description": "read from the ERC-1967 implementation slot of the proxy",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "reasons why the upgrade is not safe",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "estimatedGas": {
+                    "type": "integer"
+                },
+                "implementation": {
+                    "type": "string"
+                },
+                "missingMethods": {
+                    "description": "methods and events of the embedded ABI missing in the new ABI",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ok": {
+                    "type": "boolean"
+                },
+                "proxiableUUID": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "description": "possible issues not blocking the upgrade (e.g. selectors not found in bytecode)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.ContractUpgradeInput": {
+            "type": "object",
+            "required": [
+                "abi",
+                "implementation"
+            ],
+            "properties": {
+                "abi": {
+                    "description": "JSON ABI of the new implementation (checked for compatibility with the embedded ABI)",
+                    "type": "string"
+                },
+                "callData": {
+                    "description": "optional hex encoded call executed after the upgrade (upgradeToAndCall)",
+                    "type": "string"
+                },
+                "implementation": {
+                    "description": "address of the new implementation",
+                    "type": "string"
+                }
+            }
+        },
+        "model.EmailPasswordInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Erc721Attribute": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "model.Erc721Json": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Erc721Attribute"
+                    }
+                },
+                "background_color": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "external_url": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Title      string            ` + "`" + `json:\"title\"` + "`" + `\nType       string            ` + "`" + `json:\"type\"` + "`" + `\nProperties *Erc721Properties ` + "`" + `json:\"properties,omitempty\"` + "`" + `",
+                    "type": "string"
+                },
+                "youtube_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.FunnelConversion": {
+            "type": "object",
+            "properties": {
+                "catalogId": {
+                    "type": "string"
+                },
+                "conversion": {
+                    "description": "confirmed mints per requested payload",
+                    "type": "number"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FunnelStepReport"
+                    }
+                }
+            }
+        },
+        "model.FunnelReport": {
+            "type": "object",
+            "properties": {
+                "catalogs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FunnelConversion"
+                    }
+                },
+                "from": {
+                    "description": "ms",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "ms",
+                    "type": "integer"
+                },
+                "total": {
+                    "$ref": "#/definitions/model.FunnelConversion"
+                }
+            }
+        },
+        "model.FunnelStepReport": {
+            "type": "object",
+            "properties": {
+                "conversion": {
+                    "description": "passed per passed first step (payload)",
+                    "type": "number"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "passed": {
+                    "type": "integer"
+                },
+                "reasons": {
+                    "description": "failures per reason code",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "step": {
+                    "type": "string"
+                },
+                "stepConversion": {
+                    "description": "passed per passed previous step",
+                    "type": "number"
+                }
+            }
+        },
+        "model.GovernanceEvent": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "description": "account of the role change or account which (un)paused the contract",
+                    "type": "string"
+                },
+                "alert": {
+                    "description": "reason admins were alerted about the event",
+                    "type": "string"
+                },
+                "blockNumber": {
+                    "type": "integer"
+                },
+                "blockTime": {
+                    "description": "block timestamp in seconds",
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "event": {
+                    "description": "RoleGranted, RoleRevoked, AdminChanged, Paused, Unpaused, Upgraded",
+                    "type": "string"
+                },
                 "id": {
+                    "description": "blockNumber_txIndex_logIndex",
                     "type": "string"
                 },
-                "imageLink": {
-                    "description": "CID/hash of the image",
+                "implementation": {
+                    "type": "string"
+                },
+                "logIndex": {
+                    "type": "integer"
+                },
+                "newAdmin": {
+                    "type": "string"
+                },
+                "previousAdmin": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "sender": {
+                    "description": "sender of the role change",
+                    "type": "string"
+                },
+                "txHash": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.JwtTokenOutput": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MetadataAttribute": {
+            "type": "object",
+            "required": [
+                "value"
+            ],
+            "properties": {
+                "displayType": {
+                    "type": "string",
+                    "enum": [
+                        "number",
+                        "boost_number",
+                        "boost_percentage",
+                        "date"
+                    ]
+                },
+                "maxValue": {
+                    "description": "only numeric attributes",
+                    "type": "number"
+                },
+                "traitType": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "value": {}
+            }
+        },
+        "model.MetadataTemplate": {
+            "type": "object",
+            "required": [
+                "attributes"
+            ],
+            "properties": {
+                "attributes": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/model.MetadataAttribute"
+                    }
+                },
+                "backgroundColor": {
+                    "description": "six-character hexadecimal without a pre-pended #",
                     "type": "string"
                 },
-                "keywords": {
-                    "description": "comma separated list of keywords",
-                    "type": "string",
-                    "maxLength": 1000,
-                    "minLength": 3
+                "externalUrlPattern": {
+                    "description": "placeholders: {catalogId}, {wallet}, {contentLink} (default: catalog content link)",
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "extraFields": {
+                    "description": "additional top level fields (e.g. animation_url)",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "nameTemplate": {
+                    "description": "placeholders: {catalog}, {edition}, {maxEditions}, {type} (e.g. \"{catalog} #{edition}\")",
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "model.MintLatencyStats": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "integer"
+                },
+                "count": {
+                    "description": "number of mined claims",
+                    "type": "integer"
                 },
-                "modified": {
+                "max": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 3
+                "median": {
+                    "type": "integer"
                 },
-                "nftTokensUsed": {
-                    "description": "currently minted tokens for the catalog",
+                "p95": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NftImageList": {
+            "type": "object",
+            "properties": {
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NftImageListItem"
+                    }
+                },
+                "page": {
                     "type": "integer"
                 },
-                "type": {
-                    "type": "string"
+                "pageSize": {
+                    "type": "integer"
                 },
-                "videoLink": {
-                    "description": "YouTube or similar link",
-                    "type": "string"
+                "total": {
+                    "description": "total number of images matching the search",
+                    "type": "integer"
                 }
             }
         },
-        "model.Claim": {
+        "model.NftImageListItem": {
             "type": "object",
-            "required": [
-                "catalogId",
-                "recaptcha_token",
-                "signature",
-                "walletAddress"
-            ],
             "properties": {
-                "catalogId": {
-                    "description": "categoryId to be claimed",
+                "cid": {
                     "type": "string"
                 },
                 "created": {
                     "type": "integer"
                 },
-                "gasPrice": {
-                    "description": "gas price of the transaction",
+                "height": {
                     "type": "integer"
                 },
-                "keywords": {
-                    "description": "keywords (not need to be stored in db)",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.ClaimKeyword"
-                    }
-                },
-                "mailioAddress": {
-                    "description": "optional mailio address",
+                "mime": {
                     "type": "string"
                 },
-                "recaptcha_token": {
-                    "description": "recaptcha v3 token // required",
+                "name": {
+                    "description": "original file name",
                     "type": "string"
                 },
-                "signature": {
-                    "description": "signature of categoryId + nonce",
+                "optimized": {
+                    "$ref": "#/definitions/model.NftImageVariant"
+                },
+                "size": {
+                    "description": "size in bytes of the pinned original",
+                    "type": "integer"
+                },
+                "thumbnail": {
+                    "$ref": "#/definitions/model.NftImageVariant"
+                },
+                "uploader": {
+                    "description": "ID of the user who uploaded the image",
                     "type": "string"
                 },
-                "tokenUri": {
-                    "description": "token uri",
+                "usedByCatalogs": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.NftImageUploadResponse": {
+            "type": "object",
+            "properties": {
+                "hash": {
+                    "description": "CID of the uploaded image (stripped of metadata)",
                     "type": "string"
                 },
-                "txHash": {
-                    "description": "transaction hash of the transaction",
+                "name": {
                     "type": "string"
                 },
-                "walletAddress": {
-                    "description": "publickey of the user retrieved from wallet",
+                "optimized": {
+                    "description": "web optimized version (only decodable images)",
+                    "$ref": "#/definitions/model.NftImageVariant"
+                },
+                "original": {
+                    "$ref": "#/definitions/model.NftImageVariant"
+                },
+                "size": {
                     "type": "string"
+                },
+                "thumbnail": {
+                    "description": "web thumbnail (only decodable images)",
+                    "$ref": "#/definitions/model.NftImageVariant"
                 }
             }
         },
-        "model.ClaimKeyword": {
+        "model.NftImageVariant": {
             "type": "object",
             "properties": {
-                "word": {
+                "cid": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "mime": {
                     "type": "string"
+                },
+                "size": {
+                    "description": "size in bytes",
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
                 }
             }
         },
-        "model.ClaimPreview": {
+        "model.NftPins": {
             "type": "object",
-            "required": [
-                "catalogId",
-                "recaptcha_token",
-                "signature",
-                "walletAddress"
-            ],
             "properties": {
-                "catalogId": {
-                    "description": "categoryId to be claimed",
+                "pins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.PinHealthIssue": {
+            "type": "object",
+            "properties": {
+                "cid": {
                     "type": "string"
                 },
-                "created": {
+                "error": {
+                    "type": "string"
+                },
+                "missing": {
+                    "description": "pinning providers content was not pinned to",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "retrievable": {
+                    "description": "retrievable through the IPFS gateway within the timeout",
+                    "type": "boolean"
+                },
+                "source": {
+                    "description": "catalog/\u003ccatalogId\u003e or claim/\u003cwalletAddress_catalogId\u003e",
+                    "type": "string"
+                }
+            }
+        },
+        "model.PinHealthReport": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "description": "number of checked CIDs",
                     "type": "integer"
                 },
-                "gasPrice": {
-                    "description": "gas price of the transaction",
+                "finished": {
                     "type": "integer"
                 },
-                "keywords": {
-                    "description": "keywords (not need to be stored in db)",
+                "healthy": {
+                    "description": "pinned with all providers and retrievable through the gateway",
+                    "type": "integer"
+                },
+                "repinned": {
+                    "description": "missing pins recovered by re-pinning",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.ClaimKeyword"
+                        "$ref": "#/definitions/model.PinHealthIssue"
                     }
                 },
-                "mailioAddress": {
-                    "description": "optional mailio address",
+                "started": {
+                    "type": "integer"
+                },
+                "unhealthy": {
+                    "description": "content that couldn't be recovered",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PinHealthIssue"
+                    }
+                }
+            }
+        },
+        "model.PinReference": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "imageLink, tokenUri or metadataCid",
                     "type": "string"
                 },
-                "recaptcha_token": {
-                    "description": "recaptcha v3 token // required",
+                "id": {
+                    "description": "catalog ID or walletAddress_catalogId of the claim",
                     "type": "string"
                 },
-                "signature": {
-                    "description": "signature of categoryId + nonce",
+                "type": {
+                    "description": "catalog or claim",
+                    "type": "string"
+                }
+            }
+        },
+        "model.PinRemoval": {
+            "type": "object",
+            "properties": {
+                "cid": {
                     "type": "string"
                 },
-                "tokenId": {
+                "cids": {
+                    "description": "removed CIDs (the image and its variants)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created": {
                     "type": "integer"
                 },
-                "tokenUri": {
-                    "description": "token uri",
+                "error": {
+                    "description": "reason of the failed removal",
                     "type": "string"
                 },
-                "txHash": {
-                    "description": "transaction hash of the transaction",
+                "id": {
                     "type": "string"
                 },
-                "txStatus": {
-                    "description": "1 = success, 0 = fail",
-                    "type": "integer"
+                "reason": {
+                    "type": "string"
                 },
-                "walletAddress": {
-                    "description": "publickey of the user retrieved from wallet",
+                "references": {
+                    "description": "references at the time of removal",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PinReference"
+                    }
+                },
+                "removed": {
+                    "description": "false if content couldn't be removed from all providers",
+                    "type": "boolean"
+                },
+                "user": {
+                    "description": "ID of the user who removed the pin",
                     "type": "string"
                 }
             }
         },
-        "model.EmailPasswordInput": {
+        "model.PodcastDetails": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "feedUrl"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "feedUrl": {
+                    "description": "RSS feed of the podcast",
+                    "type": "string",
+                    "maxLength": 2000
                 },
-                "password": {
-                    "type": "string"
+                "host": {
+                    "type": "string",
+                    "maxLength": 255
                 }
             }
         },
-        "model.JwtTokenOutput": {
+        "model.PodcastEpisodeDetails": {
             "type": "object",
+            "required": [
+                "episodeNumber"
+            ],
             "properties": {
-                "token": {
-                    "type": "string"
+                "durationSeconds": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "episodeNumber": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "feedUrl": {
+                    "description": "RSS feed of the podcast",
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "season": {
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
-        "model.NftImage": {
+        "model.PublicCatalogStats": {
             "type": "object",
             "properties": {
-                "Keys": {
-                    "$ref": "#/definitions/model.NftKeys"
+                "catalogId": {
+                    "type": "string"
+                },
+                "claims": {
+                    "type": "integer"
+                },
+                "claimsToday": {
+                    "description": "claims of the last 24 hours",
+                    "type": "integer"
+                },
+                "lastClaimed": {
+                    "description": "ms",
+                    "type": "integer"
+                },
+                "maxEditions": {
+                    "type": "integer"
                 },
-                "PinLsObject": {
-                    "$ref": "#/definitions/model.NftPinLsObject"
+                "nftTokensUsed": {
+                    "type": "integer"
                 }
             }
         },
-        "model.NftImageUploadResponse": {
+        "model.RoleChangeInput": {
             "type": "object",
+            "required": [
+                "account",
+                "role"
+            ],
             "properties": {
-                "hash": {
+                "account": {
                     "type": "string"
                 },
-                "name": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "MINTER_ROLE",
+                        "PAUSER_ROLE"
+                    ]
+                }
+            }
+        },
+        "model.RoleMembership": {
+            "type": "object",
+            "properties": {
+                "account": {
                     "type": "string"
                 },
-                "size": {
+                "hasRole": {
+                    "type": "boolean"
+                },
+                "role": {
                     "type": "string"
                 }
             }
         },
-        "model.NftKeys": {
-            "type": "object",
-            "additionalProperties": true
-        },
-        "model.NftPinLsObject": {
+        "model.StatsSummary": {
             "type": "object",
             "properties": {
-                "Cid": {
+                "balance": {
+                    "description": "broker balance in wei",
+                    "type": "string"
+                },
+                "balanceNative": {
+                    "description": "broker balance in native currency",
                     "type": "string"
                 },
-                "Type": {
+                "brokerAddress": {
                     "type": "string"
+                },
+                "claimsToday": {
+                    "description": "claims of the last 24 hours",
+                    "type": "integer"
+                },
+                "claimsTotal": {
+                    "type": "integer"
+                },
+                "pendingClaims": {
+                    "description": "claims of the receipt window without a receipt yet",
+                    "type": "integer"
+                },
+                "pendingTransactions": {
+                    "description": "broker transactions waiting in the mempool",
+                    "type": "integer"
                 }
             }
         },
-        "model.NftPins": {
+        "model.VideoDetails": {
             "type": "object",
             "properties": {
-                "pins": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "durationSeconds": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "platform": {
+                    "description": "e.g. YouTube, Vimeo",
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "model.VirtualEventDetails": {
+            "type": "object",
+            "required": [
+                "location",
+                "startTime"
+            ],
+            "properties": {
+                "endTime": {
+                    "description": "ms",
+                    "type": "integer"
+                },
+                "location": {
+                    "description": "URL of the event or place",
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "startTime": {
+                    "description": "ms",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "timezone": {
+                    "description": "IANA time zone (e.g. Europe/Ljubljana)",
+                    "type": "string",
+                    "maxLength": 64
                 }
             }
         }
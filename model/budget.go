@@ -0,0 +1,43 @@
+package model
+
+const BudgetTable = "budget"
+const BudgetSpentTable = "budget_spent"
+
+// ID of the global budget settings within the BudgetTable
+const GlobalBudgetID = "global"
+
+// BudgetSettings limits the spending of the broker wallet
+// all amounts are in native currency (e.g. "0.5" MATIC), empty amount means no limit
+type BudgetSettings struct {
+	DailyLimit          string `json:"dailyLimit,omitempty"`          // max spent per day (UTC) across all catalogs
+	MaxTxFee            string `json:"maxTxFee,omitempty"`            // fee ceiling of a single mint transaction
+	DefaultCatalogLimit string `json:"defaultCatalogLimit,omitempty"` // max spent per catalog (when catalog has no budget of its own)
+	Modified            int64  `json:"modified"`
+}
+
+// CatalogBudget overrides the default catalog limit for a single catalog
+type CatalogBudget struct {
+	CatalogId string `json:"catalogId"`
+	Limit     string `json:"limit" validate:"required"` // max spent for the catalog in native currency
+	Modified  int64  `json:"modified"`
+}
+
+// BudgetSpent is a running total of spent fees (in wei) for a single day or a single catalog
+type BudgetSpent struct {
+	ID       string `json:"id"`    // day_YYYYMMDD or catalog_{catalogId}
+	Spent    string `json:"spent"` // spent amount in wei
+	Modified int64  `json:"modified"`
+}
+
+// BudgetStatus of the global budget (not stored in db)
+type BudgetStatus struct {
+	Settings   BudgetSettings `json:"settings"`
+	SpentToday string         `json:"spentToday"` // in native currency
+}
+
+// CatalogBudgetStatus of a single catalog budget (not stored in db)
+type CatalogBudgetStatus struct {
+	CatalogId string `json:"catalogId"`
+	Limit     string `json:"limit,omitempty"` // effective limit in native currency
+	Spent     string `json:"spent"`           // in native currency
+}
@@ -2,17 +2,64 @@ package model
 
 const CatalogTable = "catalog"
 
+// lifecycle states of a catalog
+const (
+	CatalogStatusDraft     = "draft"     // hidden, not claimable (default of new catalogs)
+	CatalogStatusScheduled = "scheduled" // hidden until publishAt, then published automatically
+	CatalogStatusPublished = "published" // public and claimable
+	CatalogStatusArchived  = "archived"  // hidden, not claimable, existing claims remain readable
+)
+
+// CatalogStatusTransitions are allowed status changes
+var CatalogStatusTransitions = map[string][]string{
+	CatalogStatusDraft:     {CatalogStatusScheduled, CatalogStatusPublished, CatalogStatusArchived},
+	CatalogStatusScheduled: {CatalogStatusDraft, CatalogStatusPublished, CatalogStatusArchived},
+	CatalogStatusPublished: {CatalogStatusArchived},
+	CatalogStatusArchived:  {CatalogStatusDraft, CatalogStatusPublished},
+}
+
 // Catalog serves as knowledge catalog high level description
 type Catalog struct {
-	ID            string `json:"id,omitempty"`
-	Name          string `json:"name" validate:"required,min=3,max=255"`
-	Type          string `json:"type" validate:"required" oneof:"video,article,podcast,podcast-episode,virtual-event"`
-	Description   string `json:"description" validate:"required,min=3,max=1000"`
-	ContentLink   string `json:"contentLink" validate:"required,min=3,max=2000"`
-	Keywords      string `json:"keywords" validate:"required,min=3,max=1000"` // comma separated list of keywords
-	VideoLink     string `json:"videoLink,omitempty"`                         // YouTube or similar link
-	ImageLink     string `json:"imageLink,omitempty"`                         // CID/hash of the image
-	NftTokensUsed int    `json:"nftTokensUsed"`                               //currently minted tokens for the catalog
-	Modified      int64  `json:"modified"`
-	Created       int64  `json:"created"`
+	ID               string                         `json:"id,omitempty"`
+	ExternalRef      string                         `json:"externalRef,omitempty" validate:"omitempty,max=255"` // reference of the catalog in an external system (matched by imports)
+	Name             string                         `json:"name" validate:"required,min=3,max=255"`
+	Type             string                         `json:"type" validate:"required,oneof=video article podcast podcast-episode virtual-event"`
+	Description      string                         `json:"description" validate:"required,min=3,max=1000"`
+	ContentLink      string                         `json:"contentLink" validate:"required,min=3,max=2000"`
+	Keywords         string                         `json:"keywords" validate:"required,min=3,max=1000"`                                          // comma separated list of keywords
+	VideoLink        string                         `json:"videoLink,omitempty"`                                                                  // YouTube or similar link
+	ImageLink        string                         `json:"imageLink,omitempty"`                                                                  // CID/hash of the image
+	DefaultLanguage  string                         `json:"defaultLanguage,omitempty"`                                                            // language of the name, description, content link and keywords (default en)
+	Translations     map[string]*CatalogTranslation `json:"translations,omitempty" validate:"omitempty,max=50,dive,keys,max=35,endkeys,required"` // localized content by language tag (e.g. de, pt-BR)
+	Video            *VideoDetails                  `json:"video,omitempty"`                                                                      // only video catalogs
+	Article          *ArticleDetails                `json:"article,omitempty"`                                                                    // only article catalogs
+	Podcast          *PodcastDetails                `json:"podcast,omitempty"`                                                                    // only podcast catalogs
+	PodcastEpisode   *PodcastEpisodeDetails         `json:"podcastEpisode,omitempty"`                                                             // only podcast-episode catalogs
+	VirtualEvent     *VirtualEventDetails           `json:"virtualEvent,omitempty"`                                                               // only virtual-event catalogs
+	MetadataMode     string                         `json:"metadataMode,omitempty" validate:"omitempty,oneof=ipfs https"`                         // where token URI points to: ipfs (default) or https (metadata endpoint)
+	MetadataTemplate *MetadataTemplate              `json:"metadataTemplate,omitempty"`                                                           // metadata of the claimed tokens (default background and informed attribute if not set)
+	Status           string                         `json:"status,omitempty" validate:"omitempty,oneof=draft scheduled published archived"`       // draft (default), scheduled, published or archived
+	PublishAt        int64                          `json:"publishAt,omitempty"`                                                                  // time (ms) a scheduled catalog is published
+	NftTokensUsed    int                            `json:"nftTokensUsed"`                                                                        //currently minted tokens for the catalog
+	Modified         int64                          `json:"modified"`
+	Created          int64                          `json:"created"`
+}
+
+// CatalogStatusChange changes status of the catalog
+type CatalogStatusChange struct {
+	Status    string `json:"status" validate:"required,oneof=draft scheduled published archived"`
+	PublishAt int64  `json:"publishAt,omitempty"` // required when scheduled (ms)
+}
+
+// LifecycleStatus returns status of the catalog (catalogs created before statuses are published)
+func (c *Catalog) LifecycleStatus() string {
+	if c.Status == "" {
+		return CatalogStatusPublished
+	}
+	return c.Status
+}
+
+// IsPublished returns true if catalog is public and claimable
+func (c *Catalog) IsPublished() bool {
+	return c.LifecycleStatus() == CatalogStatusPublished
 }
@@ -0,0 +1,45 @@
+package model
+
+// content types of a catalog
+const (
+	CatalogTypeVideo          = "video"
+	CatalogTypeArticle        = "article"
+	CatalogTypePodcast        = "podcast"
+	CatalogTypePodcastEpisode = "podcast-episode"
+	CatalogTypeVirtualEvent   = "virtual-event"
+)
+
+// VideoDetails are video specific catalog fields
+type VideoDetails struct {
+	Platform        string `json:"platform,omitempty" validate:"omitempty,max=255"` // e.g. YouTube, Vimeo
+	DurationSeconds int    `json:"durationSeconds,omitempty" validate:"omitempty,min=1"`
+}
+
+// ArticleDetails are article specific catalog fields
+type ArticleDetails struct {
+	Author         string `json:"author,omitempty" validate:"omitempty,max=255"`
+	PublishedAt    int64  `json:"publishedAt,omitempty" validate:"omitempty,min=1"` // ms
+	ReadingMinutes int    `json:"readingMinutes,omitempty" validate:"omitempty,min=1"`
+}
+
+// PodcastDetails are podcast specific catalog fields
+type PodcastDetails struct {
+	FeedUrl string `json:"feedUrl" validate:"required,url,max=2000"` // RSS feed of the podcast
+	Host    string `json:"host,omitempty" validate:"omitempty,max=255"`
+}
+
+// PodcastEpisodeDetails are podcast episode specific catalog fields
+type PodcastEpisodeDetails struct {
+	FeedUrl         string `json:"feedUrl,omitempty" validate:"omitempty,url,max=2000"` // RSS feed of the podcast
+	Season          int    `json:"season,omitempty" validate:"omitempty,min=1"`
+	EpisodeNumber   int    `json:"episodeNumber" validate:"required,min=1"`
+	DurationSeconds int    `json:"durationSeconds,omitempty" validate:"omitempty,min=1"`
+}
+
+// VirtualEventDetails are virtual event specific catalog fields
+type VirtualEventDetails struct {
+	StartTime int64  `json:"startTime" validate:"required,min=1"`                      // ms
+	EndTime   int64  `json:"endTime,omitempty" validate:"omitempty,gtfield=StartTime"` // ms
+	Location  string `json:"location" validate:"required,max=2000"`                    // URL of the event or place
+	Timezone  string `json:"timezone,omitempty" validate:"omitempty,max=64"`           // IANA time zone (e.g. Europe/Ljubljana)
+}
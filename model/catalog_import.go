@@ -0,0 +1,45 @@
+package model
+
+// formats of catalog import and export files
+const (
+	CatalogFormatJSON = "json"
+	CatalogFormatYAML = "yaml"
+	CatalogFormatCSV  = "csv"
+)
+
+// actions of the imported catalogs
+const (
+	CatalogImportCreate    = "create"
+	CatalogImportUpdate    = "update"
+	CatalogImportUnchanged = "unchanged" // stored catalog equals the row, nothing is written
+)
+
+// CatalogImportItem is a catalog of the import file. ImageFile is the name of an image uploaded
+// with the import, it's pinned and set as the imageLink of the catalog
+type CatalogImportItem struct {
+	Catalog
+	ImageFile string `json:"imageFile,omitempty"`
+}
+
+// CatalogImportRow is the outcome of a single catalog of the import file
+type CatalogImportRow struct {
+	Row         int      `json:"row"` // position in the file (starting with 1, CSV header excluded)
+	ID          string   `json:"id,omitempty"`
+	ExternalRef string   `json:"externalRef,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Action      string   `json:"action,omitempty"` // create, update or unchanged (empty if the row is invalid)
+	Stored      bool     `json:"stored"`           // true if the catalog is stored (or unchanged) after the import
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// CatalogImportResult is the report of the import. Nothing is stored on a dry-run or if any row is invalid.
+// If storing a catalog fails, rows before it are stored (see Stored of rows) and the rest is not
+type CatalogImportResult struct {
+	DryRun    bool                `json:"dryRun"`
+	Imported  bool                `json:"imported"` // true if all catalogs were stored
+	Created   int                 `json:"created"`
+	Updated   int                 `json:"updated"`
+	Unchanged int                 `json:"unchanged"`
+	Failed    int                 `json:"failed"`
+	Rows      []*CatalogImportRow `json:"rows"`
+}
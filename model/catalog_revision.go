@@ -0,0 +1,29 @@
+package model
+
+const CatalogRevisionTable = "catalog_revision"
+
+// CatalogRevision is an immutable snapshot of a catalog stored on every catalog write
+type CatalogRevision struct {
+	CatalogId    string                `json:"catalogId"`
+	Revision     int                   `json:"revision"` // sequential number of the revision (starting with 1)
+	Author       string                `json:"author,omitempty"`
+	Changes      []*CatalogFieldChange `json:"changes"`                // changes from the previous revision
+	RestoredFrom int                   `json:"restoredFrom,omitempty"` // revision restored by this revision
+	Catalog      *Catalog              `json:"catalog"`
+	Created      int64                 `json:"created"`
+}
+
+// CatalogFieldChange is a changed field of the catalog (JSON field name)
+type CatalogFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// CatalogRevisionDiff are changes between two revisions of the catalog
+type CatalogRevisionDiff struct {
+	CatalogId string                `json:"catalogId"`
+	From      int                   `json:"from"`
+	To        int                   `json:"to"`
+	Changes   []*CatalogFieldChange `json:"changes"`
+}
@@ -0,0 +1,29 @@
+package model
+
+// sort options of the catalog search
+const (
+	CatalogSortRelevance  = "relevance" // full text match (default when searching by text)
+	CatalogSortCreated    = "created"   // default
+	CatalogSortModified   = "modified"
+	CatalogSortPopularity = "popularity" // number of claims
+)
+
+// CatalogSearch are filters and sorting of the catalog search
+type CatalogSearch struct {
+	Type    string // exact catalog type
+	Keyword string // one of the comma separated catalog keywords
+	Query   string // full text search over name and description
+	Sort    string `validate:"omitempty,oneof=relevance created modified popularity"` // relevance, created, modified or popularity
+	Order   string `validate:"omitempty,oneof=asc desc"`                              // asc or desc (default)
+	Status  string `validate:"omitempty,oneof=draft scheduled published archived"`    // lifecycle status (all if empty)
+	Limit   int    `validate:"omitempty,min=1"`                                       // max number of catalogs (all if 0)
+	Cursor  string // continuation cursor returned with the previous page
+}
+
+// CatalogSearchResult are catalogs matching the search with number of matching catalogs per type
+type CatalogSearchResult struct {
+	Catalogs []*Catalog     `json:"catalogs"`
+	Next     string         `json:"next,omitempty"` // cursor of the following page (empty on the last page)
+	Total    int            `json:"total"`          // number of matching catalogs
+	Facets   map[string]int `json:"facets"`         // number of matching catalogs per type (ignoring type filter)
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language of catalogs without defaultLanguage
+const DefaultLanguage = "en"
+
+// LanguageTagRegex matches simple BCP 47 language tags (e.g. en, de-AT, zh-Hant)
+var LanguageTagRegex = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// CatalogTranslation is a localized variant of the catalog content (missing fields fall back to the default language)
+type CatalogTranslation struct {
+	Name        string `json:"name,omitempty" validate:"omitempty,min=3,max=255"`
+	Description string `json:"description,omitempty" validate:"omitempty,min=3,max=1000"`
+	ContentLink string `json:"contentLink,omitempty" validate:"omitempty,min=3,max=2000"`
+	Keywords    string `json:"keywords,omitempty" validate:"omitempty,min=3,max=1000"` // comma separated list of quiz keywords
+}
+
+// Languages returns the default language of the catalog followed by languages of translations (sorted,
+// so matching by primary language always picks the same translation)
+func (c *Catalog) Languages() []string {
+	translations := []string{}
+	for lang := range c.Translations {
+		if lang != c.Language() {
+			translations = append(translations, lang)
+		}
+	}
+	sort.Strings(translations)
+	return append([]string{c.Language()}, translations...)
+}
+
+// Language returns the language of the catalog fields
+func (c *Catalog) Language() string {
+	if c.DefaultLanguage == "" {
+		return DefaultLanguage
+	}
+	return c.DefaultLanguage
+}
+
+// MatchLanguage returns the first preferred language the catalog is available in. Languages are matched
+// exactly first and then by primary language (de-AT matches de and de-DE). Falls back to the default language
+func (c *Catalog) MatchLanguage(preferred []string) string {
+	available := c.Languages()
+	for _, lang := range preferred {
+		for _, a := range available {
+			if strings.EqualFold(a, lang) {
+				return a
+			}
+		}
+		primary := primaryLanguage(lang)
+		for _, a := range available {
+			if primaryLanguage(a) == primary {
+				return a
+			}
+		}
+	}
+	return c.Language()
+}
+
+// Localized returns a copy of the catalog with content of the language (missing fields fall back to the default language)
+func (c *Catalog) Localized(lang string) *Catalog {
+	localized := *c
+	translation, ok := c.Translations[lang]
+	if !ok || translation == nil {
+		return &localized
+	}
+	if translation.Name != "" {
+		localized.Name = translation.Name
+	}
+	if translation.Description != "" {
+		localized.Description = translation.Description
+	}
+	if translation.ContentLink != "" {
+		localized.ContentLink = translation.ContentLink
+	}
+	if translation.Keywords != "" {
+		localized.Keywords = translation.Keywords
+	}
+	return &localized
+}
+
+func primaryLanguage(tag string) string {
+	return strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+}
@@ -7,21 +7,73 @@ import (
 
 const ClaimTable = "claim"
 const ClaimFingerprintTable = "fingerprint"
+const ClaimEditionTable = "claim_edition"
+
+// secondary indexes of claims (keys end with the creation time and the claim ID)
+const ClaimByCreatedTable = "claim_by_created"
+const ClaimByCatalogTable = "claim_by_catalog"
+const ClaimByWalletTable = "claim_by_wallet"
+const ClaimByVisitorTable = "claim_by_visitor"
 
 type Claim struct {
-	CatalogId      string         `json:"catalogId" validate:"required"`      // categoryId to be claimed
-	WalletAddress  string         `json:"walletAddress" validate:"required"`  // publickey of the user retrieved from wallet
-	MailioAddress  string         `json:"mailioAddress,omitempty"`            // optional mailio address
-	Signature      string         `json:"signature" validate:"required"`      // signature of categoryId + nonce
-	ReCaptchaToken string         `json:"recaptchaToken" validate:"required"` // recaptcha v3 token // required
-	GasPrice       uint64         `json:"gasPrice"`                           // gas price of the transaction
-	TxHash         string         `json:"txHash,omitempty"`                   // transaction hash of the transaction
-	TokenUri       string         `json:"tokenUri,omitempty"`                 // token uri
-	VisitorId      string         `json:"visitorId" validate:"required"`      // visitor id
-	Keywords       []ClaimKeyword `json:"keywords,omitempty"`                 // keywords (not need to be stored in db)
+	CatalogId      string         `json:"catalogId" validate:"required"`               // categoryId to be claimed
+	WalletAddress  string         `json:"walletAddress" validate:"required"`           // publickey of the user retrieved from wallet
+	MailioAddress  string         `json:"mailioAddress,omitempty"`                     // optional mailio address
+	Signature      string         `json:"signature" validate:"required"`               // signature of categoryId + nonce
+	ReCaptchaToken string         `json:"recaptchaToken" validate:"required"`          // recaptcha v3 token // required
+	GasPrice       uint64         `json:"gasPrice"`                                    // gas price of the transaction
+	Fee            string         `json:"fee,omitempty"`                               // fee paid in wei (gas used * gas price, recorded when mined)
+	ReservedFee    string         `json:"reservedFee,omitempty"`                       // fee in wei reserved in budgets until the transaction is mined
+	BudgetDay      string         `json:"budgetDay,omitempty"`                         // daily budget the fee is reserved in (day_YYYYMMDD)
+	TxHash         string         `json:"txHash,omitempty"`                            // transaction hash of the transaction
+	TokenUri       string         `json:"tokenUri,omitempty"`                          // token uri
+	MetadataCid    string         `json:"metadataCid,omitempty"`                       // CID of the metadata frozen to IPFS (https metadata mode)
+	Edition        int            `json:"edition,omitempty"`                           // edition number of the token within the catalog (#12 of 100)
+	MaxEditions    int            `json:"maxEditions,omitempty"`                       // max number of tokens within the catalog
+	VisitorId      string         `json:"visitorId" validate:"required,excludesall=/"` // visitor id (part of datastore keys)
+	Language       string         `json:"language,omitempty"`                          // language of the claimant (metadata and quiz keywords), negotiated if empty
+	Keywords       []ClaimKeyword `json:"keywords,omitempty"`                          // keywords (not need to be stored in db)
+	MintStatus     string         `json:"mintStatus,omitempty"`                        // outcome of the mint transaction: pending, success or failed
+	Mined          int64          `json:"mined,omitempty"`                             // time of the block including the mint transaction (ms)
 	Created        int64          `json:"created"`
 }
 
+// outcomes of mint transactions (MintStatus of claims)
+const (
+	MintPending = "pending"
+	MintSuccess = "success"
+	MintFailed  = "failed"
+)
+
+// ClaimFilter selects listed claims (empty fields match all claims). Since and Until are creation times in ms
+type ClaimFilter struct {
+	CatalogId     string
+	WalletAddress string
+	VisitorId     string
+	Since         int64 // created at or after (0 = no lower bound)
+	Until         int64 // created before (0 = no upper bound)
+}
+
+// Matches checks if the claim is selected by the filter
+func (cf *ClaimFilter) Matches(claim *Claim) bool {
+	if cf.CatalogId != "" && claim.CatalogId != cf.CatalogId {
+		return false
+	}
+	if cf.WalletAddress != "" && claim.WalletAddress != cf.WalletAddress {
+		return false
+	}
+	if cf.VisitorId != "" && claim.VisitorId != cf.VisitorId {
+		return false
+	}
+	if cf.Since > 0 && claim.Created < cf.Since {
+		return false
+	}
+	if cf.Until > 0 && claim.Created >= cf.Until {
+		return false
+	}
+	return true
+}
+
 // fingerprinting each catalogId claim in order to prevent users
 // getting the same catalogId claim multiple times
 type ClaimFingerprint struct {
@@ -29,6 +81,13 @@ type ClaimFingerprint struct {
 	VisitorId string `json:"visitorId" validate:"required"` // fingerprint of the user
 }
 
+// last edition number assigned to a claim of the catalog
+type ClaimEdition struct {
+	CatalogId   string `json:"catalogId"`
+	LastEdition int    `json:"lastEdition"`
+	Modified    int64  `json:"modified"`
+}
+
 // preview of the claimed token (not need to be stored in db)
 // tokenId is retrieved from the blockchain
 type ClaimPreview struct {
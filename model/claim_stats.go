@@ -0,0 +1,78 @@
+package model
+
+// bucket intervals of claim statistics
+const (
+	StatsIntervalHour = "hour"
+	StatsIntervalDay  = "day"
+)
+
+// ClaimStats of claims created within [from, to) of a single catalog (all catalogs if catalogId is empty)
+type ClaimStats struct {
+	CatalogId      string              `json:"catalogId,omitempty"`
+	From           int64               `json:"from"`     // ms
+	To             int64               `json:"to"`       // ms
+	Interval       string              `json:"interval"` // hour or day (UTC)
+	Claims         int                 `json:"claims"`
+	UniqueWallets  int                 `json:"uniqueWallets"`
+	UniqueVisitors int                 `json:"uniqueVisitors"`
+	Succeeded      int                 `json:"succeeded"` // mint transactions included in a block with success status
+	Failed         int                 `json:"failed"`    // reverted mint transactions
+	Pending        int                 `json:"pending"`   // mint transactions without a receipt yet
+	Unknown        int                 `json:"unknown"`   // claims without a recorded mint outcome (stored before outcomes were recorded)
+	MintLatency    *MintLatencyStats   `json:"mintLatency,omitempty"`
+	Buckets        []*ClaimStatsBucket `json:"buckets"`
+}
+
+// ClaimStatsBucket counts claims created within a single hour or day
+type ClaimStatsBucket struct {
+	Start     int64 `json:"start"` // ms
+	Claims    int   `json:"claims"`
+	Succeeded int   `json:"succeeded"`
+	Failed    int   `json:"failed"`
+	Pending   int   `json:"pending"`
+	Unknown   int   `json:"unknown"`
+}
+
+// MintLatencyStats of time between the claim and the block including its mint transaction (ms)
+type MintLatencyStats struct {
+	Count   int   `json:"count"` // number of mined claims
+	Average int64 `json:"average"`
+	Median  int64 `json:"median"`
+	P95     int64 `json:"p95"`
+	Max     int64 `json:"max"`
+}
+
+// CatalogRanking of catalogs with the most claims created within [from, to)
+type CatalogRanking struct {
+	From     int64                  `json:"from"`
+	To       int64                  `json:"to"`
+	Catalogs []*CatalogRankingEntry `json:"catalogs"`
+}
+
+type CatalogRankingEntry struct {
+	CatalogId     string `json:"catalogId"`
+	Name          string `json:"name,omitempty"`
+	Claims        int    `json:"claims"`
+	UniqueWallets int    `json:"uniqueWallets"`
+}
+
+// StatsSummary of the bridge for the admin dashboard
+type StatsSummary struct {
+	BrokerAddress       string `json:"brokerAddress"`
+	Balance             string `json:"balance"`             // broker balance in wei
+	BalanceNative       string `json:"balanceNative"`       // broker balance in native currency
+	PendingTransactions uint64 `json:"pendingTransactions"` // broker transactions waiting in the mempool
+	PendingClaims       int    `json:"pendingClaims"`       // claims of the receipt window without a receipt yet
+	ClaimsToday         int    `json:"claimsToday"`         // claims of the last 24 hours
+	ClaimsTotal         int    `json:"claimsTotal"`
+}
+
+// PublicCatalogStats are non-sensitive counts of a published catalog
+type PublicCatalogStats struct {
+	CatalogId     string `json:"catalogId"`
+	Claims        int    `json:"claims"`
+	ClaimsToday   int    `json:"claimsToday"` // claims of the last 24 hours
+	NftTokensUsed int    `json:"nftTokensUsed"`
+	MaxEditions   int    `json:"maxEditions,omitempty"`
+	LastClaimed   int64  `json:"lastClaimed,omitempty"` // ms
+}
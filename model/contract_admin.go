@@ -0,0 +1,47 @@
+package model
+
+const ContractAdminTxTable = "contract_admin_tx"
+
+// contract roles manageable through the admin API
+const (
+	MinterRole = "MINTER_ROLE"
+	PauserRole = "PAUSER_ROLE"
+)
+
+// contract administration actions
+const (
+	ContractActionPause      = "pause"
+	ContractActionUnpause    = "unpause"
+	ContractActionGrantRole  = "grantRole"
+	ContractActionRevokeRole = "revokeRole"
+)
+
+// ContractStatus of the Mailio NFT contract (not stored in db)
+type ContractStatus struct {
+	Address string            `json:"address"` // proxy address of the contract
+	Paused  bool              `json:"paused"`
+	Roles   []*RoleMembership `json:"roles"` // roles of the broker and admin wallets
+}
+
+// RoleMembership tells if account has the contract role (not stored in db)
+type RoleMembership struct {
+	Role    string `json:"role"`
+	Account string `json:"account"`
+	HasRole bool   `json:"hasRole"`
+}
+
+// RoleChangeInput is input for granting or revoking a contract role
+type RoleChangeInput struct {
+	Role    string `json:"role" validate:"required,oneof=MINTER_ROLE PAUSER_ROLE"`
+	Account string `json:"account" validate:"required,eth_addr"`
+}
+
+// ContractAdminTx tracks transactions sent by the admin wallet
+type ContractAdminTx struct {
+	TxHash  string `json:"txHash"`
+	Action  string `json:"action"` // pause, unpause, grantRole, revokeRole
+	Role    string `json:"role,omitempty"`
+	Account string `json:"account,omitempty"`
+	From    string `json:"from"` // admin wallet address
+	Created int64  `json:"created"`
+}
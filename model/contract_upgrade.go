@@ -0,0 +1,47 @@
+package model
+
+const ContractUpgradeTable = "contract_upgrade"
+const ContractSettingTable = "contract_setting"
+
+// ID of the current implementation address within the ContractSettingTable
+const ContractImplementationID = "implementation"
+
+// contract administration action of upgrading the proxy
+const ContractActionUpgrade = "upgrade"
+
+// ContractUpgradeInput for upgrading the Mailio NFT proxy to a new implementation
+type ContractUpgradeInput struct {
+	Implementation string `json:"implementation" validate:"required,eth_addr"` // address of the new implementation
+	Abi            string `json:"abi" validate:"required"`                     // JSON ABI of the new implementation (checked for compatibility with the embedded ABI)
+	CallData       string `json:"callData,omitempty"`                          // optional hex encoded call executed after the upgrade (upgradeToAndCall)
+}
+
+// ContractUpgradeCheck is the result of pre-upgrade checks (not stored in db)
+type ContractUpgradeCheck struct {
+	Implementation        string   `json:"implementation"`
+	CurrentImplementation string   `json:"currentImplementation"` // read from the ERC-1967 implementation slot of the proxy
+	ProxiableUUID         string   `json:"proxiableUUID"`
+	AbiChecked            bool     `json:"abiChecked"`     // true if ABI of the new implementation was parsed and compared
+	MissingMethods        []string `json:"missingMethods"` // methods and events of the embedded ABI missing in the new ABI
+	EstimatedGas          uint64   `json:"estimatedGas"`
+	Warnings              []string `json:"warnings"` // possible issues not blocking the upgrade (e.g. selectors not found in bytecode)
+	Errors                []string `json:"errors"`   // reasons why the upgrade is not safe
+	Ok                    bool     `json:"ok"`
+}
+
+// ContractUpgrade records a completed proxy upgrade
+type ContractUpgrade struct {
+	TxHash                 string `json:"txHash"`
+	BlockNumber            uint64 `json:"blockNumber"`
+	PreviousImplementation string `json:"previousImplementation"`
+	Implementation         string `json:"implementation"` // implementation address from the Upgraded event
+	From                   string `json:"from"`
+	Created                int64  `json:"created"`
+}
+
+// ContractImplementation is the currently stored implementation address of the proxy
+type ContractImplementation struct {
+	Address  string `json:"address"`
+	TxHash   string `json:"txHash,omitempty"` // upgrade transaction which set the implementation
+	Modified int64  `json:"modified"`
+}
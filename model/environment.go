@@ -2,14 +2,15 @@ package model
 
 import (
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/go-resty/resty/v2"
-	leveldb "github.com/ipfs/go-ds-leveldb"
+	"github.com/ethereum/go-ethereum/rpc"
 	nft "github.com/mailio/mailio-nft-server/onchain/mailionft"
+	"github.com/mailio/mailio-nft-server/pinning"
 )
 
 type Environment struct {
-	DB               *leveldb.Datastore
-	EthClient        *ethclient.Client
-	NftContract      *nft.Mailionft
-	IpfsInfuraClient *resty.Client
+	*Repositories // catalogs, claims, editions, fingerprints, users and the key-value DB (storage backend selected in config)
+	EthClient     *ethclient.Client
+	RpcClient     *rpc.Client // raw RPC client of the EthClient (batch requests)
+	NftContract   *nft.Mailionft
+	Pinning       *pinning.Replicator
 }
@@ -3,9 +3,21 @@ package model
 import "errors"
 
 var (
-	ErrNotFound     = errors.New("Item not found")
-	ErrUnauthorized = errors.New("Unauthorized")
-	ErrExists       = errors.New("Item already exists")
-	ErrSignature    = errors.New("invalid signature")
-	ErrKeyword      = errors.New("keywords do not match")
+	ErrNotFound       = errors.New("Item not found")
+	ErrUnauthorized   = errors.New("Unauthorized")
+	ErrExists         = errors.New("Item already exists")
+	ErrSignature      = errors.New("invalid signature")
+	ErrKeyword        = errors.New("keywords do not match")
+	ErrBudget         = errors.New("spending budget exceeded")
+	ErrMissingRole    = errors.New("missing required contract role")
+	ErrUpgradeCheck   = errors.New("contract upgrade checks failed")
+	ErrSoldOut        = errors.New("all tokens of the catalog have been claimed")
+	ErrCidMismatch    = errors.New("CID returned by IPFS doesn't match the computed CID")
+	ErrInvalidImage   = errors.New("invalid image")
+	ErrInvalidStatus  = errors.New("invalid catalog status transition")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrPinReferenced  = errors.New("pinned content is referenced by catalogs or claims")
+	ErrInvalidImport  = errors.New("invalid import file")
+	ErrInvalidRange   = errors.New("invalid time range")
+	ErrInvalidCatalog = errors.New("invalid catalog")
 )
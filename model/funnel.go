@@ -0,0 +1,71 @@
+package model
+
+const FunnelEventTable = "funnel_event"
+
+// steps of the claim funnel in the order users go through them
+const (
+	FunnelPayload   = "payload"   // signing payload requested
+	FunnelSubmitted = "submitted" // signed claim submitted (input, catalog and visitor fingerprint checked)
+	FunnelCaptcha   = "captcha"   // reCaptcha verified
+	FunnelSignature = "signature" // EIP-712 signature verified
+	FunnelKeywords  = "keywords"  // keywords matched
+	FunnelMint      = "mint"      // mint transaction sent
+	FunnelConfirmed = "confirmed" // mint transaction included in a block with success status
+)
+
+// FunnelSteps are funnel steps in order
+var FunnelSteps = []string{FunnelPayload, FunnelSubmitted, FunnelCaptcha, FunnelSignature, FunnelKeywords, FunnelMint, FunnelConfirmed}
+
+// reason codes of failed funnel steps
+const (
+	FunnelReasonCatalogUnavailable   = "catalog_unavailable"   // catalog doesn't exist or isn't published
+	FunnelReasonAlreadyClaimed       = "already_claimed"       // wallet has already claimed the catalog (ErrExists)
+	FunnelReasonInvalidInput         = "invalid_input"         // claim body isn't valid
+	FunnelReasonDuplicateFingerprint = "duplicate_fingerprint" // visitor has already claimed the catalog
+	FunnelReasonCaptchaFailed        = "captcha_failed"        // reCaptcha rejected the token
+	FunnelReasonCaptchaError         = "captcha_error"         // reCaptcha couldn't be verified
+	FunnelReasonInvalidSignature     = "invalid_signature"     // ErrSignature
+	FunnelReasonInvalidKeywords      = "invalid_keywords"      // ErrKeyword
+	FunnelReasonBudgetExceeded       = "budget_exceeded"       // ErrBudget
+	FunnelReasonSoldOut              = "sold_out"              // ErrSoldOut
+	FunnelReasonMintError            = "mint_error"            // contract call or other failure before the transaction was sent
+	FunnelReasonReverted             = "reverted"              // mint transaction failed on-chain
+)
+
+// FunnelEvent records a user passing or failing a step of the claim funnel
+type FunnelEvent struct {
+	ID            string `json:"id"` // created_xid (ordered by time)
+	Step          string `json:"step"`
+	Passed        bool   `json:"passed"`
+	Reason        string `json:"reason,omitempty"` // reason code of the failure
+	CatalogId     string `json:"catalogId,omitempty"`
+	WalletAddress string `json:"walletAddress,omitempty"`
+	VisitorId     string `json:"visitorId,omitempty"`
+	TxHash        string `json:"txHash,omitempty"`
+	Created       int64  `json:"created"`
+}
+
+// FunnelReport of funnel events within [from, to) of all catalogs combined and of every single catalog
+type FunnelReport struct {
+	From     int64               `json:"from"` // ms
+	To       int64               `json:"to"`   // ms
+	Total    *FunnelConversion   `json:"total"`
+	Catalogs []*FunnelConversion `json:"catalogs"`
+}
+
+// FunnelConversion of a catalog (all catalogs if catalogId is empty)
+type FunnelConversion struct {
+	CatalogId  string              `json:"catalogId,omitempty"`
+	Steps      []*FunnelStepReport `json:"steps"`
+	Conversion float64             `json:"conversion"` // confirmed mints per requested payload
+}
+
+// FunnelStepReport counts events of a single step
+type FunnelStepReport struct {
+	Step           string         `json:"step"`
+	Passed         int            `json:"passed"`
+	Failed         int            `json:"failed"`
+	Reasons        map[string]int `json:"reasons,omitempty"` // failures per reason code
+	Conversion     float64        `json:"conversion"`        // passed per passed first step (payload)
+	StepConversion float64        `json:"stepConversion"`    // passed per passed previous step
+}
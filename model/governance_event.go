@@ -0,0 +1,40 @@
+package model
+
+const GovernanceEventTable = "governance_event"
+
+// ID of the last block scanned for governance events within the ContractSettingTable
+const GovernanceLastBlockID = "governance_last_block"
+
+// governance events of the Mailio NFT contract
+const (
+	GovernanceRoleGranted  = "RoleGranted"
+	GovernanceRoleRevoked  = "RoleRevoked"
+	GovernanceAdminChanged = "AdminChanged"
+	GovernancePaused       = "Paused"
+	GovernanceUnpaused     = "Unpaused"
+	GovernanceUpgraded     = "Upgraded"
+)
+
+// GovernanceEvent is an on-chain role, admin, pause or upgrade event of the Mailio NFT contract
+type GovernanceEvent struct {
+	ID             string `json:"id"`    // blockNumber_txIndex_logIndex
+	Event          string `json:"event"` // RoleGranted, RoleRevoked, AdminChanged, Paused, Unpaused, Upgraded
+	Role           string `json:"role,omitempty"`
+	Account        string `json:"account,omitempty"` // account of the role change or account which (un)paused the contract
+	Sender         string `json:"sender,omitempty"`  // sender of the role change
+	PreviousAdmin  string `json:"previousAdmin,omitempty"`
+	NewAdmin       string `json:"newAdmin,omitempty"`
+	Implementation string `json:"implementation,omitempty"`
+	BlockNumber    uint64 `json:"blockNumber"`
+	BlockTime      int64  `json:"blockTime"` // block timestamp in seconds
+	TxHash         string `json:"txHash"`
+	LogIndex       uint   `json:"logIndex"`
+	Alert          string `json:"alert,omitempty"` // reason admins were alerted about the event
+	Created        int64  `json:"created"`
+}
+
+// GovernanceLastBlock is the last block scanned for governance events
+type GovernanceLastBlock struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	Modified    int64  `json:"modified"`
+}
@@ -1,21 +1,53 @@
 package model
 
+import "encoding/json"
+
 type NftImageUploadResponse struct {
-	Name string `json:"name"`
-	Hash string `json:"hash"`
-	Size string `json:"size"`
+	Name      string           `json:"name"`
+	Hash      string           `json:"hash"` // CID of the uploaded image (stripped of metadata)
+	Size      string           `json:"size"`
+	Original  *NftImageVariant `json:"original,omitempty"`
+	Optimized *NftImageVariant `json:"optimized,omitempty"` // web optimized version (only decodable images)
+	Thumbnail *NftImageVariant `json:"thumbnail,omitempty"` // web thumbnail (only decodable images)
+}
+
+// NftImageVariant is a processed image pinned to IPFS
+type NftImageVariant struct {
+	Cid    string `json:"cid"`
+	Mime   string `json:"mime"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int    `json:"size"` // size in bytes
 }
 
+const NftImageTable = "nft_image"
+
+// NftImage is an image uploaded through the bridge (keyed by CID of the original)
 type NftImage struct {
-	PinLsList   NftKeys        `json:"Keys"`
-	PinLsObject NftPinLsObject `json:"PinLsObject"`
+	Cid       string           `json:"cid"`
+	Name      string           `json:"name"` // original file name
+	Size      int              `json:"size"` // size in bytes of the pinned original
+	Mime      string           `json:"mime"`
+	Width     int              `json:"width,omitempty"`
+	Height    int              `json:"height,omitempty"`
+	Optimized *NftImageVariant `json:"optimized,omitempty"`
+	Thumbnail *NftImageVariant `json:"thumbnail,omitempty"`
+	Uploader  string           `json:"uploader,omitempty"` // ID of the user who uploaded the image
+	Created   int64            `json:"created"`
 }
 
-type NftKeys map[string]interface{}
+// NftImageListItem is an image with number of catalogs using it
+type NftImageListItem struct {
+	*NftImage
+	UsedByCatalogs int `json:"usedByCatalogs"`
+}
 
-type NftPinLsObject struct {
-	Cid  string `json:"Cid"`
-	Type string `json:"Type"`
+// NftImageList is a page of uploaded images
+type NftImageList struct {
+	Images   []*NftImageListItem `json:"images"`
+	Total    int                 `json:"total"` // total number of images matching the search
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"pageSize"`
 }
 
 type NftPins struct {
@@ -26,13 +58,33 @@ type Erc721Json struct {
 	// Title      string            `json:"title"`
 	// Type       string            `json:"type"`
 	// Properties *Erc721Properties `json:"properties,omitempty"`
-	Name            string             `json:"name"`
-	Description     string             `json:"description"`
-	Image           string             `json:"image"`
-	YoutubeUrl      string             `json:"youtube_url,omitempty"`
-	BackgroundColor string             `json:"background_color,omitempty"`
-	ExternalUrl     string             `json:"external_url,omitempty"`
-	Attributes      []*Erc721Attribute `json:"attributes"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description"`
+	Image           string                 `json:"image"`
+	YoutubeUrl      string                 `json:"youtube_url,omitempty"`
+	BackgroundColor string                 `json:"background_color,omitempty"`
+	ExternalUrl     string                 `json:"external_url,omitempty"`
+	Attributes      []*Erc721Attribute     `json:"attributes"`
+	ExtraFields     map[string]interface{} `json:"-"` // additional top level fields merged into JSON
+}
+
+// MarshalJSON merges extra fields into the top level metadata JSON
+func (e Erc721Json) MarshalJSON() ([]byte, error) {
+	type erc721Json Erc721Json
+	b, err := json.Marshal(erc721Json(e))
+	if err != nil || len(e.ExtraFields) == 0 {
+		return b, err
+	}
+	merged := map[string]interface{}{}
+	if err := json.Unmarshal(b, &merged); err != nil {
+		return nil, err
+	}
+	for k, v := range e.ExtraFields {
+		if _, exists := merged[k]; !exists {
+			merged[k] = v
+		}
+	}
+	return json.Marshal(merged)
 }
 
 type Erc721Attribute map[string]interface{}
@@ -0,0 +1,27 @@
+package model
+
+// metadata modes of a catalog (where token URI of claimed NFTs points to)
+const (
+	MetadataModeIpfs  = "ipfs"  // metadata JSON is uploaded to IPFS for every claim
+	MetadataModeHttps = "https" // metadata is served by the bridge at /metadata/:tokenId
+)
+
+// display types of numeric attributes (OpenSea metadata standards)
+var MetadataDisplayTypes = []string{"number", "boost_number", "boost_percentage", "date"}
+
+// MetadataTemplate defines ERC-721 metadata of the tokens claimed from a catalog
+type MetadataTemplate struct {
+	NameTemplate       string                 `json:"nameTemplate,omitempty" validate:"omitempty,max=255"`        // placeholders: {catalog}, {edition}, {maxEditions}, {type} (e.g. "{catalog} #{edition}")
+	BackgroundColor    string                 `json:"backgroundColor,omitempty" validate:"omitempty,len=6"`       // six-character hexadecimal without a pre-pended #
+	ExternalUrlPattern string                 `json:"externalUrlPattern,omitempty" validate:"omitempty,max=2000"` // placeholders: {catalogId}, {wallet}, {contentLink} (default: catalog content link)
+	Attributes         []*MetadataAttribute   `json:"attributes,omitempty" validate:"omitempty,max=50,dive,required"`
+	ExtraFields        map[string]interface{} `json:"extraFields,omitempty"` // additional top level fields (e.g. animation_url)
+}
+
+// MetadataAttribute is a single trait of the token
+type MetadataAttribute struct {
+	TraitType   string      `json:"traitType,omitempty" validate:"max=255"`
+	DisplayType string      `json:"displayType,omitempty" validate:"omitempty,oneof=number boost_number boost_percentage date"`
+	Value       interface{} `json:"value" validate:"required"`
+	MaxValue    float64     `json:"maxValue,omitempty"` // only numeric attributes
+}
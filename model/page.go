@@ -0,0 +1,21 @@
+package model
+
+// page sizes of list endpoints
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+// ClaimPage is a page of claims. Next is the cursor of the following page (empty on the last page)
+type ClaimPage struct {
+	Claims []*Claim `json:"claims"`
+	Next   string   `json:"next,omitempty"`
+	Total  *int     `json:"total,omitempty"` // number of all claims (only if requested)
+}
+
+// ClaimPreviewPage is a page of claims with token IDs. Next is the cursor of the following page (empty on the last page)
+type ClaimPreviewPage struct {
+	Claims []*ClaimPreview `json:"claims"`
+	Next   string          `json:"next,omitempty"`
+	Total  *int            `json:"total,omitempty"` // number of all claims of the wallet (only if requested)
+}
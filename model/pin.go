@@ -0,0 +1,13 @@
+package model
+
+const PinTable = "pin"
+
+// Pin is content uploaded and pinned to IPFS by the bridge (keyed by locally computed CID)
+type Pin struct {
+	Cid       string   `json:"cid"`
+	Name      string   `json:"name"`      // file name of the upload
+	Size      int      `json:"size"`      // size in bytes
+	Locations []string `json:"locations"` // names of pinning providers content is pinned to
+	Created   int64    `json:"created"`
+	Modified  int64    `json:"modified"`
+}
@@ -0,0 +1,25 @@
+package model
+
+const PinHealthTable = "pin_health"
+
+// ID of the latest pin health report within the PinHealthTable
+const PinHealthReportID = "latest"
+
+// PinHealthReport is the result of checking all CIDs the bridge depends on
+type PinHealthReport struct {
+	Checked   int               `json:"checked"`   // number of checked CIDs
+	Healthy   int               `json:"healthy"`   // pinned with all providers and retrievable through the gateway
+	Repinned  []*PinHealthIssue `json:"repinned"`  // missing pins recovered by re-pinning
+	Unhealthy []*PinHealthIssue `json:"unhealthy"` // content that couldn't be recovered
+	Started   int64             `json:"started"`
+	Finished  int64             `json:"finished"`
+}
+
+// PinHealthIssue describes a CID missing at some of the pinning providers or not retrievable through the gateway
+type PinHealthIssue struct {
+	Cid         string   `json:"cid"`
+	Source      string   `json:"source"`      // catalog/<catalogId> or claim/<walletAddress_catalogId>
+	Missing     []string `json:"missing"`     // pinning providers content was not pinned to
+	Retrievable bool     `json:"retrievable"` // retrievable through the IPFS gateway within the timeout
+	Error       string   `json:"error,omitempty"`
+}
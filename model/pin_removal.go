@@ -0,0 +1,29 @@
+package model
+
+const PinRemovalTable = "pin_removal"
+
+// types of references to pinned content
+const (
+	PinReferenceCatalog = "catalog"
+	PinReferenceClaim   = "claim"
+)
+
+// PinReference is a catalog or claim depending on pinned content
+type PinReference struct {
+	Type  string `json:"type"`  // catalog or claim
+	ID    string `json:"id"`    // catalog ID or walletAddress_catalogId of the claim
+	Field string `json:"field"` // imageLink, tokenUri or metadataCid
+}
+
+// PinRemoval is an audit log entry of a forced removal of pinned content
+type PinRemoval struct {
+	ID         string          `json:"id"`
+	Cid        string          `json:"cid"`
+	Cids       []string        `json:"cids,omitempty"` // removed CIDs (the image and its variants)
+	Reason     string          `json:"reason"`
+	User       string          `json:"user,omitempty"`       // ID of the user who removed the pin
+	References []*PinReference `json:"references,omitempty"` // references at the time of removal
+	Removed    bool            `json:"removed"`              // false if content couldn't be removed from all providers
+	Error      string          `json:"error,omitempty"`      // reason of the failed removal
+	Created    int64           `json:"created"`
+}
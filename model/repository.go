@@ -0,0 +1,91 @@
+package model
+
+import (
+	"context"
+	"io"
+
+	"github.com/ipfs/go-datastore"
+)
+
+// Repositories of the storage backend selected in config (leveldb, memory or SQL)
+type Repositories struct {
+	Catalogs     CatalogRepository
+	Claims       ClaimRepository
+	Editions     EditionRepository
+	Fingerprints FingerprintRepository
+	FunnelEvents FunnelEventRepository
+	Users        UserRepository
+	// DB stores all other tables (revisions, budgets, pins, images, contract history and governance)
+	// as key-value pairs in the same backend
+	DB     datastore.Datastore
+	Closer io.Closer // closes the storage backend (nil if there is nothing to close)
+}
+
+// CatalogRepository stores catalogs by ID
+type CatalogRepository interface {
+	// Get returns the catalog or ErrNotFound
+	Get(ctx context.Context, id string) (*Catalog, error)
+	// Put inserts or replaces the catalog
+	Put(ctx context.Context, catalog *Catalog) error
+	// List returns up to limit catalogs (all if 0) ordered by ID descending (newest first)
+	List(ctx context.Context, limit int) ([]*Catalog, error)
+}
+
+// ClaimRepository stores claims (unique per wallet and catalog). Claims are listed newest first (descending
+// by creation time) using secondary indexes by catalog, wallet, visitor and creation time written with the claim
+type ClaimRepository interface {
+	// Get returns the claim of the wallet or ErrNotFound
+	Get(ctx context.Context, catalogId string, walletAddress string) (*Claim, error)
+	// Put inserts or replaces the claim and its index entries
+	Put(ctx context.Context, claim *Claim) error
+	// List returns up to limit claims (all if 0) newest first
+	List(ctx context.Context, limit int) ([]*Claim, error)
+	// ListPage returns up to limit claims selected by the filter newest first starting after the cursor and the
+	// cursor of the following page (empty on the last page). A limit of 0 (or less) returns all remaining claims
+	// without a next page. Returns ErrInvalidCursor if cursor can't be decoded
+	ListPage(ctx context.Context, filter *ClaimFilter, cursor string, limit int) ([]*Claim, string, error)
+	// Count returns number of claims selected by the filter
+	Count(ctx context.Context, filter *ClaimFilter) (int, error)
+	// CountByCatalog returns number of claims per catalog ID
+	CountByCatalog(ctx context.Context) (map[string]int, error)
+	// RebuildIndexes recreates the secondary indexes from stored claims and returns number of indexed claims
+	RebuildIndexes(ctx context.Context) (int, error)
+}
+
+// EditionRepository assigns edition numbers of catalog tokens. Assignment is atomic, so replicas sharing
+// the backend never assign the same edition
+type EditionRepository interface {
+	// Next assigns and returns the edition following both the last assigned edition and minted (number of
+	// tokens minted on-chain). Returns ErrSoldOut if the edition would exceed maxEditions
+	Next(ctx context.Context, catalogId string, minted int, maxEditions int) (int, error)
+	// Release takes back the edition if no later edition has been assigned (e.g. mint transaction wasn't sent)
+	Release(ctx context.Context, catalogId string, edition int) error
+	// Last returns the last assigned edition of the catalog (0 if none)
+	Last(ctx context.Context, catalogId string) (int, error)
+}
+
+// FingerprintRepository stores visitor fingerprints of claims (one per catalog and visitor)
+type FingerprintRepository interface {
+	// Get returns the fingerprint or ErrNotFound
+	Get(ctx context.Context, catalogId string, visitorId string) (*ClaimFingerprint, error)
+	// Put inserts the fingerprint (no error if it exists)
+	Put(ctx context.Context, fingerprint *ClaimFingerprint) error
+}
+
+// FunnelEventRepository stores funnel events ordered by creation time (event IDs start with the creation time)
+type FunnelEventRepository interface {
+	// Put inserts the event
+	Put(ctx context.Context, event *FunnelEvent) error
+	// List returns events of the catalog (all catalogs if empty) created within [from, to) newest first
+	List(ctx context.Context, catalogId string, from int64, to int64) ([]*FunnelEvent, error)
+	// Prune removes events created before the time (ms) and returns number of removed events
+	Prune(ctx context.Context, before int64) (int, error)
+}
+
+// UserRepository stores users by email
+type UserRepository interface {
+	// Get returns the user or ErrNotFound
+	Get(ctx context.Context, email string) (*User, error)
+	// Put inserts or replaces the user
+	Put(ctx context.Context, user *User) error
+}
@@ -0,0 +1,120 @@
+package pinning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// KuboPinner pins content with the Kubo RPC API (local Kubo node or Infura IPFS API)
+type KuboPinner struct {
+	name   string
+	client *resty.Client
+}
+
+func NewKuboPinner(name string, endpoint string) *KuboPinner {
+	return &KuboPinner{
+		name:   name,
+		client: newHttpClient(endpoint),
+	}
+}
+
+// NewInfuraPinner creates Kubo RPC pinner authenticated with Infura project key and secret
+func NewInfuraPinner(name string, endpoint string, key string, secret string) *KuboPinner {
+	return &KuboPinner{
+		name:   name,
+		client: newHttpClient(endpoint).SetBasicAuth(key, secret),
+	}
+}
+
+func (kp *KuboPinner) Name() string {
+	return kp.name
+}
+
+func (kp *KuboPinner) Add(ctx context.Context, filename string, data []byte, cidVersion int) (string, error) {
+	resp, err := kp.client.R().
+		SetContext(ctx).
+		SetFileReader("file", filename, bytes.NewReader(data)).
+		SetContentLength(true).
+		SetQueryParam("cid-version", strconv.Itoa(cidVersion)).
+		SetQueryParam("pin", "true").
+		Post("/api/v0/add")
+	if cErr := checkResponse(kp.name, "add", resp, err); cErr != nil {
+		return "", cErr
+	}
+	// response is a JSON object per line, the first one is the file (not the folder)
+	for _, line := range strings.Split(string(resp.Body()), "\n") {
+		if len(line) <= 3 {
+			continue
+		}
+		var added struct {
+			Name string `json:"Name"`
+			Hash string `json:"Hash"`
+		}
+		if err := json.Unmarshal([]byte(line), &added); err != nil {
+			return "", err
+		}
+		return added.Hash, nil
+	}
+	return "", errors.New(kp.name + ": empty add response")
+}
+
+func (kp *KuboPinner) Pin(ctx context.Context, cid string, name string) error {
+	resp, err := kp.client.R().
+		SetContext(ctx).
+		SetQueryParam("arg", cid).
+		Post("/api/v0/pin/add")
+	return checkResponse(kp.name, "pin", resp, err)
+}
+
+func (kp *KuboPinner) Unpin(ctx context.Context, cid string) error {
+	resp, err := kp.client.R().
+		SetContext(ctx).
+		SetQueryParam("arg", cid).
+		Post("/api/v0/pin/rm")
+	if err == nil && strings.Contains(string(resp.Body()), "not pinned") {
+		return nil
+	}
+	return checkResponse(kp.name, "unpin", resp, err)
+}
+
+func (kp *KuboPinner) IsPinned(ctx context.Context, cid string) (bool, error) {
+	resp, err := kp.client.R().
+		SetContext(ctx).
+		SetQueryParam("arg", cid).
+		SetQueryParam("type", "recursive").
+		Post("/api/v0/pin/ls")
+	if err == nil && strings.Contains(string(resp.Body()), "not pinned") {
+		return false, nil
+	}
+	if cErr := checkResponse(kp.name, "pin ls", resp, err); cErr != nil {
+		return false, cErr
+	}
+	return true, nil
+}
+
+func (kp *KuboPinner) List(ctx context.Context) ([]string, error) {
+	resp, err := kp.client.R().
+		SetContext(ctx).
+		SetQueryParam("type", "recursive").
+		Post("/api/v0/pin/ls")
+	if cErr := checkResponse(kp.name, "pin ls", resp, err); cErr != nil {
+		return nil, cErr
+	}
+	var pins struct {
+		Keys map[string]interface{} `json:"Keys"`
+	}
+	if err := json.Unmarshal(resp.Body(), &pins); err != nil {
+		return nil, err
+	}
+	cids := []string{}
+	for cid := range pins.Keys {
+		cids = append(cids, cid)
+	}
+	return cids, nil
+}
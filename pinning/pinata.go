@@ -0,0 +1,126 @@
+package pinning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const pinataDefaultEndpoint = "https://api.pinata.cloud"
+
+// max number of pins returned by a single pin list request
+const pinataPageLimit = 1000
+
+// PinataPinner pins content with the Pinata API (authenticated with JWT)
+type PinataPinner struct {
+	name   string
+	client *resty.Client
+}
+
+func NewPinataPinner(name string, endpoint string, jwt string) *PinataPinner {
+	if endpoint == "" {
+		endpoint = pinataDefaultEndpoint
+	}
+	return &PinataPinner{
+		name:   name,
+		client: newHttpClient(endpoint).SetAuthToken(jwt),
+	}
+}
+
+func (pp *PinataPinner) Name() string {
+	return pp.name
+}
+
+func (pp *PinataPinner) Add(ctx context.Context, filename string, data []byte, cidVersion int) (string, error) {
+	resp, err := pp.client.R().
+		SetContext(ctx).
+		SetFileReader("file", filename, bytes.NewReader(data)).
+		SetFormData(map[string]string{
+			"pinataOptions":  fmt.Sprintf(`{"cidVersion":%d}`, cidVersion),
+			"pinataMetadata": fmt.Sprintf(`{"name":%q}`, filename),
+		}).
+		Post("/pinning/pinFileToIPFS")
+	if cErr := checkResponse(pp.name, "add", resp, err); cErr != nil {
+		return "", cErr
+	}
+	var added struct {
+		IpfsHash string `json:"IpfsHash"`
+	}
+	if err := json.Unmarshal(resp.Body(), &added); err != nil {
+		return "", err
+	}
+	return added.IpfsHash, nil
+}
+
+func (pp *PinataPinner) Pin(ctx context.Context, cid string, name string) error {
+	resp, err := pp.client.R().
+		SetContext(ctx).
+		SetBody(map[string]interface{}{
+			"hashToPin": cid,
+			"pinataMetadata": map[string]string{
+				"name": name,
+			},
+		}).
+		Post("/pinning/pinByHash")
+	return checkResponse(pp.name, "pin", resp, err)
+}
+
+func (pp *PinataPinner) Unpin(ctx context.Context, cid string) error {
+	pinned, err := pp.IsPinned(ctx, cid)
+	if err != nil || !pinned {
+		return err
+	}
+	resp, err := pp.client.R().
+		SetContext(ctx).
+		Delete("/pinning/unpin/" + cid)
+	return checkResponse(pp.name, "unpin", resp, err)
+}
+
+func (pp *PinataPinner) IsPinned(ctx context.Context, cid string) (bool, error) {
+	pins, err := pp.pinList(ctx, map[string]string{
+		"hashContains": cid,
+		"status":       "pinned",
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, pin := range pins {
+		if pin == cid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (pp *PinataPinner) List(ctx context.Context) ([]string, error) {
+	return pp.pinList(ctx, map[string]string{
+		"status":    "pinned",
+		"pageLimit": fmt.Sprintf("%d", pinataPageLimit),
+	})
+}
+
+func (pp *PinataPinner) pinList(ctx context.Context, params map[string]string) ([]string, error) {
+	resp, err := pp.client.R().
+		SetContext(ctx).
+		SetQueryParams(params).
+		Get("/data/pinList")
+	if cErr := checkResponse(pp.name, "pin list", resp, err); cErr != nil {
+		return nil, cErr
+	}
+	var pinList struct {
+		Rows []struct {
+			IpfsPinHash string `json:"ipfs_pin_hash"`
+		} `json:"rows"`
+	}
+	if err := json.Unmarshal(resp.Body(), &pinList); err != nil {
+		return nil, err
+	}
+	cids := []string{}
+	for _, row := range pinList.Rows {
+		cids = append(cids, row.IpfsPinHash)
+	}
+	return cids, nil
+}
@@ -0,0 +1,79 @@
+package pinning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	lc "github.com/mailio/mailio-nft-server/config"
+)
+
+// pinning provider types
+const (
+	ProviderInfura         = "infura"
+	ProviderKubo           = "kubo"
+	ProviderPinata         = "pinata"
+	ProviderPinningService = "pinning_service"
+)
+
+// timeout of requests to pinning providers (uploads included)
+const requestTimeout = time.Minute
+
+var ErrUploadNotSupported = errors.New("pinning provider doesn't support uploads")
+
+// Pinner pins content to an IPFS pinning provider
+type Pinner interface {
+	// Name of the provider (recorded as pin location)
+	Name() string
+	// Add uploads and pins the content, returns its CID (0 = base58 CIDv0, 1 = base32 CIDv1)
+	Add(ctx context.Context, filename string, data []byte, cidVersion int) (string, error)
+	// Pin pins content already available on IPFS
+	Pin(ctx context.Context, cid string, name string) error
+	// Unpin removes the pin (no error if content is not pinned)
+	Unpin(ctx context.Context, cid string) error
+	// IsPinned checks if content is pinned
+	IsPinned(ctx context.Context, cid string) (bool, error)
+	// List lists CIDs of all pinned content
+	List(ctx context.Context) ([]string, error)
+}
+
+// New creates a pinner from the provider config
+func New(conf lc.PinningProviderSubConfig) (Pinner, error) {
+	name := conf.Name
+	if name == "" {
+		name = conf.Type
+	}
+	switch conf.Type {
+	case ProviderInfura:
+		return NewInfuraPinner(name, conf.Endpoint, conf.Key, conf.Secret), nil
+	case ProviderKubo:
+		return NewKuboPinner(name, conf.Endpoint), nil
+	case ProviderPinata:
+		return NewPinataPinner(name, conf.Endpoint, conf.Token), nil
+	case ProviderPinningService:
+		return NewPinningServicePinner(name, conf.Endpoint, conf.Token), nil
+	}
+	return nil, fmt.Errorf("unknown pinning provider type %s", conf.Type)
+}
+
+func newHttpClient(endpoint string) *resty.Client {
+	return resty.New().
+		SetHostURL(strings.TrimSuffix(endpoint, "/")).
+		SetTimeout(requestTimeout)
+}
+
+// checkResponse returns error if request failed or returned a non 2xx status
+func checkResponse(provider string, action string, resp *resty.Response, err error) error {
+	if err != nil {
+		lc.Log.Error("pinning provider request failed", provider, action, err)
+		return err
+	}
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		lc.Log.Error("pinning provider request failed", provider, action, resp.StatusCode(), string(resp.Body()))
+		return fmt.Errorf("%s: %s failed with status %d", provider, action, resp.StatusCode())
+	}
+	return nil
+}
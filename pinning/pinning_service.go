@@ -0,0 +1,116 @@
+package pinning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// max number of pins returned by a single pin list request
+const pinningServicePageLimit = 1000
+
+// PinningServicePinner pins content with the IPFS Pinning Services API (https://ipfs.github.io/pinning-services-api-spec/)
+// API doesn't support uploads, so it can only replicate content uploaded to another provider
+type PinningServicePinner struct {
+	name   string
+	client *resty.Client
+}
+
+func NewPinningServicePinner(name string, endpoint string, accessToken string) *PinningServicePinner {
+	return &PinningServicePinner{
+		name:   name,
+		client: newHttpClient(endpoint).SetAuthToken(accessToken),
+	}
+}
+
+type pinStatus struct {
+	RequestId string `json:"requestid"`
+	Status    string `json:"status"`
+	Pin       struct {
+		Cid string `json:"cid"`
+	} `json:"pin"`
+}
+
+func (ps *PinningServicePinner) Name() string {
+	return ps.name
+}
+
+func (ps *PinningServicePinner) Add(ctx context.Context, filename string, data []byte, cidVersion int) (string, error) {
+	return "", ErrUploadNotSupported
+}
+
+func (ps *PinningServicePinner) Pin(ctx context.Context, cid string, name string) error {
+	resp, err := ps.client.R().
+		SetContext(ctx).
+		SetBody(map[string]string{
+			"cid":  cid,
+			"name": name,
+		}).
+		Post("/pins")
+	return checkResponse(ps.name, "pin", resp, err)
+}
+
+func (ps *PinningServicePinner) Unpin(ctx context.Context, cid string) error {
+	pins, err := ps.pins(ctx, map[string]string{
+		"cid":    cid,
+		"status": "queued,pinning,pinned,failed",
+	})
+	if err != nil {
+		return err
+	}
+	for _, pin := range pins {
+		resp, err := ps.client.R().
+			SetContext(ctx).
+			Delete("/pins/" + pin.RequestId)
+		if cErr := checkResponse(ps.name, "unpin", resp, err); cErr != nil {
+			return cErr
+		}
+	}
+	return nil
+}
+
+// IsPinned returns true also for queued pins (pinning is asynchronous)
+func (ps *PinningServicePinner) IsPinned(ctx context.Context, cid string) (bool, error) {
+	pins, err := ps.pins(ctx, map[string]string{
+		"cid":    cid,
+		"status": "queued,pinning,pinned",
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(pins) > 0, nil
+}
+
+func (ps *PinningServicePinner) List(ctx context.Context) ([]string, error) {
+	pins, err := ps.pins(ctx, map[string]string{
+		"status": "pinned",
+		"limit":  fmt.Sprintf("%d", pinningServicePageLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	cids := []string{}
+	for _, pin := range pins {
+		cids = append(cids, pin.Pin.Cid)
+	}
+	return cids, nil
+}
+
+func (ps *PinningServicePinner) pins(ctx context.Context, params map[string]string) ([]*pinStatus, error) {
+	resp, err := ps.client.R().
+		SetContext(ctx).
+		SetQueryParams(params).
+		Get("/pins")
+	if cErr := checkResponse(ps.name, "pin list", resp, err); cErr != nil {
+		return nil, cErr
+	}
+	var results struct {
+		Results []*pinStatus `json:"results"`
+	}
+	if err := json.Unmarshal(resp.Body(), &results); err != nil {
+		return nil, err
+	}
+	return results.Results, nil
+}
@@ -0,0 +1,114 @@
+package pinning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	lc "github.com/mailio/mailio-nft-server/config"
+)
+
+// Replicator pins content to multiple providers: content is uploaded to the first provider
+// supporting uploads and replicated by CID to all other providers
+type Replicator struct {
+	pinners     []Pinner
+	minReplicas int
+}
+
+func NewReplicator(pinners []Pinner, minReplicas int) *Replicator {
+	if minReplicas <= 0 {
+		minReplicas = 1
+	}
+	return &Replicator{
+		pinners:     pinners,
+		minReplicas: minReplicas,
+	}
+}
+
+// Pinners returns all configured pinners
+func (r *Replicator) Pinners() []Pinner {
+	return r.pinners
+}
+
+// Pinner returns pinner by name or nil if not configured
+func (r *Replicator) Pinner(name string) Pinner {
+	for _, p := range r.pinners {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Add uploads the content and replicates it to all other providers
+// returns CID and names of providers content is pinned to. Error is returned (together with the
+// locations content is pinned to) if content was pinned to fewer than min replicas providers
+func (r *Replicator) Add(ctx context.Context, filename string, data []byte, cidVersion int) (string, []string, error) {
+	var (
+		cid      string
+		uploader string
+		err      error
+	)
+	for _, p := range r.pinners {
+		cid, err = p.Add(ctx, filename, data, cidVersion)
+		if err == ErrUploadNotSupported {
+			continue
+		}
+		if err != nil {
+			lc.Log.Error("failed to upload to pinning provider", p.Name(), err)
+			continue
+		}
+		uploader = p.Name()
+		break
+	}
+	if uploader == "" {
+		if err == nil || err == ErrUploadNotSupported {
+			err = errors.New("no pinning provider supporting uploads is configured")
+		}
+		return "", nil, err
+	}
+	locations, err := r.Replicate(ctx, cid, filename, []string{uploader})
+	return cid, locations, err
+}
+
+// Replicate pins content to all providers it's not pinned to yet (by CID)
+// returns all locations content is pinned to
+func (r *Replicator) Replicate(ctx context.Context, cid string, name string, locations []string) ([]string, error) {
+	pinnedTo := map[string]bool{}
+	for _, l := range locations {
+		pinnedTo[l] = true
+	}
+	for _, p := range r.pinners {
+		if pinnedTo[p.Name()] {
+			continue
+		}
+		if err := p.Pin(ctx, cid, name); err != nil {
+			lc.Log.Error("failed to replicate pin", p.Name(), cid, err)
+			continue
+		}
+		locations = append(locations, p.Name())
+	}
+	if len(locations) < r.minReplicas {
+		return locations, fmt.Errorf("content %s pinned to %d providers, %d required", cid, len(locations), r.minReplicas)
+	}
+	return locations, nil
+}
+
+// Unpin removes pins from the given locations and returns locations pins couldn't be removed from
+func (r *Replicator) Unpin(ctx context.Context, cid string, locations []string) ([]string, error) {
+	remaining := []string{}
+	var lastErr error
+	for _, location := range locations {
+		p := r.Pinner(location)
+		if p == nil {
+			lc.Log.Warn("pinning provider not configured anymore, pin not removed", location, cid)
+			remaining = append(remaining, location)
+			continue
+		}
+		if err := p.Unpin(ctx, cid); err != nil {
+			remaining = append(remaining, location)
+			lastErr = err
+		}
+	}
+	return remaining, lastErr
+}
@@ -0,0 +1,556 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	leveldb "github.com/ipfs/go-ds-leveldb"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mitchellh/mapstructure"
+)
+
+// NewLevelDB creates repositories stored in the leveldb datastore (closed by the environment, which also uses
+// it as the key-value DB). Claim indexes are rebuilt if they are missing or outdated
+func NewLevelDB(db *leveldb.Datastore) (*model.Repositories, error) {
+	claims := &levelDBClaims{db: db}
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancel()
+	if err := claims.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+	return &model.Repositories{
+		Catalogs:     &levelDBCatalogs{db: db},
+		Claims:       claims,
+		Editions:     &levelDBEditions{db: db},
+		Fingerprints: &levelDBFingerprints{db: db},
+		FunnelEvents: &levelDBFunnelEvents{db: db},
+		Users:        &levelDBUsers{db: db},
+		DB:           db,
+	}, nil
+}
+
+type levelDBCatalogs struct {
+	db *leveldb.Datastore
+}
+
+func (lr *levelDBCatalogs) Get(ctx context.Context, id string) (*model.Catalog, error) {
+	var catalog model.Catalog
+	if err := getDocument(ctx, lr.db, util.CreateKey(model.CatalogTable, id), &catalog); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+func (lr *levelDBCatalogs) Put(ctx context.Context, catalog *model.Catalog) error {
+	return putDocument(ctx, lr.db, util.CreateKey(model.CatalogTable, catalog.ID), catalog)
+}
+
+func (lr *levelDBCatalogs) List(ctx context.Context, limit int) ([]*model.Catalog, error) {
+	q := query.Query{
+		Limit:  limit,
+		Prefix: "/" + model.CatalogTable,
+		Orders: []query.Order{query.OrderByKeyDescending{}},
+	}
+	entries, err := queryEntries(ctx, lr.db, q)
+	if err != nil {
+		return nil, err
+	}
+	catalogs := []*model.Catalog{}
+	for _, entry := range entries {
+		var catalog model.Catalog
+		if err := decodeDocument(entry.Value, &catalog); err != nil {
+			return nil, err
+		}
+		catalogs = append(catalogs, &catalog)
+	}
+	return catalogs, nil
+}
+
+// version of the claim index layout, indexes of older (or missing) versions are rebuilt on start
+// (2: hex encoded key components)
+const claimIndexVersion = 2
+
+var claimIndexVersionKey = datastore.NewKey("/claim_index/version")
+
+// secondary indexes of claims, keys are /index[/value]/position and values are claim IDs
+var claimIndexTables = []string{
+	model.ClaimByCreatedTable,
+	model.ClaimByCatalogTable,
+	model.ClaimByWalletTable,
+	model.ClaimByVisitorTable,
+}
+
+type levelDBClaims struct {
+	db    *leveldb.Datastore
+	mutex sync.Mutex // serializes writes, so index entries of replaced claims are removed
+}
+
+// ensureIndexes rebuilds claim indexes if they were built with an older version (or never)
+func (lr *levelDBClaims) ensureIndexes(ctx context.Context) error {
+	value, err := lr.db.Get(ctx, claimIndexVersionKey)
+	if err != nil && err != datastore.ErrNotFound {
+		return err
+	}
+	if err == nil && string(value) == strconv.Itoa(claimIndexVersion) {
+		return nil
+	}
+	indexed, err := lr.RebuildIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	lc.Log.Info("rebuilt claim indexes", indexed)
+	return nil
+}
+
+func (lr *levelDBClaims) Get(ctx context.Context, catalogId string, walletAddress string) (*model.Claim, error) {
+	var claim model.Claim
+	if err := getDocument(ctx, lr.db, util.CreateKey(model.ClaimTable, claimID(catalogId, walletAddress)), &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// Put writes the claim and its index entries in a single batch (removing entries of the replaced claim)
+func (lr *levelDBClaims) Put(ctx context.Context, claim *model.Claim) error {
+	value, err := util.MarshalToBytes(claim)
+	if err != nil {
+		return err
+	}
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	batch, err := lr.db.Batch(ctx)
+	if err != nil {
+		return err
+	}
+	previous, err := lr.Get(ctx, claim.CatalogId, claim.WalletAddress)
+	if err != nil && err != model.ErrNotFound {
+		return err
+	}
+	if previous != nil {
+		for _, key := range claimIndexKeys(previous) {
+			if err := batch.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+	id := claimID(claim.CatalogId, claim.WalletAddress)
+	if err := batch.Put(ctx, util.CreateKey(model.ClaimTable, id), value); err != nil {
+		return err
+	}
+	for _, key := range claimIndexKeys(claim) {
+		if err := batch.Put(ctx, key, []byte(id)); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(ctx)
+}
+
+func (lr *levelDBClaims) List(ctx context.Context, limit int) ([]*model.Claim, error) {
+	claims := []*model.Claim{}
+	err := lr.scan(ctx, &model.ClaimFilter{}, "", true, func(position string, claim *model.Claim) bool {
+		claims = append(claims, claim)
+		return limit <= 0 || len(claims) < limit
+	})
+	return claims, err
+}
+
+// ListPage reads the claims from the index of the filter (cursor is the position of the last claim)
+func (lr *levelDBClaims) ListPage(ctx context.Context, filter *model.ClaimFilter, cursor string, limit int) ([]*model.Claim, string, error) {
+	after, err := decodeClaimCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	claims := []*model.Claim{}
+	next := ""
+	err = lr.scan(ctx, filter, after, true, func(position string, claim *model.Claim) bool {
+		if limit > 0 && len(claims) == limit {
+			// there is a next page
+			next = util.EncodeCursor(claimPosition(claims[limit-1]))
+			return false
+		}
+		claims = append(claims, claim)
+		return true
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return claims, next, nil
+}
+
+// Count counts index entries (claims are read only if the filter has conditions not covered by the index)
+func (lr *levelDBClaims) Count(ctx context.Context, filter *model.ClaimFilter) (int, error) {
+	_, covered := claimIndexPrefix(filter)
+	count := 0
+	err := lr.scan(ctx, filter, "", !covered, func(position string, claim *model.Claim) bool {
+		count++
+		return true
+	})
+	return count, err
+}
+
+func (lr *levelDBClaims) CountByCatalog(ctx context.Context) (map[string]int, error) {
+	q := query.Query{
+		Prefix:   "/" + model.ClaimTable,
+		KeysOnly: true,
+	}
+	entries, err := queryEntries(ctx, lr.db, q)
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]int{}
+	for _, entry := range entries {
+		// claim key: /claim/walletAddress_catalogId
+		if i := strings.LastIndex(entry.Key, "_"); i >= 0 {
+			claims[entry.Key[i+1:]]++
+		}
+	}
+	return claims, nil
+}
+
+// RebuildIndexes removes all index entries and indexes stored claims in a single batch
+func (lr *levelDBClaims) RebuildIndexes(ctx context.Context) (int, error) {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	batch, err := lr.db.Batch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, table := range claimIndexTables {
+		entries, err := queryEntries(ctx, lr.db, query.Query{Prefix: "/" + table, KeysOnly: true})
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range entries {
+			if err := batch.Delete(ctx, datastore.NewKey(entry.Key)); err != nil {
+				return 0, err
+			}
+		}
+	}
+	entries, err := queryEntries(ctx, lr.db, query.Query{Prefix: "/" + model.ClaimTable})
+	if err != nil {
+		return 0, err
+	}
+	claims, err := decodeClaims(entries)
+	if err != nil {
+		return 0, err
+	}
+	for _, claim := range claims {
+		id := []byte(claimID(claim.CatalogId, claim.WalletAddress))
+		for _, key := range claimIndexKeys(claim) {
+			if err := batch.Put(ctx, key, id); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := batch.Put(ctx, claimIndexVersionKey, []byte(strconv.Itoa(claimIndexVersion))); err != nil {
+		return 0, err
+	}
+	return len(claims), batch.Commit(ctx)
+}
+
+// scan calls fn with claims selected by the filter newest first (starting after the position if not empty)
+// until fn returns false. Claims are nil unless load is set (the filter is then checked on every claim)
+func (lr *levelDBClaims) scan(ctx context.Context, filter *model.ClaimFilter, after string, load bool, fn func(position string, claim *model.Claim) bool) error {
+	prefix, _ := claimIndexPrefix(filter)
+	before := after
+	if filter.Until > 0 {
+		if until := timePosition(filter.Until); before == "" || until < before {
+			before = until
+		}
+	}
+	since := ""
+	if filter.Since > 0 {
+		since = timePosition(filter.Since)
+	}
+	q := query.Query{
+		Prefix:   prefix,
+		KeysOnly: !load,
+		Orders:   []query.Order{query.OrderByKeyDescending{}},
+	}
+	if before != "" {
+		q.Filters = []query.Filter{query.FilterKeyCompare{Op: query.LessThan, Key: prefix + "/" + before}}
+	}
+	qRes, err := lr.db.Query(ctx, q)
+	if err != nil {
+		return err
+	}
+	defer qRes.Close()
+	for r := range qRes.Next() {
+		if r.Error != nil {
+			return r.Error
+		}
+		position := strings.TrimPrefix(r.Key, prefix+"/")
+		if position < since {
+			// older claims follow
+			break
+		}
+		var claim *model.Claim
+		if load {
+			var err error
+			claim, err = lr.indexedClaim(ctx, string(r.Value))
+			if err == model.ErrNotFound || (err == nil && !filter.Matches(claim)) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if !fn(position, claim) {
+			break
+		}
+	}
+	return nil
+}
+
+func (lr *levelDBClaims) indexedClaim(ctx context.Context, id string) (*model.Claim, error) {
+	var claim model.Claim
+	if err := getDocument(ctx, lr.db, util.CreateKey(model.ClaimTable, id), &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// claimIndexPrefix picks the most selective index of the filter and reports if the index covers all
+// conditions (other than creation time) of the filter
+func claimIndexPrefix(filter *model.ClaimFilter) (string, bool) {
+	conditions := 0
+	for _, value := range []string{filter.CatalogId, filter.WalletAddress, filter.VisitorId} {
+		if value != "" {
+			conditions++
+		}
+	}
+	covered := conditions <= 1
+	switch {
+	case filter.WalletAddress != "":
+		return "/" + model.ClaimByWalletTable + "/" + keyComponent(filter.WalletAddress), covered
+	case filter.VisitorId != "":
+		return "/" + model.ClaimByVisitorTable + "/" + keyComponent(filter.VisitorId), covered
+	case filter.CatalogId != "":
+		return "/" + model.ClaimByCatalogTable + "/" + keyComponent(filter.CatalogId), covered
+	}
+	return "/" + model.ClaimByCreatedTable, covered
+}
+
+// claimIndexKeys are the index entries of the claim
+func claimIndexKeys(claim *model.Claim) []datastore.Key {
+	position := claimPosition(claim)
+	keys := []datastore.Key{
+		util.CreateKey(model.ClaimByCreatedTable, position),
+		util.CreateKey(model.ClaimByCatalogTable, keyComponent(claim.CatalogId)+"/"+position),
+		util.CreateKey(model.ClaimByWalletTable, keyComponent(claim.WalletAddress)+"/"+position),
+	}
+	if claim.VisitorId != "" {
+		keys = append(keys, util.CreateKey(model.ClaimByVisitorTable, keyComponent(claim.VisitorId)+"/"+position))
+	}
+	return keys
+}
+
+func decodeClaims(entries []query.Entry) ([]*model.Claim, error) {
+	claims := []*model.Claim{}
+	for _, entry := range entries {
+		var claim model.Claim
+		if err := decodeDocument(entry.Value, &claim); err != nil {
+			return nil, err
+		}
+		claims = append(claims, &claim)
+	}
+	return claims, nil
+}
+
+type levelDBEditions struct {
+	db    *leveldb.Datastore
+	mutex sync.Mutex // serializes assignment (a single process opens the datastore)
+}
+
+func (lr *levelDBEditions) Next(ctx context.Context, catalogId string, minted int, maxEditions int) (int, error) {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+	last, err := lr.Last(ctx, catalogId)
+	if err != nil {
+		return 0, err
+	}
+	edition, err := nextEdition(last, minted, maxEditions)
+	if err != nil {
+		return 0, err
+	}
+	return edition, lr.put(ctx, catalogId, edition)
+}
+
+func (lr *levelDBEditions) Release(ctx context.Context, catalogId string, edition int) error {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+	last, err := lr.Last(ctx, catalogId)
+	if err != nil || last != edition {
+		return err
+	}
+	return lr.put(ctx, catalogId, edition-1)
+}
+
+func (lr *levelDBEditions) Last(ctx context.Context, catalogId string) (int, error) {
+	var edition model.ClaimEdition
+	err := getDocument(ctx, lr.db, util.CreateKey(model.ClaimEditionTable, catalogId), &edition)
+	if err == model.ErrNotFound {
+		return 0, nil
+	}
+	return edition.LastEdition, err
+}
+
+func (lr *levelDBEditions) put(ctx context.Context, catalogId string, lastEdition int) error {
+	return putDocument(ctx, lr.db, util.CreateKey(model.ClaimEditionTable, catalogId), &model.ClaimEdition{
+		CatalogId:   catalogId,
+		LastEdition: lastEdition,
+		Modified:    time.Now().UnixMilli(),
+	})
+}
+
+type levelDBFingerprints struct {
+	db *leveldb.Datastore
+}
+
+func (lr *levelDBFingerprints) Get(ctx context.Context, catalogId string, visitorId string) (*model.ClaimFingerprint, error) {
+	var fingerprint model.ClaimFingerprint
+	if err := getDocument(ctx, lr.db, util.CreateKey(model.ClaimFingerprintTable, fingerprintID(catalogId, visitorId)), &fingerprint); err != nil {
+		return nil, err
+	}
+	return &fingerprint, nil
+}
+
+func (lr *levelDBFingerprints) Put(ctx context.Context, fingerprint *model.ClaimFingerprint) error {
+	return putDocument(ctx, lr.db, util.CreateKey(model.ClaimFingerprintTable, fingerprintID(fingerprint.CatalogId, fingerprint.VisitorId)), fingerprint)
+}
+
+// levelDBFunnelEvents are stored by ID, keys are ordered by creation time
+type levelDBFunnelEvents struct {
+	db *leveldb.Datastore
+}
+
+func (lr *levelDBFunnelEvents) Put(ctx context.Context, event *model.FunnelEvent) error {
+	return putDocument(ctx, lr.db, util.CreateKey(model.FunnelEventTable, event.ID), event)
+}
+
+// List scans events newest first, so events after the period are skipped and scanning stops at the start of the period
+func (lr *levelDBFunnelEvents) List(ctx context.Context, catalogId string, from int64, to int64) ([]*model.FunnelEvent, error) {
+	q := query.Query{
+		Prefix: "/" + model.FunnelEventTable,
+		Orders: []query.Order{query.OrderByKeyDescending{}},
+		Filters: []query.Filter{
+			query.FilterKeyCompare{Op: query.LessThan, Key: util.CreateKey(model.FunnelEventTable, timePosition(to)).String()},
+		},
+	}
+	qRes, err := lr.db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer qRes.Close()
+	events := []*model.FunnelEvent{}
+	for r := range qRes.Next() {
+		if r.Error != nil {
+			return nil, r.Error
+		}
+		var event model.FunnelEvent
+		if err := decodeDocument(r.Value, &event); err != nil {
+			return nil, err
+		}
+		if event.Created < from {
+			break
+		}
+		if catalogId == "" || event.CatalogId == catalogId {
+			events = append(events, &event)
+		}
+	}
+	return events, nil
+}
+
+// Prune scans keys oldest first and removes them in a single batch
+func (lr *levelDBFunnelEvents) Prune(ctx context.Context, before int64) (int, error) {
+	qRes, err := lr.db.Query(ctx, query.Query{
+		Prefix:   "/" + model.FunnelEventTable,
+		KeysOnly: true,
+		Orders:   []query.Order{query.OrderByKey{}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer qRes.Close()
+	batch, err := lr.db.Batch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	end := util.CreateKey(model.FunnelEventTable, timePosition(before)).String()
+	pruned := 0
+	for r := range qRes.Next() {
+		if r.Error != nil {
+			return 0, r.Error
+		}
+		if r.Key >= end {
+			break
+		}
+		if err := batch.Delete(ctx, datastore.NewKey(r.Key)); err != nil {
+			return 0, err
+		}
+		pruned++
+	}
+	return pruned, batch.Commit(ctx)
+}
+
+type levelDBUsers struct {
+	db *leveldb.Datastore
+}
+
+func (lr *levelDBUsers) Get(ctx context.Context, email string) (*model.User, error) {
+	var user model.User
+	if err := getDocument(ctx, lr.db, util.CreateKey(model.UserTable, email), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (lr *levelDBUsers) Put(ctx context.Context, user *model.User) error {
+	return putDocument(ctx, lr.db, util.CreateKey(model.UserTable, user.Email), user)
+}
+
+// getDocument decodes JSON document stored under the key. Returns model.ErrNotFound if key doesn't exist
+func getDocument(ctx context.Context, db *leveldb.Datastore, key datastore.Key, out interface{}) error {
+	value, err := db.Get(ctx, key)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrNotFound
+		}
+		return err
+	}
+	return decodeDocument(value, out)
+}
+
+func putDocument(ctx context.Context, db *leveldb.Datastore, key datastore.Key, document interface{}) error {
+	value, err := util.MarshalToBytes(document)
+	if err != nil {
+		return err
+	}
+	return db.Put(ctx, key, value)
+}
+
+func decodeDocument(value []byte, out interface{}) error {
+	document, err := util.UnmarshalFromBytes(value)
+	if err != nil {
+		return err
+	}
+	return mapstructure.Decode(document, out)
+}
+
+func queryEntries(ctx context.Context, db *leveldb.Datastore, q query.Query) ([]query.Entry, error) {
+	qRes, err := db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer qRes.Close()
+	return qRes.Rest()
+}
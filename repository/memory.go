@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+)
+
+// NewMemory creates repositories kept in memory (lost on restart, e.g. for tests and local development)
+func NewMemory() *model.Repositories {
+	return &model.Repositories{
+		Catalogs:     &memoryCatalogs{table: newMemoryTable()},
+		Claims:       &memoryClaims{table: newMemoryTable()},
+		Editions:     &memoryEditions{editions: map[string]int{}},
+		Fingerprints: &memoryFingerprints{table: newMemoryTable()},
+		FunnelEvents: &memoryFunnelEvents{table: newMemoryTable()},
+		Users:        &memoryUsers{table: newMemoryTable()},
+		DB:           dssync.MutexWrap(datastore.NewMapDatastore()),
+	}
+}
+
+// memoryTable keeps JSON encoded documents by ID, so stored documents are never shared with callers
+type memoryTable struct {
+	mutex     sync.RWMutex
+	documents map[string][]byte
+}
+
+func newMemoryTable() *memoryTable {
+	return &memoryTable{
+		documents: map[string][]byte{},
+	}
+}
+
+// get decodes the document. Returns model.ErrNotFound if document doesn't exist
+func (mt *memoryTable) get(id string, out interface{}) error {
+	mt.mutex.RLock()
+	document, ok := mt.documents[id]
+	mt.mutex.RUnlock()
+	if !ok {
+		return model.ErrNotFound
+	}
+	return json.Unmarshal(document, out)
+}
+
+func (mt *memoryTable) put(id string, document interface{}) error {
+	b, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	mt.mutex.Lock()
+	defer mt.mutex.Unlock()
+	mt.documents[id] = b
+	return nil
+}
+
+func (mt *memoryTable) delete(id string) {
+	mt.mutex.Lock()
+	defer mt.mutex.Unlock()
+	delete(mt.documents, id)
+}
+
+// ids returns all IDs in descending order
+func (mt *memoryTable) ids() []string {
+	mt.mutex.RLock()
+	defer mt.mutex.RUnlock()
+	ids := []string{}
+	for id := range mt.documents {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids
+}
+
+type memoryCatalogs struct {
+	table *memoryTable
+}
+
+func (mr *memoryCatalogs) Get(ctx context.Context, id string) (*model.Catalog, error) {
+	var catalog model.Catalog
+	if err := mr.table.get(id, &catalog); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+func (mr *memoryCatalogs) Put(ctx context.Context, catalog *model.Catalog) error {
+	return mr.table.put(catalog.ID, catalog)
+}
+
+func (mr *memoryCatalogs) List(ctx context.Context, limit int) ([]*model.Catalog, error) {
+	catalogs := []*model.Catalog{}
+	for _, id := range limitIDs(mr.table.ids(), limit) {
+		var catalog model.Catalog
+		if err := mr.table.get(id, &catalog); err != nil {
+			// removed meanwhile
+			continue
+		}
+		catalogs = append(catalogs, &catalog)
+	}
+	return catalogs, nil
+}
+
+type memoryClaims struct {
+	table *memoryTable
+}
+
+func (mr *memoryClaims) Get(ctx context.Context, catalogId string, walletAddress string) (*model.Claim, error) {
+	var claim model.Claim
+	if err := mr.table.get(claimID(catalogId, walletAddress), &claim); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+func (mr *memoryClaims) Put(ctx context.Context, claim *model.Claim) error {
+	return mr.table.put(claimID(claim.CatalogId, claim.WalletAddress), claim)
+}
+
+func (mr *memoryClaims) List(ctx context.Context, limit int) ([]*model.Claim, error) {
+	claims := mr.sorted(&model.ClaimFilter{})
+	if limit > 0 && len(claims) > limit {
+		claims = claims[:limit]
+	}
+	return claims, nil
+}
+
+func (mr *memoryClaims) ListPage(ctx context.Context, filter *model.ClaimFilter, cursor string, limit int) ([]*model.Claim, string, error) {
+	after, err := decodeClaimCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	claims := []*model.Claim{}
+	for _, claim := range mr.sorted(filter) {
+		if after == "" || claimPosition(claim) < after {
+			claims = append(claims, claim)
+		}
+	}
+	next := ""
+	if limit > 0 && len(claims) > limit {
+		claims = claims[:limit]
+		next = util.EncodeCursor(claimPosition(claims[limit-1]))
+	}
+	return claims, next, nil
+}
+
+func (mr *memoryClaims) Count(ctx context.Context, filter *model.ClaimFilter) (int, error) {
+	return len(mr.sorted(filter)), nil
+}
+
+func (mr *memoryClaims) CountByCatalog(ctx context.Context) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, claim := range mr.claims(mr.table.ids()) {
+		counts[claim.CatalogId]++
+	}
+	return counts, nil
+}
+
+// RebuildIndexes has nothing to rebuild, claims are filtered and sorted on every listing
+func (mr *memoryClaims) RebuildIndexes(ctx context.Context) (int, error) {
+	return len(mr.table.ids()), nil
+}
+
+// sorted returns claims selected by the filter newest first
+func (mr *memoryClaims) sorted(filter *model.ClaimFilter) []*model.Claim {
+	claims := []*model.Claim{}
+	for _, claim := range mr.claims(mr.table.ids()) {
+		if filter.Matches(claim) {
+			claims = append(claims, claim)
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		return claimPosition(claims[i]) > claimPosition(claims[j])
+	})
+	return claims
+}
+
+func (mr *memoryClaims) claims(ids []string) []*model.Claim {
+	claims := []*model.Claim{}
+	for _, id := range ids {
+		var claim model.Claim
+		if err := mr.table.get(id, &claim); err == nil {
+			claims = append(claims, &claim)
+		}
+	}
+	return claims
+}
+
+type memoryEditions struct {
+	mutex    sync.Mutex
+	editions map[string]int // last assigned edition by catalog ID
+}
+
+func (mr *memoryEditions) Next(ctx context.Context, catalogId string, minted int, maxEditions int) (int, error) {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	edition, err := nextEdition(mr.editions[catalogId], minted, maxEditions)
+	if err != nil {
+		return 0, err
+	}
+	mr.editions[catalogId] = edition
+	return edition, nil
+}
+
+func (mr *memoryEditions) Release(ctx context.Context, catalogId string, edition int) error {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	if mr.editions[catalogId] == edition {
+		mr.editions[catalogId] = edition - 1
+	}
+	return nil
+}
+
+func (mr *memoryEditions) Last(ctx context.Context, catalogId string) (int, error) {
+	mr.mutex.Lock()
+	defer mr.mutex.Unlock()
+	return mr.editions[catalogId], nil
+}
+
+type memoryFingerprints struct {
+	table *memoryTable
+}
+
+func (mr *memoryFingerprints) Get(ctx context.Context, catalogId string, visitorId string) (*model.ClaimFingerprint, error) {
+	var fingerprint model.ClaimFingerprint
+	if err := mr.table.get(fingerprintID(catalogId, visitorId), &fingerprint); err != nil {
+		return nil, err
+	}
+	return &fingerprint, nil
+}
+
+func (mr *memoryFingerprints) Put(ctx context.Context, fingerprint *model.ClaimFingerprint) error {
+	return mr.table.put(fingerprintID(fingerprint.CatalogId, fingerprint.VisitorId), fingerprint)
+}
+
+// memoryFunnelEvents are kept by ID (IDs are ordered by creation time)
+type memoryFunnelEvents struct {
+	table *memoryTable
+}
+
+func (mr *memoryFunnelEvents) Put(ctx context.Context, event *model.FunnelEvent) error {
+	return mr.table.put(event.ID, event)
+}
+
+func (mr *memoryFunnelEvents) List(ctx context.Context, catalogId string, from int64, to int64) ([]*model.FunnelEvent, error) {
+	events := []*model.FunnelEvent{}
+	for _, id := range mr.table.ids() {
+		var event model.FunnelEvent
+		if err := mr.table.get(id, &event); err != nil {
+			// removed meanwhile
+			continue
+		}
+		if event.Created >= from && event.Created < to && (catalogId == "" || event.CatalogId == catalogId) {
+			events = append(events, &event)
+		}
+	}
+	return events, nil
+}
+
+func (mr *memoryFunnelEvents) Prune(ctx context.Context, before int64) (int, error) {
+	pruned := 0
+	for _, id := range mr.table.ids() {
+		var event model.FunnelEvent
+		if err := mr.table.get(id, &event); err == nil && event.Created < before {
+			mr.table.delete(id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+type memoryUsers struct {
+	table *memoryTable
+}
+
+func (mr *memoryUsers) Get(ctx context.Context, email string) (*model.User, error) {
+	var user model.User
+	if err := mr.table.get(email, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (mr *memoryUsers) Put(ctx context.Context, user *model.User) error {
+	return mr.table.put(user.Email, user)
+}
+
+func limitIDs(ids []string, limit int) []string {
+	if limit > 0 && len(ids) > limit {
+		return ids[:limit]
+	}
+	return ids
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+)
+
+// timeout of schema migrations (and of rebuilding leveldb claim indexes)
+const migrationTimeout = time.Minute
+
+// sqlMigration is a schema version. Statements are valid in SQLite and Postgres
+type sqlMigration struct {
+	statements []string
+	// backfill fills new columns of existing rows (optional)
+	backfill func(ctx context.Context, tx *sql.Tx, dialect string) error
+}
+
+// sqlMigrations are schema versions of SQL backends. Applied versions are recorded in schema_migrations.
+// Never change released migrations, append new ones
+var sqlMigrations = []sqlMigration{
+	// 1: catalogs, claims, fingerprints and users stored as JSON documents with indexed columns
+	{statements: []string{
+		`CREATE TABLE catalogs (
+			id VARCHAR(64) PRIMARY KEY,
+			external_ref VARCHAR(255),
+			status VARCHAR(32),
+			created BIGINT NOT NULL,
+			modified BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX catalogs_external_ref ON catalogs (external_ref)`,
+		`CREATE TABLE claims (
+			id VARCHAR(255) PRIMARY KEY,
+			catalog_id VARCHAR(64) NOT NULL,
+			wallet_address VARCHAR(255) NOT NULL,
+			created BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX claims_wallet_address ON claims (wallet_address, id)`,
+		`CREATE INDEX claims_catalog_id ON claims (catalog_id)`,
+		`CREATE TABLE fingerprints (
+			catalog_id VARCHAR(64) NOT NULL,
+			visitor_id VARCHAR(255) NOT NULL,
+			PRIMARY KEY (catalog_id, visitor_id)
+		)`,
+		`CREATE TABLE users (
+			email VARCHAR(255) PRIMARY KEY,
+			id VARCHAR(64) NOT NULL,
+			data TEXT NOT NULL
+		)`,
+	}},
+	// 2: claims listed newest first by catalog, wallet, visitor or creation time
+	{statements: []string{
+		`ALTER TABLE claims ADD COLUMN visitor_id VARCHAR(255) NOT NULL DEFAULT ''`,
+		`DROP INDEX claims_wallet_address`,
+		`DROP INDEX claims_catalog_id`,
+		`CREATE INDEX claims_created ON claims (created, id)`,
+		`CREATE INDEX claims_catalog_created ON claims (catalog_id, created, id)`,
+		`CREATE INDEX claims_wallet_created ON claims (wallet_address, created, id)`,
+		`CREATE INDEX claims_visitor_created ON claims (visitor_id, created, id)`,
+	}, backfill: backfillClaimVisitors},
+	// 3: edition counters and the key-value table of the other services (binary keys compare byte by byte)
+	{statements: []string{
+		`CREATE TABLE claim_editions (
+			catalog_id VARCHAR(64) PRIMARY KEY,
+			last_edition INTEGER NOT NULL,
+			modified BIGINT NOT NULL
+		)`,
+		`CREATE TABLE key_values (
+			id BYTEA PRIMARY KEY,
+			value BYTEA NOT NULL
+		)`,
+	}},
+	// 4: funnel events (pruned by creation time) moved from the key-value table
+	{statements: []string{
+		`CREATE TABLE funnel_events (
+			id VARCHAR(64) PRIMARY KEY,
+			catalog_id VARCHAR(255) NOT NULL,
+			created BIGINT NOT NULL,
+			data TEXT NOT NULL
+		)`,
+		`CREATE INDEX funnel_events_created ON funnel_events (created)`,
+	}, backfill: backfillFunnelEvents},
+}
+
+// migrate applies missing schema versions (each version in its own transaction)
+func migrate(db *sql.DB, dialect string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, applied BIGINT NOT NULL)`); err != nil {
+		return err
+	}
+	var current int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	for i := current; i < len(sqlMigrations); i++ {
+		version := i + 1
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, statement := range sqlMigrations[i].statements {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("schema migration %d failed: %w", version, err)
+			}
+		}
+		if backfill := sqlMigrations[i].backfill; backfill != nil {
+			if err := backfill(ctx, tx, dialect); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("schema migration %d backfill failed: %w", version, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, rebind(dialect, `INSERT INTO schema_migrations (version, applied) VALUES (?, ?)`), version, time.Now().UnixMilli()); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		lc.Log.Info("applied schema migration", version)
+	}
+	return nil
+}
+
+// backfillClaimVisitors copies visitor IDs of existing claims from their documents to the indexed column
+func backfillClaimVisitors(ctx context.Context, tx *sql.Tx, dialect string) error {
+	rows, err := tx.QueryContext(ctx, `SELECT data FROM claims`)
+	if err != nil {
+		return err
+	}
+	claims := []*model.Claim{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return err
+		}
+		var claim model.Claim
+		if err := json.Unmarshal([]byte(data), &claim); err != nil {
+			rows.Close()
+			return err
+		}
+		claims = append(claims, &claim)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, claim := range claims {
+		if _, err := tx.ExecContext(ctx, rebind(dialect, `UPDATE claims SET visitor_id = ? WHERE id = ?`),
+			claim.VisitorId, claimID(claim.CatalogId, claim.WalletAddress)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillFunnelEvents moves funnel events stored in the key-value table to the funnel_events table
+func backfillFunnelEvents(ctx context.Context, tx *sql.Tx, dialect string) error {
+	prefix := "/" + model.FunnelEventTable
+	rows, err := tx.QueryContext(ctx, rebind(dialect, `SELECT value FROM key_values WHERE id >= ? AND id < ?`), []byte(prefix+"/"), []byte(prefix+"0"))
+	if err != nil {
+		return err
+	}
+	events := []*model.FunnelEvent{}
+	for rows.Next() {
+		var value []byte
+		if err := rows.Scan(&value); err != nil {
+			rows.Close()
+			return err
+		}
+		var event model.FunnelEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			rows.Close()
+			return err
+		}
+		events = append(events, &event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, rebind(dialect, `INSERT INTO funnel_events (id, catalog_id, created, data) VALUES (?, ?, ?, ?)`),
+			event.ID, event.CatalogId, event.Created, string(data)); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, rebind(dialect, `DELETE FROM key_values WHERE id >= ? AND id < ?`), []byte(prefix+"/"), []byte(prefix+"0"))
+	return err
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	leveldb "github.com/ipfs/go-ds-leveldb"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+)
+
+// storage backends
+const (
+	BackendLevelDB  = "leveldb"
+	BackendMemory   = "memory"
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+)
+
+// New creates repositories of the configured storage backend. leveldb repositories use the datastore
+// (other backends don't need it). SQL schema is migrated to the latest version
+func New(conf lc.StorageSubConfig, db *leveldb.Datastore) (*model.Repositories, error) {
+	switch conf.Backend {
+	case "", BackendLevelDB:
+		if db == nil {
+			return nil, fmt.Errorf("leveldb storage requires a datastore")
+		}
+		return NewLevelDB(db)
+	case BackendMemory:
+		return NewMemory(), nil
+	case BackendSQLite, BackendPostgres:
+		return NewSQL(conf.Backend, conf.Dsn)
+	}
+	return nil, fmt.Errorf("unknown storage backend %s", conf.Backend)
+}
+
+// claimID is the unique ID of the claim (claims are ordered by wallet, then catalog)
+func claimID(catalogId string, walletAddress string) string {
+	return walletAddress + "_" + catalogId
+}
+
+// nextEdition returns the edition following the last assigned edition and minted tokens or ErrSoldOut
+func nextEdition(last int, minted int, maxEditions int) (int, error) {
+	edition := last
+	if minted > edition {
+		edition = minted
+	}
+	edition++
+	if edition > maxEditions {
+		return 0, model.ErrSoldOut
+	}
+	return edition, nil
+}
+
+// claimPosition orders claims by creation time, then ID (zero padded time and hex encoded ID, so positions
+// compare as strings and are safe within datastore keys). Claim list cursors encode the position of the last claim
+func claimPosition(claim *model.Claim) string {
+	return timePosition(claim.Created) + "_" + keyComponent(claimID(claim.CatalogId, claim.WalletAddress))
+}
+
+// keyComponent hex encodes a client provided value (wallet, catalog or visitor ID), so it can't add
+// or escape segments of a datastore key. Encoding preserves the order of values
+func keyComponent(value string) string {
+	return hex.EncodeToString([]byte(value))
+}
+
+// timePosition is the position prefix of claims created at the time (ms)
+func timePosition(created int64) string {
+	return fmt.Sprintf("%013d", created)
+}
+
+// parseClaimPosition returns creation time and claim ID of the position
+func parseClaimPosition(position string) (int64, string, error) {
+	i := strings.Index(position, "_")
+	if i < 0 {
+		return 0, "", model.ErrInvalidCursor
+	}
+	created, err := strconv.ParseInt(position[:i], 10, 64)
+	if err != nil {
+		return 0, "", model.ErrInvalidCursor
+	}
+	id, err := hex.DecodeString(position[i+1:])
+	if err != nil {
+		return 0, "", model.ErrInvalidCursor
+	}
+	return created, string(id), nil
+}
+
+// decodeClaimCursor returns the claim position encoded in the cursor (empty if there is no cursor)
+func decodeClaimCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	position, err := util.DecodeCursor(cursor)
+	if err != nil {
+		return "", model.ErrInvalidCursor
+	}
+	if _, _, err := parseClaimPosition(position); err != nil {
+		return "", err
+	}
+	return position, nil
+}
+
+// fingerprintID is the unique ID of the fingerprint
+func fingerprintID(catalogId string, visitorId string) string {
+	return catalogId + "_" + visitorId
+}
@@ -0,0 +1,368 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	leveldb "github.com/ipfs/go-ds-leveldb"
+	"github.com/mailio/mailio-nft-server/model"
+)
+
+// backends runs the test against repositories of every backend available without external services
+func backends(t *testing.T, test func(t *testing.T, repositories *model.Repositories)) {
+	open := map[string]func(t *testing.T) *model.Repositories{
+		BackendMemory: func(t *testing.T) *model.Repositories {
+			return NewMemory()
+		},
+		BackendLevelDB: func(t *testing.T) *model.Repositories {
+			db, err := leveldb.NewDatastore(filepath.Join(t.TempDir(), "leveldb"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { db.Close() })
+			repositories, err := NewLevelDB(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return repositories
+		},
+		BackendSQLite: func(t *testing.T) *model.Repositories {
+			repositories, err := NewSQL(BackendSQLite, filepath.Join(t.TempDir(), "nft.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { repositories.Closer.Close() })
+			return repositories
+		},
+	}
+	for _, backend := range []string{BackendMemory, BackendLevelDB, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			test(t, open[backend](t))
+		})
+	}
+}
+
+func testClaims() []*model.Claim {
+	claims := []*model.Claim{}
+	for i := 0; i < 12; i++ {
+		claims = append(claims, &model.Claim{
+			CatalogId:     fmt.Sprintf("catalog%d", i%3),
+			WalletAddress: fmt.Sprintf("0x%02d", i%5),
+			VisitorId:     fmt.Sprintf("visitor%d", i%2),
+			Edition:       i + 1,
+			Created:       int64(1000 + i/2), // pairs of claims created at the same time
+		})
+	}
+	// client provided values must not escape index keys
+	claims = append(claims, &model.Claim{CatalogId: "catalog0", WalletAddress: "0x99", VisitorId: "visitor0/../../claim/x", Created: 1003})
+	return claims
+}
+
+func TestClaimRepository(t *testing.T) {
+	backends(t, func(t *testing.T, repositories *model.Repositories) {
+		ctx := context.Background()
+		claims := testClaims()
+		for _, claim := range claims {
+			if err := repositories.Claims.Put(ctx, claim); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// replaced claim moves to other index entries
+		replaced := *claims[0]
+		replaced.VisitorId = "visitor9"
+		replaced.Created = 2000
+		if err := repositories.Claims.Put(ctx, &replaced); err != nil {
+			t.Fatal(err)
+		}
+		claims[0] = &replaced
+
+		got, err := repositories.Claims.Get(ctx, replaced.CatalogId, replaced.WalletAddress)
+		if err != nil || got.VisitorId != "visitor9" || got.Created != 2000 {
+			t.Fatalf("get returned %+v, %v", got, err)
+		}
+		if _, err := repositories.Claims.Get(ctx, "catalog0", "0xmissing"); err != model.ErrNotFound {
+			t.Fatalf("get of missing claim returned %v", err)
+		}
+
+		all, err := repositories.Claims.List(ctx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != len(claims) {
+			t.Fatalf("listed %d claims, expected %d", len(all), len(claims))
+		}
+		for i := 1; i < len(all); i++ {
+			if claimPosition(all[i-1]) <= claimPosition(all[i]) {
+				t.Fatalf("claims not listed newest first at %d", i)
+			}
+		}
+		if limited, _ := repositories.Claims.List(ctx, 3); !reflect.DeepEqual(limited, all[:3]) {
+			t.Fatalf("limited list differs from the first claims")
+		}
+
+		for _, filter := range []*model.ClaimFilter{
+			{},
+			{CatalogId: "catalog1"},
+			{WalletAddress: "0x02"},
+			{VisitorId: "visitor0"},
+			{VisitorId: "visitor0/../../claim/x"},
+			{VisitorId: "visitor9"},
+			{Since: 1002, Until: 1005},
+			{CatalogId: "catalog0", Since: 1003},
+			{CatalogId: "missing"},
+		} {
+			expected := []*model.Claim{}
+			for _, claim := range all {
+				if filter.Matches(claim) {
+					expected = append(expected, claim)
+				}
+			}
+			count, err := repositories.Claims.Count(ctx, filter)
+			if err != nil || count != len(expected) {
+				t.Fatalf("count of %+v returned %d, %v, expected %d", filter, count, err, len(expected))
+			}
+			// pages of 2 claims (cursors within claims created at the same time)
+			paged := []*model.Claim{}
+			cursor := ""
+			for {
+				page, next, err := repositories.Claims.ListPage(ctx, filter, cursor, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				paged = append(paged, page...)
+				if next == "" {
+					break
+				}
+				if len(page) != 2 {
+					t.Fatalf("page of %+v with next cursor has %d claims", filter, len(page))
+				}
+				cursor = next
+			}
+			if !reflect.DeepEqual(paged, expected) {
+				t.Fatalf("pages of %+v differ from the filtered list (%d, expected %d claims)", filter, len(paged), len(expected))
+			}
+			// no limit returns all claims without a next page
+			for _, limit := range []int{0, -1} {
+				unpaged, next, err := repositories.Claims.ListPage(ctx, filter, "", limit)
+				if err != nil || next != "" || !reflect.DeepEqual(unpaged, expected) {
+					t.Fatalf("list of %+v with limit %d returned %d claims, next %q, %v", filter, limit, len(unpaged), next, err)
+				}
+			}
+		}
+
+		if _, _, err := repositories.Claims.ListPage(ctx, &model.ClaimFilter{}, "invalid", 2); err != model.ErrInvalidCursor {
+			t.Fatalf("invalid cursor returned %v", err)
+		}
+
+		counts, err := repositories.Claims.CountByCatalog(ctx)
+		if err != nil || !reflect.DeepEqual(counts, map[string]int{"catalog0": 5, "catalog1": 4, "catalog2": 4}) {
+			t.Fatalf("counts by catalog %v, %v", counts, err)
+		}
+
+		indexed, err := repositories.Claims.RebuildIndexes(ctx)
+		if err != nil || indexed != len(claims) {
+			t.Fatalf("rebuilt %d indexes, %v", indexed, err)
+		}
+		if count, _ := repositories.Claims.Count(ctx, &model.ClaimFilter{CatalogId: "catalog1"}); count != 4 {
+			t.Fatalf("count after rebuild %d", count)
+		}
+	})
+}
+
+func TestCatalogRepository(t *testing.T) {
+	backends(t, func(t *testing.T, repositories *model.Repositories) {
+		ctx := context.Background()
+		for _, id := range []string{"b", "a", "c"} {
+			if err := repositories.Catalogs.Put(ctx, &model.Catalog{ID: id, Name: "catalog " + id, Created: 1, Modified: 1}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repositories.Catalogs.Put(ctx, &model.Catalog{ID: "a", Name: "renamed", Created: 1, Modified: 2}); err != nil {
+			t.Fatal(err)
+		}
+		catalog, err := repositories.Catalogs.Get(ctx, "a")
+		if err != nil || catalog.Name != "renamed" {
+			t.Fatalf("get returned %+v, %v", catalog, err)
+		}
+		if _, err := repositories.Catalogs.Get(ctx, "missing"); err != model.ErrNotFound {
+			t.Fatalf("get of missing catalog returned %v", err)
+		}
+		catalogs, err := repositories.Catalogs.List(ctx, 2)
+		if err != nil || len(catalogs) != 2 || catalogs[0].ID != "c" || catalogs[1].ID != "b" {
+			t.Fatalf("list returned %v, %v", catalogs, err)
+		}
+	})
+}
+
+func TestEditionRepository(t *testing.T) {
+	backends(t, func(t *testing.T, repositories *model.Repositories) {
+		ctx := context.Background()
+		editions := repositories.Editions
+		if last, err := editions.Last(ctx, "catalog"); err != nil || last != 0 {
+			t.Fatalf("last of new catalog %d, %v", last, err)
+		}
+		for _, expected := range []int{1, 2} {
+			if edition, err := editions.Next(ctx, "catalog", 0, 5); err != nil || edition != expected {
+				t.Fatalf("next edition %d, %v, expected %d", edition, err, expected)
+			}
+		}
+		// tokens minted on-chain are skipped
+		if edition, err := editions.Next(ctx, "catalog", 3, 5); err != nil || edition != 4 {
+			t.Fatalf("next edition after minted %d, %v", edition, err)
+		}
+		// only the last edition is released
+		if err := editions.Release(ctx, "catalog", 3); err != nil {
+			t.Fatal(err)
+		}
+		if err := editions.Release(ctx, "catalog", 4); err != nil {
+			t.Fatal(err)
+		}
+		if last, err := editions.Last(ctx, "catalog"); err != nil || last != 3 {
+			t.Fatalf("last after release %d, %v", last, err)
+		}
+		for _, expected := range []int{4, 5} {
+			if edition, err := editions.Next(ctx, "catalog", 0, 5); err != nil || edition != expected {
+				t.Fatalf("next edition %d, %v, expected %d", edition, err, expected)
+			}
+		}
+		if _, err := editions.Next(ctx, "catalog", 0, 5); err != model.ErrSoldOut {
+			t.Fatalf("next edition of sold out catalog returned %v", err)
+		}
+		if last, _ := editions.Last(ctx, "catalog"); last != 5 {
+			t.Fatalf("sold out catalog assigned edition %d", last)
+		}
+		if edition, err := editions.Next(ctx, "other", 0, 5); err != nil || edition != 1 {
+			t.Fatalf("next edition of other catalog %d, %v", edition, err)
+		}
+	})
+}
+
+func TestFingerprintAndUserRepository(t *testing.T) {
+	backends(t, func(t *testing.T, repositories *model.Repositories) {
+		ctx := context.Background()
+		fingerprint := &model.ClaimFingerprint{CatalogId: "catalog", VisitorId: "visitor"}
+		for i := 0; i < 2; i++ {
+			if err := repositories.Fingerprints.Put(ctx, fingerprint); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, err := repositories.Fingerprints.Get(ctx, "catalog", "visitor"); err != nil || *got != *fingerprint {
+			t.Fatalf("get fingerprint %+v, %v", got, err)
+		}
+		if _, err := repositories.Fingerprints.Get(ctx, "other", "visitor"); err != model.ErrNotFound {
+			t.Fatalf("get of missing fingerprint returned %v", err)
+		}
+
+		if err := repositories.Users.Put(ctx, &model.User{ID: "admin", Email: "admin@example.com", Name: "Admin"}); err != nil {
+			t.Fatal(err)
+		}
+		if user, err := repositories.Users.Get(ctx, "admin@example.com"); err != nil || user.Name != "Admin" {
+			t.Fatalf("get user %+v, %v", user, err)
+		}
+		if _, err := repositories.Users.Get(ctx, "missing@example.com"); err != model.ErrNotFound {
+			t.Fatalf("get of missing user returned %v", err)
+		}
+	})
+}
+
+func TestFunnelEventRepository(t *testing.T) {
+	backends(t, func(t *testing.T, repositories *model.Repositories) {
+		ctx := context.Background()
+		for i, catalogId := range []string{"a", "b", "", "a"} {
+			created := int64(1000 * (i + 1))
+			event := &model.FunnelEvent{
+				ID:        fmt.Sprintf("%013d_%d", created, i),
+				Step:      model.FunnelPayload,
+				Passed:    true,
+				CatalogId: catalogId,
+				Created:   created,
+			}
+			if err := repositories.FunnelEvents.Put(ctx, event); err != nil {
+				t.Fatal(err)
+			}
+		}
+		eventTimes := func(events []*model.FunnelEvent) []int64 {
+			times := []int64{}
+			for _, event := range events {
+				times = append(times, event.Created)
+			}
+			return times
+		}
+		events, err := repositories.FunnelEvents.List(ctx, "", 2000, 4000)
+		if err != nil || !reflect.DeepEqual(eventTimes(events), []int64{3000, 2000}) {
+			t.Fatalf("list events %v, %v", eventTimes(events), err)
+		}
+		events, err = repositories.FunnelEvents.List(ctx, "a", 0, 5000)
+		if err != nil || !reflect.DeepEqual(eventTimes(events), []int64{4000, 1000}) {
+			t.Fatalf("list events of catalog %v, %v", eventTimes(events), err)
+		}
+
+		pruned, err := repositories.FunnelEvents.Prune(ctx, 3000)
+		if err != nil || pruned != 2 {
+			t.Fatalf("pruned %d events, %v", pruned, err)
+		}
+		events, err = repositories.FunnelEvents.List(ctx, "", 0, 5000)
+		if err != nil || !reflect.DeepEqual(eventTimes(events), []int64{4000, 3000}) {
+			t.Fatalf("list events after pruning %v, %v", eventTimes(events), err)
+		}
+	})
+}
+
+func TestDB(t *testing.T) {
+	backends(t, func(t *testing.T, repositories *model.Repositories) {
+		ctx := context.Background()
+		db := repositories.DB
+		for _, key := range []string{"/bar/2", "/bar/1", "/bar/3", "/barbaz/1", "/other/1"} {
+			if err := db.Put(ctx, datastore.NewKey(key), []byte(key)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := db.Put(ctx, datastore.NewKey("/bar/1"), []byte{0, 0xff}); err != nil {
+			t.Fatal(err)
+		}
+		if value, err := db.Get(ctx, datastore.NewKey("/bar/1")); err != nil || !reflect.DeepEqual(value, []byte{0, 0xff}) {
+			t.Fatalf("get returned %v, %v", value, err)
+		}
+		if _, err := db.Get(ctx, datastore.NewKey("/bar/9")); err != datastore.ErrNotFound {
+			t.Fatalf("get of missing key returned %v", err)
+		}
+		if err := db.Delete(ctx, datastore.NewKey("/other/1")); err != nil {
+			t.Fatal(err)
+		}
+		if has, err := db.Has(ctx, datastore.NewKey("/other/1")); err != nil || has {
+			t.Fatalf("deleted key exists %v, %v", has, err)
+		}
+
+		for _, q := range []struct {
+			query    query.Query
+			expected []string
+		}{
+			{query.Query{Prefix: "/bar", Orders: []query.Order{query.OrderByKey{}}}, []string{"/bar/1", "/bar/2", "/bar/3"}},
+			{query.Query{Prefix: "/bar", Orders: []query.Order{query.OrderByKeyDescending{}}, Limit: 2}, []string{"/bar/3", "/bar/2"}},
+			{query.Query{Prefix: "/bar", Orders: []query.Order{query.OrderByKeyDescending{}}, Offset: 1}, []string{"/bar/2", "/bar/1"}},
+			{query.Query{Prefix: "/bar", Orders: []query.Order{query.OrderByKey{}}, Filters: []query.Filter{query.FilterKeyCompare{Op: query.GreaterThan, Key: "/bar/1"}}, KeysOnly: true}, []string{"/bar/2", "/bar/3"}},
+			{query.Query{Prefix: "/missing"}, []string{}},
+		} {
+			results, err := db.Query(ctx, q.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := results.Rest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys := []string{}
+			for _, entry := range entries {
+				keys = append(keys, entry.Key)
+			}
+			if !reflect.DeepEqual(keys, q.expected) {
+				t.Fatalf("query %v returned %v, expected %v", q.query, keys, q.expected)
+			}
+		}
+	})
+}
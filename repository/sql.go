@@ -0,0 +1,426 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// database/sql driver names of the SQL backends
+var sqlDrivers = map[string]string{
+	BackendSQLite:   "sqlite3",
+	BackendPostgres: "postgres",
+}
+
+// NewSQL connects to the SQLite or Postgres database and migrates its schema
+func NewSQL(dialect string, dsn string) (*model.Repositories, error) {
+	db, err := sql.Open(sqlDrivers[dialect], dsn)
+	if err != nil {
+		return nil, err
+	}
+	if dialect == BackendSQLite {
+		// SQLite allows a single writer
+		db.SetMaxOpenConns(1)
+	}
+	if err := migrate(db, dialect); err != nil {
+		db.Close()
+		return nil, err
+	}
+	store := &sqlStore{db: db, dialect: dialect}
+	return &model.Repositories{
+		Catalogs:     &sqlCatalogs{store},
+		Claims:       &sqlClaims{store},
+		Editions:     &sqlEditions{store},
+		Fingerprints: &sqlFingerprints{store},
+		FunnelEvents: &sqlFunnelEvents{store},
+		Users:        &sqlUsers{store},
+		DB:           &sqlDatastore{store},
+		Closer:       db,
+	}, nil
+}
+
+type sqlStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+func (s *sqlStore) exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := s.db.ExecContext(ctx, rebind(s.dialect, query), args...)
+	return err
+}
+
+// get decodes the JSON document of the single row query. Returns model.ErrNotFound if there is no row
+func (s *sqlStore) get(ctx context.Context, out interface{}, query string, args ...interface{}) error {
+	var data string
+	err := s.db.QueryRowContext(ctx, rebind(s.dialect, query), args...).Scan(&data)
+	if err == sql.ErrNoRows {
+		return model.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), out)
+}
+
+// list calls decode with the JSON document of every row of the query
+func (s *sqlStore) list(ctx context.Context, decode func(data []byte) error, query string, args ...interface{}) error {
+	rows, err := s.db.QueryContext(ctx, rebind(s.dialect, query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := decode([]byte(data)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+type sqlCatalogs struct {
+	store *sqlStore
+}
+
+func (sr *sqlCatalogs) Get(ctx context.Context, id string) (*model.Catalog, error) {
+	var catalog model.Catalog
+	if err := sr.store.get(ctx, &catalog, `SELECT data FROM catalogs WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+func (sr *sqlCatalogs) Put(ctx context.Context, catalog *model.Catalog) error {
+	data, err := json.Marshal(catalog)
+	if err != nil {
+		return err
+	}
+	return sr.store.exec(ctx, `INSERT INTO catalogs (id, external_ref, status, created, modified, data) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET external_ref = excluded.external_ref, status = excluded.status,
+		created = excluded.created, modified = excluded.modified, data = excluded.data`,
+		catalog.ID, catalog.ExternalRef, catalog.Status, catalog.Created, catalog.Modified, string(data))
+}
+
+func (sr *sqlCatalogs) List(ctx context.Context, limit int) ([]*model.Catalog, error) {
+	catalogs := []*model.Catalog{}
+	err := sr.store.list(ctx, func(data []byte) error {
+		var catalog model.Catalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return err
+		}
+		catalogs = append(catalogs, &catalog)
+		return nil
+	}, `SELECT data FROM catalogs ORDER BY id DESC`+limitClause(limit))
+	return catalogs, err
+}
+
+type sqlClaims struct {
+	store *sqlStore
+}
+
+func (sr *sqlClaims) Get(ctx context.Context, catalogId string, walletAddress string) (*model.Claim, error) {
+	var claim model.Claim
+	if err := sr.store.get(ctx, &claim, `SELECT data FROM claims WHERE id = ?`, claimID(catalogId, walletAddress)); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+func (sr *sqlClaims) Put(ctx context.Context, claim *model.Claim) error {
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return err
+	}
+	return sr.store.exec(ctx, `INSERT INTO claims (id, catalog_id, wallet_address, visitor_id, created, data) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET visitor_id = excluded.visitor_id, created = excluded.created, data = excluded.data`,
+		claimID(claim.CatalogId, claim.WalletAddress), claim.CatalogId, claim.WalletAddress, claim.VisitorId, claim.Created, string(data))
+}
+
+func (sr *sqlClaims) List(ctx context.Context, limit int) ([]*model.Claim, error) {
+	claims := []*model.Claim{}
+	err := sr.store.list(ctx, appendClaim(&claims), `SELECT data FROM claims ORDER BY created DESC, id DESC`+limitClause(limit))
+	return claims, err
+}
+
+func (sr *sqlClaims) ListPage(ctx context.Context, filter *model.ClaimFilter, cursor string, limit int) ([]*model.Claim, string, error) {
+	after, err := decodeClaimCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	where, args := claimConditions(filter)
+	if after != "" {
+		created, id, _ := parseClaimPosition(after)
+		where = append(where, "(created < ? OR (created = ? AND id < ?))")
+		args = append(args, created, created, id)
+	}
+	// one more to find out if there is a next page
+	query := `SELECT data FROM claims` + whereClause(where) + ` ORDER BY created DESC, id DESC`
+	if limit > 0 {
+		query += limitClause(limit + 1)
+	}
+	claims := []*model.Claim{}
+	if err := sr.store.list(ctx, appendClaim(&claims), query, args...); err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if limit > 0 && len(claims) > limit {
+		claims = claims[:limit]
+		next = util.EncodeCursor(claimPosition(claims[limit-1]))
+	}
+	return claims, next, nil
+}
+
+func (sr *sqlClaims) Count(ctx context.Context, filter *model.ClaimFilter) (int, error) {
+	where, args := claimConditions(filter)
+	var count int
+	err := sr.store.db.QueryRowContext(ctx, rebind(sr.store.dialect, `SELECT COUNT(*) FROM claims`+whereClause(where)), args...).Scan(&count)
+	return count, err
+}
+
+func (sr *sqlClaims) CountByCatalog(ctx context.Context) (map[string]int, error) {
+	rows, err := sr.store.db.QueryContext(ctx, `SELECT catalog_id, COUNT(*) FROM claims GROUP BY catalog_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var (
+			catalogId string
+			count     int
+		)
+		if err := rows.Scan(&catalogId, &count); err != nil {
+			return nil, err
+		}
+		counts[catalogId] = count
+	}
+	return counts, rows.Err()
+}
+
+// RebuildIndexes copies indexed columns of all claims from their documents (SQL indexes are maintained by the database)
+func (sr *sqlClaims) RebuildIndexes(ctx context.Context) (int, error) {
+	claims := []*model.Claim{}
+	if err := sr.store.list(ctx, appendClaim(&claims), `SELECT data FROM claims`); err != nil {
+		return 0, err
+	}
+	for _, claim := range claims {
+		if err := sr.store.exec(ctx, `UPDATE claims SET catalog_id = ?, wallet_address = ?, visitor_id = ?, created = ? WHERE id = ?`,
+			claim.CatalogId, claim.WalletAddress, claim.VisitorId, claim.Created, claimID(claim.CatalogId, claim.WalletAddress)); err != nil {
+			return 0, err
+		}
+	}
+	return len(claims), nil
+}
+
+func appendClaim(claims *[]*model.Claim) func(data []byte) error {
+	return func(data []byte) error {
+		var claim model.Claim
+		if err := json.Unmarshal(data, &claim); err != nil {
+			return err
+		}
+		*claims = append(*claims, &claim)
+		return nil
+	}
+}
+
+// claimConditions are the conditions of the filter (none if the filter is empty)
+func claimConditions(filter *model.ClaimFilter) ([]string, []interface{}) {
+	where := []string{}
+	args := []interface{}{}
+	for _, c := range []struct {
+		column string
+		value  string
+	}{
+		{"catalog_id", filter.CatalogId},
+		{"wallet_address", filter.WalletAddress},
+		{"visitor_id", filter.VisitorId},
+	} {
+		if c.value != "" {
+			where = append(where, c.column+" = ?")
+			args = append(args, c.value)
+		}
+	}
+	if filter.Since > 0 {
+		where = append(where, "created >= ?")
+		args = append(args, filter.Since)
+	}
+	if filter.Until > 0 {
+		where = append(where, "created < ?")
+		args = append(args, filter.Until)
+	}
+	return where, args
+}
+
+type sqlEditions struct {
+	store *sqlStore
+}
+
+// Next increments the counter within a transaction. The update locks the counter row until commit, so
+// concurrent assignments (of all replicas) wait for each other
+func (sr *sqlEditions) Next(ctx context.Context, catalogId string, minted int, maxEditions int) (int, error) {
+	tx, err := sr.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UnixMilli()
+	if _, err := tx.ExecContext(ctx, rebind(sr.store.dialect, `INSERT INTO claim_editions (catalog_id, last_edition, modified) VALUES (?, 0, ?)
+		ON CONFLICT (catalog_id) DO NOTHING`), catalogId, now); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, rebind(sr.store.dialect, `UPDATE claim_editions
+		SET last_edition = CASE WHEN last_edition > ? THEN last_edition ELSE ? END + 1, modified = ? WHERE catalog_id = ?`),
+		minted, minted, now, catalogId); err != nil {
+		return 0, err
+	}
+	var edition int
+	if err := tx.QueryRowContext(ctx, rebind(sr.store.dialect, `SELECT last_edition FROM claim_editions WHERE catalog_id = ?`), catalogId).Scan(&edition); err != nil {
+		return 0, err
+	}
+	if edition > maxEditions {
+		return 0, model.ErrSoldOut
+	}
+	return edition, tx.Commit()
+}
+
+func (sr *sqlEditions) Release(ctx context.Context, catalogId string, edition int) error {
+	return sr.store.exec(ctx, `UPDATE claim_editions SET last_edition = last_edition - 1, modified = ? WHERE catalog_id = ? AND last_edition = ?`,
+		time.Now().UnixMilli(), catalogId, edition)
+}
+
+func (sr *sqlEditions) Last(ctx context.Context, catalogId string) (int, error) {
+	var edition int
+	err := sr.store.db.QueryRowContext(ctx, rebind(sr.store.dialect, `SELECT last_edition FROM claim_editions WHERE catalog_id = ?`), catalogId).Scan(&edition)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return edition, err
+}
+
+type sqlFingerprints struct {
+	store *sqlStore
+}
+
+func (sr *sqlFingerprints) Get(ctx context.Context, catalogId string, visitorId string) (*model.ClaimFingerprint, error) {
+	var found int
+	err := sr.store.db.QueryRowContext(ctx, rebind(sr.store.dialect, `SELECT 1 FROM fingerprints WHERE catalog_id = ? AND visitor_id = ?`), catalogId, visitorId).Scan(&found)
+	if err == sql.ErrNoRows {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.ClaimFingerprint{CatalogId: catalogId, VisitorId: visitorId}, nil
+}
+
+func (sr *sqlFingerprints) Put(ctx context.Context, fingerprint *model.ClaimFingerprint) error {
+	return sr.store.exec(ctx, `INSERT INTO fingerprints (catalog_id, visitor_id) VALUES (?, ?) ON CONFLICT DO NOTHING`,
+		fingerprint.CatalogId, fingerprint.VisitorId)
+}
+
+type sqlFunnelEvents struct {
+	store *sqlStore
+}
+
+func (sr *sqlFunnelEvents) Put(ctx context.Context, event *model.FunnelEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return sr.store.exec(ctx, `INSERT INTO funnel_events (id, catalog_id, created, data) VALUES (?, ?, ?, ?)`,
+		event.ID, event.CatalogId, event.Created, string(data))
+}
+
+func (sr *sqlFunnelEvents) List(ctx context.Context, catalogId string, from int64, to int64) ([]*model.FunnelEvent, error) {
+	conditions := []string{"created >= ?", "created < ?"}
+	args := []interface{}{from, to}
+	if catalogId != "" {
+		conditions = append(conditions, "catalog_id = ?")
+		args = append(args, catalogId)
+	}
+	events := []*model.FunnelEvent{}
+	err := sr.store.list(ctx, func(data []byte) error {
+		var event model.FunnelEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return err
+		}
+		events = append(events, &event)
+		return nil
+	}, `SELECT data FROM funnel_events`+whereClause(conditions)+` ORDER BY id DESC`, args...)
+	return events, err
+}
+
+func (sr *sqlFunnelEvents) Prune(ctx context.Context, before int64) (int, error) {
+	res, err := sr.store.db.ExecContext(ctx, rebind(sr.store.dialect, `DELETE FROM funnel_events WHERE created < ?`), before)
+	if err != nil {
+		return 0, err
+	}
+	pruned, err := res.RowsAffected()
+	return int(pruned), err
+}
+
+type sqlUsers struct {
+	store *sqlStore
+}
+
+func (sr *sqlUsers) Get(ctx context.Context, email string) (*model.User, error) {
+	var user model.User
+	if err := sr.store.get(ctx, &user, `SELECT data FROM users WHERE email = ?`, email); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (sr *sqlUsers) Put(ctx context.Context, user *model.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	return sr.store.exec(ctx, `INSERT INTO users (email, id, data) VALUES (?, ?, ?)
+		ON CONFLICT (email) DO UPDATE SET id = excluded.id, data = excluded.data`,
+		user.Email, user.ID, string(data))
+}
+
+// rebind replaces ? placeholders with $1, $2, ... for Postgres
+func rebind(dialect string, query string) string {
+	if dialect != BackendPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func limitClause(limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	return " LIMIT " + strconv.Itoa(limit)
+}
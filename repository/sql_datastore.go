@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+)
+
+// sqlDatastore is the key-value datastore of SQL backends (key_values table). Queries select keys of the
+// prefix ordered by key in the database, other query options (filters, custom orders) are applied in memory
+type sqlDatastore struct {
+	store *sqlStore
+}
+
+func (ds *sqlDatastore) Get(ctx context.Context, key datastore.Key) ([]byte, error) {
+	var value []byte
+	err := ds.store.db.QueryRowContext(ctx, rebind(ds.store.dialect, `SELECT value FROM key_values WHERE id = ?`), key.Bytes()).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, datastore.ErrNotFound
+	}
+	return value, err
+}
+
+func (ds *sqlDatastore) Has(ctx context.Context, key datastore.Key) (bool, error) {
+	var found int
+	err := ds.store.db.QueryRowContext(ctx, rebind(ds.store.dialect, `SELECT 1 FROM key_values WHERE id = ?`), key.Bytes()).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (ds *sqlDatastore) GetSize(ctx context.Context, key datastore.Key) (int, error) {
+	return datastore.GetBackedSize(ctx, ds, key)
+}
+
+func (ds *sqlDatastore) Put(ctx context.Context, key datastore.Key, value []byte) error {
+	return ds.store.exec(ctx, `INSERT INTO key_values (id, value) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET value = excluded.value`,
+		key.Bytes(), value)
+}
+
+func (ds *sqlDatastore) Delete(ctx context.Context, key datastore.Key) error {
+	return ds.store.exec(ctx, `DELETE FROM key_values WHERE id = ?`, key.Bytes())
+}
+
+func (ds *sqlDatastore) Query(ctx context.Context, q query.Query) (query.Results, error) {
+	where := []string{}
+	args := []interface{}{}
+	// a prefix of /bar only selects /bar/baz, not /barbaz ('0' follows '/')
+	if prefix := path.Clean("/" + q.Prefix); prefix != "/" {
+		where = append(where, "id >= ?", "id < ?")
+		args = append(args, []byte(prefix+"/"), []byte(prefix+"0"))
+	}
+	order := " ORDER BY id"
+	remaining := query.Query{Filters: q.Filters, Orders: q.Orders, Offset: q.Offset, Limit: q.Limit}
+	if len(q.Orders) == 1 {
+		switch q.Orders[0].(type) {
+		case query.OrderByKey, *query.OrderByKey:
+			remaining.Orders = nil
+		case query.OrderByKeyDescending, *query.OrderByKeyDescending:
+			order = " ORDER BY id DESC"
+			remaining.Orders = nil
+		}
+	}
+	limit := ""
+	if len(remaining.Filters) == 0 && len(remaining.Orders) == 0 && remaining.Offset == 0 {
+		limit = limitClause(remaining.Limit)
+		remaining.Limit = 0
+	}
+
+	rows, err := ds.store.db.QueryContext(ctx, rebind(ds.store.dialect, `SELECT id, value FROM key_values`+whereClause(where)+order+limit), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries := []query.Entry{}
+	for rows.Next() {
+		var (
+			id    []byte
+			value []byte
+		)
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, err
+		}
+		entry := query.Entry{Key: string(id), Size: len(value)}
+		if !q.KeysOnly {
+			entry.Value = value
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return query.NaiveQueryApply(remaining, query.ResultsWithEntries(q, entries)), nil
+}
+
+func (ds *sqlDatastore) Sync(ctx context.Context, prefix datastore.Key) error {
+	return nil
+}
+
+// Close does nothing, the database is closed with the repositories
+func (ds *sqlDatastore) Close() error {
+	return nil
+}
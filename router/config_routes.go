@@ -16,16 +16,45 @@ import (
 func ConfigAPI(router *gin.Engine, env *model.Environment, conf *config.Config) *gin.Engine {
 
 	// initialize services
+	notificationService := service.NewNotificationService()
+	pinService := service.NewPinService(env)
+	budgetService := service.NewBudgetService(env, notificationService)
 	nftCatalogService := service.NewNftCatalog(env)
+	funnelService := service.NewFunnelService(env)
 	userService := service.NewUserService(env)
-	nftClaimService := service.NewNftClaimService(env)
-	nftImageService := service.NewNftImagesService(env)
+	nftMetadataService := service.NewNftMetadataService(env, nftCatalogService, pinService)
+	nftClaimService := service.NewNftClaimService(env, budgetService, nftMetadataService, nftCatalogService, funnelService)
+	nftImageService := service.NewNftImagesService(env, pinService, nftCatalogService, nftClaimService)
+	nftCatalogImportService := service.NewNftCatalogImportService(nftCatalogService, nftImageService)
+	contractAdminService := service.NewContractAdminService(env)
+	contractUpgradeService := service.NewContractUpgradeService(env, contractAdminService)
+	governanceService := service.NewGovernanceService(env, notificationService, contractUpgradeService)
+	pinHealthService := service.NewPinHealthService(env, nftCatalogService, nftClaimService, pinService, notificationService)
+	nftStatsService := service.NewNftStatsService(env, nftClaimService, nftCatalogService)
 
 	// intialize API endpoints
 	nftCatalogApi := api.NewNftCatalogAPI(nftCatalogService)
+	nftCatalogImportApi := api.NewNftCatalogImportAPI(nftCatalogImportService)
 	userApi := api.NewUserAPI(userService)
 	nftImageApi := api.NewNftImagesAPI(nftImageService)
-	claimApi := api.NewClaimAPI(nftClaimService, nftCatalogService)
+	claimApi := api.NewClaimAPI(nftClaimService, nftCatalogService, funnelService)
+	budgetApi := api.NewBudgetAPI(budgetService)
+	contractAdminApi := api.NewContractAdminAPI(contractAdminService)
+	contractUpgradeApi := api.NewContractUpgradeAPI(contractUpgradeService)
+	governanceApi := api.NewGovernanceAPI(governanceService)
+	nftMetadataApi := api.NewNftMetadataAPI(nftMetadataService, nftClaimService, nftCatalogService)
+	pinHealthApi := api.NewPinHealthAPI(pinHealthService)
+	statsApi := api.NewStatsAPI(nftStatsService)
+	funnelApi := api.NewFunnelAPI(funnelService)
+
+	// background jobs
+	go governanceService.Watch()
+	go nftClaimService.WatchMetadataFreeze()
+	go pinHealthService.Watch()
+	go nftCatalogService.WatchScheduled()
+	go nftCatalogService.WatchTokenCounts()
+	go nftClaimService.WatchMintReceipts()
+	go funnelService.WatchRetention()
 
 	// enable cors
 	router.Use(cors.New(cors.Config{
@@ -39,17 +68,20 @@ func ConfigAPI(router *gin.Engine, env *model.Environment, conf *config.Config)
 	root := router.Group("/")
 	{
 		root.GET("/", endpoints.PingEndpoint)
+		root.GET("/metadata/:tokenId", nftMetadataApi.GetMetadata)
 	}
 
 	// public APIs
 	public := router.Group("/api/v1")
 	{
 		public.GET("/catalog/:id", nftCatalogApi.GetCatalog)
+		public.GET("/catalog/:id/stats", statsApi.CatalogStats)
 		public.GET("/catalog", nftCatalogApi.ListCatalogs)
 		public.POST("/login", userApi.Login)
 		public.GET("/claim/:address/payload/:catalogId", claimApi.SigningPayload)
 		public.POST("/claim", claimApi.MintClaim)
 		public.GET("/user/claims/:walletaddress", claimApi.ListClaimsByUser)
+		public.GET("/metadata/:tokenId", nftMetadataApi.GetMetadata)
 	}
 
 	// init JWT Authentication Middleware for private endpoints
@@ -63,11 +95,44 @@ func ConfigAPI(router *gin.Engine, env *model.Environment, conf *config.Config)
 	{
 		private.POST("/catalog", nftCatalogApi.PutCatalog)
 		private.PUT("/catalog", nftCatalogApi.PutCatalog)
+		private.GET("/catalog/:id/metadata/preview", nftMetadataApi.PreviewMetadata)
+		private.GET("/catalog/:id/revisions", nftCatalogApi.ListRevisions)
+		private.GET("/catalog/:id/revisions/:revision", nftCatalogApi.GetRevision)
+		private.POST("/catalog/:id/revisions/:revision/restore", nftCatalogApi.RestoreRevision)
+		private.GET("/catalog/:id/diff", nftCatalogApi.DiffRevisions)
+		private.GET("/admin/catalog", nftCatalogApi.AdminListCatalogs)
+		private.GET("/admin/catalog/:id", nftCatalogApi.AdminGetCatalog)
+		private.PUT("/admin/catalog/:id/status", nftCatalogApi.SetStatus)
+		private.POST("/admin/catalogs/import", nftCatalogImportApi.Import)
+		private.GET("/admin/catalogs/export", nftCatalogImportApi.Export)
 		private.GET("/bridge/balance", claimApi.GetBridgeBalance)
 		private.POST("/nftimage/upload", nftImageApi.Upload)
 		private.GET("/nftimage/list", nftImageApi.List)
 		private.DELETE("/nftimage/:hash", nftImageApi.RemovePin)
+		private.GET("/nftimage/removals", nftImageApi.ListRemovals)
+		private.GET("/pin/health", pinHealthApi.GetReport)
+		private.POST("/pin/health/check", pinHealthApi.Check)
 		private.GET("/claim", claimApi.ListClaims)
+		private.GET("/budget", budgetApi.GetBudget)
+		private.PUT("/budget", budgetApi.PutBudget)
+		private.GET("/budget/catalog/:catalogId", budgetApi.GetCatalogBudget)
+		private.PUT("/budget/catalog/:catalogId", budgetApi.PutCatalogBudget)
+		private.GET("/contract/status", contractAdminApi.GetStatus)
+		private.GET("/contract/roles/:role/:account", contractAdminApi.HasRole)
+		private.POST("/contract/roles/grant", contractAdminApi.GrantRole)
+		private.POST("/contract/roles/revoke", contractAdminApi.RevokeRole)
+		private.POST("/contract/pause", contractAdminApi.Pause)
+		private.POST("/contract/unpause", contractAdminApi.Unpause)
+		private.GET("/contract/transactions", contractAdminApi.ListTransactions)
+		private.GET("/contract/implementation", contractUpgradeApi.GetImplementation)
+		private.GET("/contract/upgrades", contractUpgradeApi.ListUpgrades)
+		private.POST("/contract/upgrade/check", contractUpgradeApi.CheckUpgrade)
+		private.POST("/contract/upgrade", contractUpgradeApi.Upgrade)
+		private.GET("/contract/events", governanceApi.ListEvents)
+		private.GET("/admin/stats/claims", statsApi.ClaimStats)
+		private.GET("/admin/stats/catalogs", statsApi.TopCatalogs)
+		private.GET("/admin/stats/summary", statsApi.Summary)
+		private.GET("/admin/stats/funnel", funnelApi.Report)
 	}
 	return router
 }
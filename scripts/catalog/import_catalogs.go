@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+var Red = "\033[31m"
+var Green = "\033[32m"
+var Reset = "\033[0m"
+
+func main() {
+
+	server := flag.String("server", "http://localhost:8080", "Bridge server URL")
+	token := flag.String("token", "", "JWT token of the admin (from /api/v1/login)")
+	file := flag.String("file", "", "Catalogs file (.json, .yaml, .yml or .csv)")
+	format := flag.String("format", "", "json, yaml or csv (by file extension by default)")
+	apply := flag.Bool("apply", false, "Store the catalogs (only validated by default)")
+	flag.Parse()
+
+	if *file == "" || *token == "" {
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	if *format == "" {
+		*format = service.CatalogFormat(*file)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		Pkg.Fatal("Failed to read catalogs file: %s", err.Error())
+	}
+	rows, err := service.CatalogImportRows(data, *format)
+	if err != nil {
+		Pkg.Fatal("Failed to read catalogs file: %s", err.Error())
+	}
+
+	// images are referenced by local paths (relative to the catalogs file) and sent by their file names
+	images := map[string]string{}
+	for _, row := range rows {
+		item := &model.CatalogImportItem{}
+		if err := json.Unmarshal(row, item); err != nil || item.ImageFile == "" {
+			continue
+		}
+		path := item.ImageFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(*file), path)
+		}
+		name := filepath.Base(path)
+		if other, ok := images[name]; ok && other != path {
+			Pkg.Fatal("Different images with the same file name: %s, %s", other, path)
+		}
+		images[name] = path
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := addFile(writer, "file", *file); err != nil {
+		Pkg.Fatal("Failed to read catalogs file: %s", err.Error())
+	}
+	for _, path := range images {
+		if err := addFile(writer, "images", path); err != nil {
+			Pkg.Fatal("Failed to read image %s: %s", path, err.Error())
+		}
+	}
+	if err := writer.Close(); err != nil {
+		Pkg.Fatal("Failed to create request: %s", err.Error())
+	}
+
+	query := url.Values{}
+	query.Set("format", *format)
+	query.Set("dryRun", strconv.FormatBool(!*apply))
+	req, err := http.NewRequest(http.MethodPost, *server+"/api/v1/admin/catalogs/import?"+query.Encode(), &body)
+	if err != nil {
+		Pkg.Fatal("Failed to create request: %s", err.Error())
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", *token)
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		Pkg.Fatal("Import request failed: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		Pkg.Fatal("Failed to read response: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		Pkg.Fatal("Import failed (%s): %s", resp.Status, string(respBody))
+	}
+
+	result := &model.CatalogImportResult{}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		Pkg.Fatal("Failed to parse response: %s", err.Error())
+	}
+	stored := 0
+	for _, row := range result.Rows {
+		if row.Stored {
+			stored++
+		}
+		if len(row.Errors) == 0 {
+			fmt.Printf("row %d: %s %s %s\n", row.Row, row.Action, row.ID, row.Name)
+			continue
+		}
+		for _, rowErr := range row.Errors {
+			fmt.Printf("%srow %d: %s%s\n", Red, row.Row, rowErr, Reset)
+		}
+	}
+	fmt.Printf("created: %d, updated: %d, unchanged: %d, failed: %d\n", result.Created, result.Updated, result.Unchanged, result.Failed)
+	if result.Failed > 0 {
+		if stored > 0 {
+			fmt.Printf("%s%d catalogs were stored before the import stopped, fix the rows above and import again%s\n", Red, stored, Reset)
+		} else {
+			fmt.Printf("%sNo catalogs were stored, fix the rows above%s\n", Red, Reset)
+		}
+		os.Exit(2)
+	}
+	if result.Imported {
+		fmt.Printf("%sSuccessfully imported catalogs%s\n", Green, Reset)
+	} else {
+		fmt.Printf("%sAll catalogs are valid, run with -apply to import them%s\n", Green, Reset)
+	}
+}
+
+func addFile(writer *multipart.Writer, field string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	part, err := writer.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, f)
+	return err
+}
+
+type Pkg string
+
+func (self Pkg) Fatal(s string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", self, fmt.Sprintf(s, a...))
+	os.Exit(2)
+}
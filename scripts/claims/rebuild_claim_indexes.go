@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	leveldb "github.com/ipfs/go-ds-leveldb"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/repository"
+	"gopkg.in/yaml.v3"
+)
+
+var Green = "\033[32m"
+var Reset = "\033[0m"
+
+type YamlConfig struct {
+	DatastorePath string              `yaml:"datastore_path"`
+	Storage       lc.StorageSubConfig `yaml:"storage"`
+}
+
+// rebuilds secondary indexes of claims (by catalog, wallet, visitor and creation time).
+// Stop the server first when using the leveldb storage (the datastore can't be opened twice)
+func main() {
+	config := flag.String("config", "", "Config file path")
+	timeout := flag.Duration("timeout", 10*time.Minute, "Timeout of the rebuild")
+	flag.Parse()
+
+	if *config == "" {
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	// check if config exists
+	if _, err := os.Stat(*config); errors.Is(err, os.ErrNotExist) {
+		Pkg.Fatal("Config file not found %s", *config)
+	}
+	dat, err := os.ReadFile(*config)
+	if err != nil {
+		Pkg.Fatal("Failed to read config file: %s", err.Error())
+	}
+	conf := YamlConfig{}
+	if err := yaml.Unmarshal(dat, &conf); err != nil {
+		Pkg.Fatal("Failed to parse config file: %s", err.Error())
+	}
+
+	ds, err := leveldb.NewDatastore(conf.DatastorePath, &leveldb.Options{})
+	if err != nil {
+		Pkg.Fatal("Failed to open datastore: %s", err.Error())
+	}
+	defer ds.Close()
+	repositories, err := repository.New(conf.Storage, ds)
+	if err != nil {
+		Pkg.Fatal("Failed to open storage: %s", err.Error())
+	}
+	if repositories.Closer != nil {
+		defer repositories.Closer.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	indexed, err := repositories.Claims.RebuildIndexes(ctx)
+	if err != nil {
+		Pkg.Fatal("Failed to rebuild claim indexes: %s", err.Error())
+	}
+
+	fmt.Printf("%sSuccessfully indexed %d claims%s\n", Green, indexed, Reset)
+}
+
+type Pkg string
+
+func (self Pkg) Fatal(s string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", self, fmt.Sprintf(s, a...))
+	os.Exit(2)
+}
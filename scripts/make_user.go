@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -11,8 +10,9 @@ import (
 
 	"github.com/chryscloud/go-microkit-plugins/crypto"
 	leveldb "github.com/ipfs/go-ds-leveldb"
+	lc "github.com/mailio/mailio-nft-server/config"
 	"github.com/mailio/mailio-nft-server/model"
-	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mailio/mailio-nft-server/repository"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,7 +21,8 @@ var Green = "\033[32m"
 var Reset = "\033[0m"
 
 type YamlConfig struct {
-	DatastorePath string `yaml:"datastore_path"`
+	DatastorePath string              `yaml:"datastore_path"`
+	Storage       lc.StorageSubConfig `yaml:"storage"`
 }
 
 func main() {
@@ -61,6 +62,10 @@ func main() {
 	if err != nil {
 		Pkg.Fatal("Failed to open datastore", err.Error())
 	}
+	repositories, err := repository.New(conf.Storage, ds)
+	if err != nil {
+		Pkg.Fatal("Failed to open storage", err.Error())
+	}
 
 	passHash, err := crypto.HashPassword(*password)
 	if err != nil {
@@ -75,15 +80,13 @@ func main() {
 		Created:  time.Now().UnixMilli(),
 		Modified: time.Now().UnixMilli(),
 	}
-	key := util.CreateKey(model.UserTable, u.Email)
-	if userBytes, mErr := json.Marshal(u); err != nil {
-		Pkg.Fatal("Failed to marshal user: %s", mErr.Error())
-	} else {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-		if err := ds.Put(ctx, key, userBytes); err != nil {
-			Pkg.Fatal("Failed to save user: %s", err.Error())
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := repositories.Users.Put(ctx, &u); err != nil {
+		Pkg.Fatal("Failed to save user: %s", err.Error())
+	}
+	if repositories.Closer != nil {
+		repositories.Closer.Close()
 	}
 
 	sayHello()
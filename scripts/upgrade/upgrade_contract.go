@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	cfg "github.com/chryscloud/go-microkit-plugins/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	leveldb "github.com/ipfs/go-ds-leveldb"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	nft "github.com/mailio/mailio-nft-server/onchain/mailionft"
+	"github.com/mailio/mailio-nft-server/repository"
+	"github.com/mailio/mailio-nft-server/service"
+)
+
+var Red = "\033[31m"
+var Green = "\033[32m"
+var Reset = "\033[0m"
+
+func main() {
+
+	config := flag.String("config", "", "Config file path")
+	implementation := flag.String("implementation", "", "Address of the new MailioNFT implementation")
+	abiPath := flag.String("abi", "", "Path to JSON ABI of the new implementation (checked for compatibility with the embedded ABI)")
+	callData := flag.String("calldata", "", "Hex encoded call executed after the upgrade (optional)")
+	checkOnly := flag.Bool("check", false, "Only run the upgrade checks")
+	flag.Parse()
+
+	if *config == "" || *implementation == "" || *abiPath == "" {
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	if !common.IsHexAddress(*implementation) {
+		Pkg.Fatal("Invalid implementation address", *implementation)
+	}
+
+	if err := cfg.NewYamlConfig(*config, &lc.Conf); err != nil {
+		Pkg.Fatal("Failed to load config file", err.Error())
+	}
+
+	input := &model.ContractUpgradeInput{
+		Implementation: *implementation,
+		CallData:       *callData,
+	}
+	abiJson, err := os.ReadFile(*abiPath)
+	if err != nil {
+		Pkg.Fatal("Failed to read ABI file", err.Error())
+	}
+	input.Abi = string(abiJson)
+
+	// upgrade is recorded into the datastore, so the server must be stopped (when using leveldb storage)
+	var ds *leveldb.Datastore
+	if lc.Conf.Storage.Backend == "" || lc.Conf.Storage.Backend == repository.BackendLevelDB {
+		d, err := leveldb.NewDatastore(lc.Conf.DatastorePath, &leveldb.Options{})
+		if err != nil {
+			Pkg.Fatal("Failed to open datastore (is the server still running?)", err.Error())
+		}
+		defer d.Close()
+		ds = d
+	}
+	repositories, err := repository.New(lc.Conf.Storage, ds)
+	if err != nil {
+		Pkg.Fatal("Failed to open storage", err.Error())
+	}
+	if repositories.Closer != nil {
+		defer repositories.Closer.Close()
+	}
+	ethClient, err := ethclient.Dial(lc.Conf.BlockchainConfig.Endpoint)
+	if err != nil {
+		Pkg.Fatal("Failed to connect to blockchain", err.Error())
+	}
+	contract, err := nft.NewMailionft(common.HexToAddress(lc.Conf.BlockchainConfig.MailioNFTProxyAddress), ethClient)
+	if err != nil {
+		Pkg.Fatal("Failed to load contract", err.Error())
+	}
+	env := &model.Environment{
+		Repositories: repositories,
+		EthClient:    ethClient,
+		NftContract:  contract,
+	}
+	upgradeService := service.NewContractUpgradeService(env, service.NewContractAdminService(env))
+
+	check, err := upgradeService.Check(input)
+	if err != nil {
+		Pkg.Fatal("Failed to run upgrade checks", err.Error())
+	}
+	printJson(check)
+	if !check.Ok {
+		Pkg.Fatal("Upgrade checks failed", strings.Join(check.Errors, "; "))
+	}
+	fmt.Printf("%sUpgrade checks passed%s\n", Green, Reset)
+	for _, warning := range check.Warnings {
+		fmt.Printf("%sWarning: %s%s\n", Red, warning, Reset)
+	}
+	if *checkOnly {
+		return
+	}
+
+	fmt.Printf("Upgrade proxy %s from %s to %s? Type 'upgrade' to continue: ", lc.Conf.BlockchainConfig.MailioNFTProxyAddress, check.CurrentImplementation, check.Implementation)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(answer) != "upgrade" {
+		Pkg.Fatal("Upgrade cancelled", "no changes were made")
+	}
+
+	upgrade, _, err := upgradeService.Upgrade(input)
+	if err != nil {
+		Pkg.Fatal("Upgrade failed", err.Error())
+	}
+	printJson(upgrade)
+	fmt.Printf("%sSuccessfully upgraded Mailio NFT contract%s\n", Green, Reset)
+}
+
+func printJson(obj interface{}) {
+	out, _ := json.MarshalIndent(obj, "", "  ")
+	fmt.Println(string(out))
+}
+
+type Pkg string
+
+func (self Pkg) Fatal(s string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", self, fmt.Sprintf(s, a...))
+	os.Exit(2)
+}
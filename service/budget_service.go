@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mitchellh/mapstructure"
+)
+
+type BudgetService struct {
+	environment         *model.Environment
+	notificationService *NotificationService
+	lock                sync.Mutex
+	notified            map[string]bool // budgets (per day) admins have already been notified about
+}
+
+func NewBudgetService(environment *model.Environment, notificationService *NotificationService) *BudgetService {
+	return &BudgetService{
+		environment:         environment,
+		notificationService: notificationService,
+		notified:            map[string]bool{},
+	}
+}
+
+// GetSettings returns global budget settings (empty settings if budgets were never set)
+func (bs *BudgetService) GetSettings() (*model.BudgetSettings, error) {
+	var settings model.BudgetSettings
+	err := bs.get(util.CreateKey(model.BudgetTable, model.GlobalBudgetID), &settings)
+	if err != nil && err != model.ErrNotFound {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// PutSettings overwrites global budget settings
+func (bs *BudgetService) PutSettings(settings *model.BudgetSettings) (*model.BudgetSettings, error) {
+	settings.Modified = time.Now().UnixMilli()
+	err := bs.put(util.CreateKey(model.BudgetTable, model.GlobalBudgetID), settings)
+	if err != nil {
+		lc.Log.Error("failed to store budget settings", err)
+		return nil, err
+	}
+	return settings, nil
+}
+
+// GetCatalogBudget returns budget of a single catalog or model.ErrNotFound
+func (bs *BudgetService) GetCatalogBudget(catalogId string) (*model.CatalogBudget, error) {
+	var budget model.CatalogBudget
+	err := bs.get(util.CreateKey(model.BudgetTable, catalogId), &budget)
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// PutCatalogBudget overwrites the budget of a single catalog
+func (bs *BudgetService) PutCatalogBudget(budget *model.CatalogBudget) (*model.CatalogBudget, error) {
+	budget.Modified = time.Now().UnixMilli()
+	err := bs.put(util.CreateKey(model.BudgetTable, budget.CatalogId), budget)
+	if err != nil {
+		lc.Log.Error("failed to store catalog budget", err)
+		return nil, err
+	}
+	return budget, nil
+}
+
+// GetStatus returns global budget settings together with todays spending
+func (bs *BudgetService) GetStatus() (*model.BudgetStatus, error) {
+	settings, err := bs.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	spent, err := bs.getSpent(dailySpentID(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return &model.BudgetStatus{
+		Settings:   *settings,
+		SpentToday: util.WeiToNative(spent),
+	}, nil
+}
+
+// GetCatalogStatus returns the effective limit and total spending of a catalog
+func (bs *BudgetService) GetCatalogStatus(catalogId string) (*model.CatalogBudgetStatus, error) {
+	settings, err := bs.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	limit, err := bs.catalogLimit(catalogId, settings)
+	if err != nil {
+		return nil, err
+	}
+	spent, err := bs.getSpent(catalogSpentID(catalogId))
+	if err != nil {
+		return nil, err
+	}
+	return &model.CatalogBudgetStatus{
+		CatalogId: catalogId,
+		Limit:     limit,
+		Spent:     util.WeiToNative(spent),
+	}, nil
+}
+
+// Reserve checks if the fee (in wei) of a mint transaction fits in all the budgets and adds it to daily
+// and catalog spending. Returns the daily budget (day_YYYYMMDD) the fee is reserved in or
+// model.ErrBudget if any of the budgets would be exceeded
+func (bs *BudgetService) Reserve(catalogId string, fee *big.Int) (string, error) {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+
+	now := time.Now()
+	settings, err := bs.GetSettings()
+	if err != nil {
+		return "", err
+	}
+
+	// per transaction fee ceiling
+	if settings.MaxTxFee != "" {
+		maxFee, err := util.NativeToWei(settings.MaxTxFee)
+		if err != nil {
+			lc.Log.Error("invalid max transaction fee budget", err)
+			return "", err
+		}
+		if fee.Cmp(maxFee) > 0 {
+			bs.notifyExceeded("max_tx_fee", now, fmt.Sprintf("Mint transaction fee %s is above the ceiling of %s. Claims are refused until gas prices drop.", util.WeiToNative(fee), settings.MaxTxFee))
+			return "", model.ErrBudget
+		}
+	}
+
+	// daily budget across all catalogs
+	dayID := dailySpentID(now)
+	daySpent, err := bs.getSpent(dayID)
+	if err != nil {
+		return "", err
+	}
+	daySpent.Add(daySpent, fee)
+	if settings.DailyLimit != "" {
+		dailyLimit, err := util.NativeToWei(settings.DailyLimit)
+		if err != nil {
+			lc.Log.Error("invalid daily budget", err)
+			return "", err
+		}
+		if daySpent.Cmp(dailyLimit) > 0 {
+			bs.notifyExceeded(dayID, now, fmt.Sprintf("Daily budget of %s is spent. Claims are refused until tomorrow (UTC).", settings.DailyLimit))
+			return "", model.ErrBudget
+		}
+	}
+
+	// catalog budget
+	catalogID := catalogSpentID(catalogId)
+	catalogSpent, err := bs.getSpent(catalogID)
+	if err != nil {
+		return "", err
+	}
+	catalogSpent.Add(catalogSpent, fee)
+	limit, err := bs.catalogLimit(catalogId, settings)
+	if err != nil {
+		return "", err
+	}
+	if limit != "" {
+		catalogLimit, err := util.NativeToWei(limit)
+		if err != nil {
+			lc.Log.Error("invalid catalog budget", err)
+			return "", err
+		}
+		if catalogSpent.Cmp(catalogLimit) > 0 {
+			bs.notifyExceeded(catalogID, now, fmt.Sprintf("Budget of %s for catalog %s is spent. Claims for the catalog are refused.", limit, catalogId))
+			return "", model.ErrBudget
+		}
+	}
+
+	if err := bs.putSpent(dayID, daySpent); err != nil {
+		return "", err
+	}
+	return dayID, bs.putSpent(catalogID, catalogSpent)
+}
+
+// Release returns previously reserved fee back to the daily budget it was reserved in (day_YYYYMMDD)
+// and the catalog budget (e.g. when transaction failed to be sent)
+func (bs *BudgetService) Release(catalogId string, dayID string, fee *big.Int) {
+	bs.Reconcile(catalogId, dayID, fee, big.NewInt(0))
+}
+
+// Reconcile replaces the reserved fee (worst case of the gas limit) with the fee paid by the mined transaction
+// in the daily budget it was reserved in (day_YYYYMMDD) and the catalog budget
+func (bs *BudgetService) Reconcile(catalogId string, dayID string, reserved *big.Int, paid *big.Int) {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+
+	refund := new(big.Int).Sub(reserved, paid)
+	for _, id := range []string{dayID, catalogSpentID(catalogId)} {
+		spent, err := bs.getSpent(id)
+		if err != nil {
+			continue
+		}
+		spent.Sub(spent, refund)
+		if spent.Sign() < 0 {
+			spent.SetInt64(0)
+		}
+		bs.putSpent(id, spent)
+	}
+}
+
+// catalogLimit returns catalogs own limit or the default catalog limit
+func (bs *BudgetService) catalogLimit(catalogId string, settings *model.BudgetSettings) (string, error) {
+	budget, err := bs.GetCatalogBudget(catalogId)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return settings.DefaultCatalogLimit, nil
+		}
+		return "", err
+	}
+	return budget.Limit, nil
+}
+
+// notifyExceeded notifies admins only once per budget per day
+func (bs *BudgetService) notifyExceeded(budgetID string, now time.Time, message string) {
+	lc.Log.Warn("budget exceeded", budgetID)
+	key := budgetID + "_" + now.UTC().Format("20060102")
+	if bs.notified[key] {
+		return
+	}
+	bs.notified[key] = true
+	bs.notificationService.Notify("Mailio NFT bridge budget exceeded", message)
+}
+
+func (bs *BudgetService) getSpent(id string) (*big.Int, error) {
+	var spent model.BudgetSpent
+	err := bs.get(util.CreateKey(model.BudgetSpentTable, id), &spent)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return big.NewInt(0), nil
+		}
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(spent.Spent, 10)
+	if !ok {
+		return big.NewInt(0), nil
+	}
+	return amount, nil
+}
+
+func (bs *BudgetService) putSpent(id string, amount *big.Int) error {
+	err := bs.put(util.CreateKey(model.BudgetSpentTable, id), &model.BudgetSpent{
+		ID:       id,
+		Spent:    amount.String(),
+		Modified: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		lc.Log.Error("failed to store budget spending", err)
+	}
+	return err
+}
+
+func (bs *BudgetService) get(key datastore.Key, out interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+	m, err := bs.environment.DB.Get(ctx, key)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrNotFound
+		}
+		lc.Log.Error("failed to get budget", err)
+		return err
+	}
+	budgetMap, err := util.UnmarshalFromBytes(m)
+	if err != nil {
+		lc.Log.Error("failed to unmarshal budget", err)
+		return err
+	}
+	return mapstructure.Decode(budgetMap, out)
+}
+
+func (bs *BudgetService) put(key datastore.Key, obj interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+	m, err := util.MarshalToBytes(obj)
+	if err != nil {
+		return err
+	}
+	return bs.environment.DB.Put(ctx, key, m)
+}
+
+func dailySpentID(t time.Time) string {
+	return "day_" + t.UTC().Format("20060102")
+}
+
+func catalogSpentID(catalogId string) string {
+	return "catalog_" + catalogId
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ipfs/go-datastore/query"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mitchellh/mapstructure"
+)
+
+// timeout for sending of contract administration transactions (including gas estimation)
+const contractAdminTimeout = 30 * time.Second
+
+type ContractAdminService struct {
+	environment *model.Environment
+}
+
+func NewContractAdminService(environment *model.Environment) *ContractAdminService {
+	return &ContractAdminService{
+		environment: environment,
+	}
+}
+
+// GetStatus returns paused state of the contract and roles of the broker and admin wallets
+func (cas *ContractAdminService) GetStatus() (*model.ContractStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	paused, err := cas.environment.NftContract.Paused(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		lc.Log.Error("failed to read paused state of the contract", err)
+		return nil, err
+	}
+	status := &model.ContractStatus{
+		Address: lc.Conf.BlockchainConfig.MailioNFTProxyAddress,
+		Paused:  paused,
+		Roles:   []*model.RoleMembership{},
+	}
+	accounts := []string{}
+	for _, key := range []string{lc.Conf.BlockchainConfig.MailioNFTBrokerPrivateKey, lc.Conf.BlockchainConfig.MailioNFTAdminPrivateKey} {
+		if key == "" {
+			continue
+		}
+		_, address, err := parsePrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, address.Hex())
+	}
+	for _, account := range accounts {
+		for _, role := range []string{model.MinterRole, model.PauserRole} {
+			membership, err := cas.HasRole(role, account)
+			if err != nil {
+				return nil, err
+			}
+			status.Roles = append(status.Roles, membership)
+		}
+	}
+	return status, nil
+}
+
+// HasRole checks if account has the contract role (MINTER_ROLE or PAUSER_ROLE)
+func (cas *ContractAdminService) HasRole(role string, account string) (*model.RoleMembership, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	roleHash, err := cas.roleHash(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	hasRole, err := cas.environment.NftContract.HasRole(&bind.CallOpts{Context: ctx}, roleHash, common.HexToAddress(account))
+	if err != nil {
+		lc.Log.Error("failed to check contract role", role, account, err)
+		return nil, err
+	}
+	return &model.RoleMembership{
+		Role:    role,
+		Account: common.HexToAddress(account).Hex(),
+		HasRole: hasRole,
+	}, nil
+}
+
+// Pause pauses the contract (no minting or transfers possible). Admin wallet requires PAUSER_ROLE
+func (cas *ContractAdminService) Pause() (*model.ContractAdminTx, error) {
+	return cas.pauserTransaction(model.ContractActionPause, cas.environment.NftContract.Pause)
+}
+
+// Unpause unpauses the contract. Admin wallet requires PAUSER_ROLE
+func (cas *ContractAdminService) Unpause() (*model.ContractAdminTx, error) {
+	return cas.pauserTransaction(model.ContractActionUnpause, cas.environment.NftContract.Unpause)
+}
+
+// GrantRole grants the role to the account. Admin wallet requires the admin role of the granted role
+func (cas *ContractAdminService) GrantRole(input *model.RoleChangeInput) (*model.ContractAdminTx, error) {
+	return cas.roleTransaction(model.ContractActionGrantRole, input, cas.environment.NftContract.GrantRole)
+}
+
+// RevokeRole revokes the role from the account. Admin wallet requires the admin role of the revoked role
+func (cas *ContractAdminService) RevokeRole(input *model.RoleChangeInput) (*model.ContractAdminTx, error) {
+	return cas.roleTransaction(model.ContractActionRevokeRole, input, cas.environment.NftContract.RevokeRole)
+}
+
+// ListTransactions lists transactions sent by the admin wallet (latest first)
+func (cas *ContractAdminService) ListTransactions(limit int) ([]*model.ContractAdminTx, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	q := query.Query{
+		Limit:  limit,
+		Prefix: "/" + model.ContractAdminTxTable,
+		Orders: []query.Order{query.OrderByKeyDescending{}},
+	}
+	qRes, err := cas.environment.DB.Query(ctx, q)
+	if err != nil {
+		lc.Log.Error("failed to list contract admin transactions", err)
+		return nil, err
+	}
+	defer qRes.Close()
+
+	txs := []*model.ContractAdminTx{}
+	res, err := qRes.Rest()
+	if err != nil {
+		lc.Log.Error("failed to list contract admin transactions", err)
+		return nil, err
+	}
+	for _, r := range res {
+		txMap, err := util.UnmarshalFromBytes(r.Value)
+		if err != nil {
+			lc.Log.Error("failed to unmarshal contract admin transaction", err)
+			return nil, err
+		}
+		var tx model.ContractAdminTx
+		mapstructure.Decode(txMap, &tx)
+		txs = append(txs, &tx)
+	}
+	return txs, nil
+}
+
+func (cas *ContractAdminService) pauserTransaction(action string, send func(opts *bind.TransactOpts) (*types.Transaction, error)) (*model.ContractAdminTx, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), contractAdminTimeout)
+	defer cancel()
+
+	auth, err := cas.adminTransactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cas.requireRole(ctx, model.PauserRole, auth.From); err != nil {
+		return nil, err
+	}
+	tx, err := send(auth)
+	if err != nil {
+		lc.Log.Error("failed to send contract transaction", action, err)
+		return nil, err
+	}
+	return cas.storeTransaction(&model.ContractAdminTx{
+		TxHash: tx.Hash().Hex(),
+		Action: action,
+		From:   auth.From.Hex(),
+	})
+}
+
+func (cas *ContractAdminService) roleTransaction(action string, input *model.RoleChangeInput, send func(opts *bind.TransactOpts, role [32]byte, account common.Address) (*types.Transaction, error)) (*model.ContractAdminTx, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), contractAdminTimeout)
+	defer cancel()
+
+	auth, err := cas.adminTransactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roleHash, err := cas.roleHash(ctx, input.Role)
+	if err != nil {
+		return nil, err
+	}
+	// only members of the roles admin role can grant or revoke the role
+	adminRole, err := cas.environment.NftContract.GetRoleAdmin(&bind.CallOpts{Context: ctx}, roleHash)
+	if err != nil {
+		lc.Log.Error("failed to get role admin", input.Role, err)
+		return nil, err
+	}
+	isAdmin, err := cas.environment.NftContract.HasRole(&bind.CallOpts{Context: ctx}, adminRole, auth.From)
+	if err != nil {
+		lc.Log.Error("failed to check contract role", err)
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, model.ErrMissingRole
+	}
+	account := common.HexToAddress(input.Account)
+	tx, err := send(auth, roleHash, account)
+	if err != nil {
+		lc.Log.Error("failed to send contract transaction", action, err)
+		return nil, err
+	}
+	return cas.storeTransaction(&model.ContractAdminTx{
+		TxHash:  tx.Hash().Hex(),
+		Action:  action,
+		Role:    input.Role,
+		Account: account.Hex(),
+		From:    auth.From.Hex(),
+	})
+}
+
+func (cas *ContractAdminService) adminTransactOpts(ctx context.Context) (*bind.TransactOpts, error) {
+	if lc.Conf.BlockchainConfig.MailioNFTAdminPrivateKey == "" {
+		return nil, errors.New("admin private key not configured")
+	}
+	return newTransactOpts(ctx, cas.environment.EthClient, lc.Conf.BlockchainConfig.MailioNFTAdminPrivateKey)
+}
+
+// requireRole returns model.ErrMissingRole if account doesn't have the role
+func (cas *ContractAdminService) requireRole(ctx context.Context, role string, account common.Address) error {
+	roleHash, err := cas.roleHash(ctx, role)
+	if err != nil {
+		return err
+	}
+	hasRole, err := cas.environment.NftContract.HasRole(&bind.CallOpts{Context: ctx}, roleHash, account)
+	if err != nil {
+		lc.Log.Error("failed to check contract role", role, err)
+		return err
+	}
+	if !hasRole {
+		return model.ErrMissingRole
+	}
+	return nil
+}
+
+// roleHash reads the role constant from the contract
+func (cas *ContractAdminService) roleHash(ctx context.Context, role string) ([32]byte, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	var (
+		hash [32]byte
+		err  error
+	)
+	switch role {
+	case model.MinterRole:
+		hash, err = cas.environment.NftContract.MINTERROLE(opts)
+	case model.PauserRole:
+		hash, err = cas.environment.NftContract.PAUSERROLE(opts)
+	default:
+		return hash, fmt.Errorf("unsupported role %s", role)
+	}
+	if err != nil {
+		lc.Log.Error("failed to read role from contract", role, err)
+	}
+	return hash, err
+}
+
+func (cas *ContractAdminService) storeTransaction(adminTx *model.ContractAdminTx) (*model.ContractAdminTx, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	adminTx.Created = time.Now().UnixMilli()
+	m, err := util.MarshalToBytes(adminTx)
+	if err != nil {
+		return nil, err
+	}
+	// key starts with created timestamp for listing latest transactions first
+	id := fmt.Sprintf("%d_%s", adminTx.Created, adminTx.TxHash)
+	err = cas.environment.DB.Put(ctx, util.CreateKey(model.ContractAdminTxTable, id), m)
+	if err != nil {
+		// transaction has already been sent, so only the tracking is lost
+		lc.Log.Error("failed to store contract admin transaction", adminTx.TxHash, err)
+	}
+	lc.Log.Info("sent contract admin transaction", adminTx.Action, adminTx.TxHash)
+	return adminTx, nil
+}
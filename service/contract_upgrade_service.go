@@ -0,0 +1,426 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/onchain"
+	nft "github.com/mailio/mailio-nft-server/onchain/mailionft"
+	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mitchellh/mapstructure"
+)
+
+// timeout for sending the upgrade transaction and waiting for it to be mined
+const contractUpgradeTimeout = 3 * time.Minute
+
+// ERC-1967 implementation slot (keccak256("eip1967.proxy.implementation") - 1), also the expected proxiableUUID of UUPS implementations
+var erc1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+type ContractUpgradeService struct {
+	environment          *model.Environment
+	contractAdminService *ContractAdminService
+	mailioNftContractAbi *abi.ABI
+}
+
+func NewContractUpgradeService(environment *model.Environment, contractAdminService *ContractAdminService) *ContractUpgradeService {
+	loadedAbi, err := onchain.ReadMailioNftContractAbi()
+	if err != nil {
+		lc.Log.Error("failed to read embedded MailioNftContractAbi", err)
+		panic("failed to read embedded MailioNftContractAbi")
+	}
+	mailioNftAbi, err := abi.JSON(bytes.NewReader(loadedAbi))
+	if err != nil {
+		lc.Log.Error("failed to load MailioNftContractAbi", err)
+		panic("failed to load MailioNftContractAbi")
+	}
+	return &ContractUpgradeService{
+		environment:          environment,
+		contractAdminService: contractAdminService,
+		mailioNftContractAbi: &mailioNftAbi,
+	}
+}
+
+// Check runs all pre-upgrade checks against the new implementation:
+// proxiableUUID, ABI compatibility with the embedded MailioNFT ABI (ABI of the new implementation is required)
+// and simulation of the upgrade transaction
+func (cus *ContractUpgradeService) Check(input *model.ContractUpgradeInput) (*model.ContractUpgradeCheck, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	implementation := common.HexToAddress(input.Implementation)
+	check := &model.ContractUpgradeCheck{
+		Implementation: implementation.Hex(),
+		MissingMethods: []string{},
+		Warnings:       []string{},
+		Errors:         []string{},
+	}
+
+	current, err := cus.readImplementation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	check.CurrentImplementation = current.Hex()
+	if current == implementation {
+		check.Errors = append(check.Errors, "new implementation is already the current implementation")
+	}
+
+	code, err := cus.environment.EthClient.CodeAt(ctx, implementation, nil)
+	if err != nil {
+		lc.Log.Error("failed to read implementation bytecode", err)
+		return nil, err
+	}
+	if len(code) == 0 {
+		check.Errors = append(check.Errors, "no contract deployed at "+implementation.Hex())
+		return check, nil
+	}
+
+	// UUPS implementation must point to the ERC-1967 implementation slot
+	implContract, err := nft.NewMailionft(implementation, cus.environment.EthClient)
+	if err != nil {
+		return nil, err
+	}
+	uuid, err := implContract.ProxiableUUID(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		check.Errors = append(check.Errors, "proxiableUUID call failed: "+err.Error())
+	} else {
+		check.ProxiableUUID = common.Hash(uuid).Hex()
+		if common.Hash(uuid) != erc1967ImplementationSlot {
+			check.Errors = append(check.Errors, "proxiableUUID does not match the ERC-1967 implementation slot")
+		}
+	}
+
+	// ABI compatibility is required, methods and events of the embedded ABI missing in the new ABI block
+	// the upgrade. Selectors missing in the bytecode are a heuristic (selectors dispatched through jump
+	// tables or other contracts aren't found), so they're only warnings
+	check.Warnings = append(check.Warnings, cus.missingInBytecode(code)...)
+	if input.Abi == "" {
+		check.Errors = append(check.Errors, "ABI of the new implementation is required for the compatibility check")
+	} else {
+		newAbi, err := abi.JSON(strings.NewReader(input.Abi))
+		if err != nil {
+			check.Errors = append(check.Errors, "invalid ABI: "+err.Error())
+		} else {
+			check.AbiChecked = true
+			check.MissingMethods = append(check.MissingMethods, abiIncompatibilities(cus.mailioNftContractAbi, &newAbi)...)
+		}
+	}
+	if len(check.MissingMethods) > 0 {
+		check.Errors = append(check.Errors, "new implementation is not compatible with the embedded MailioNFT ABI")
+	}
+
+	// simulate the upgrade as the admin wallet
+	if lc.Conf.BlockchainConfig.MailioNFTAdminPrivateKey == "" {
+		check.Errors = append(check.Errors, "admin private key not configured")
+	} else {
+		_, admin, err := parsePrivateKey(lc.Conf.BlockchainConfig.MailioNFTAdminPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		data, err := cus.upgradeCallData(implementation, input.CallData)
+		if err != nil {
+			check.Errors = append(check.Errors, err.Error())
+		} else {
+			proxy := common.HexToAddress(lc.Conf.BlockchainConfig.MailioNFTProxyAddress)
+			msg := ethereum.CallMsg{From: admin, To: &proxy, Data: data}
+			if _, err := cus.environment.EthClient.CallContract(ctx, msg, nil); err != nil {
+				check.Errors = append(check.Errors, "upgrade simulation failed: "+err.Error())
+			} else if gas, err := cus.environment.EthClient.EstimateGas(ctx, msg); err != nil {
+				check.Errors = append(check.Errors, "upgrade gas estimation failed: "+err.Error())
+			} else {
+				check.EstimatedGas = gas
+			}
+		}
+	}
+
+	check.Ok = len(check.Errors) == 0
+	return check, nil
+}
+
+// Upgrade checks the new implementation and upgrades the proxy to it. Waits for the transaction to be mined
+// and records the Upgraded event. Returns model.ErrUpgradeCheck (together with the check) if any of the checks failed
+func (cus *ContractUpgradeService) Upgrade(input *model.ContractUpgradeInput) (*model.ContractUpgrade, *model.ContractUpgradeCheck, error) {
+	check, err := cus.Check(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !check.Ok {
+		return nil, check, model.ErrUpgradeCheck
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), contractUpgradeTimeout)
+	defer cancel()
+
+	auth, err := cus.contractAdminService.adminTransactOpts(ctx)
+	if err != nil {
+		return nil, check, err
+	}
+	implementation := common.HexToAddress(input.Implementation)
+	var tx *types.Transaction
+	if input.CallData != "" {
+		data, dErr := hexutil.Decode(input.CallData)
+		if dErr != nil {
+			return nil, check, dErr
+		}
+		tx, err = cus.environment.NftContract.UpgradeToAndCall(auth, implementation, data)
+	} else {
+		tx, err = cus.environment.NftContract.UpgradeTo(auth, implementation)
+	}
+	if err != nil {
+		lc.Log.Error("failed to send upgrade transaction", err)
+		return nil, check, err
+	}
+	cus.contractAdminService.storeTransaction(&model.ContractAdminTx{
+		TxHash:  tx.Hash().Hex(),
+		Action:  model.ContractActionUpgrade,
+		Account: implementation.Hex(),
+		From:    auth.From.Hex(),
+	})
+
+	receipt, err := bind.WaitMined(ctx, cus.environment.EthClient, tx)
+	if err != nil {
+		lc.Log.Error("failed waiting for upgrade transaction to be mined", tx.Hash().Hex(), err)
+		return nil, check, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		lc.Log.Error("upgrade transaction failed", tx.Hash().Hex())
+		return nil, check, errors.New("upgrade transaction failed: " + tx.Hash().Hex())
+	}
+
+	upgrade := &model.ContractUpgrade{
+		TxHash:                 tx.Hash().Hex(),
+		BlockNumber:            receipt.BlockNumber.Uint64(),
+		PreviousImplementation: check.CurrentImplementation,
+		From:                   auth.From.Hex(),
+		Created:                time.Now().UnixMilli(),
+	}
+	proxy := common.HexToAddress(lc.Conf.BlockchainConfig.MailioNFTProxyAddress)
+	upgradedEventID := cus.mailioNftContractAbi.Events["Upgraded"].ID
+	for _, l := range receipt.Logs {
+		if l.Address != proxy || len(l.Topics) == 0 || l.Topics[0] != upgradedEventID {
+			continue
+		}
+		event, pErr := cus.environment.NftContract.ParseUpgraded(*l)
+		if pErr != nil {
+			lc.Log.Error("failed to parse Upgraded event", pErr)
+			continue
+		}
+		upgrade.Implementation = event.Implementation.Hex()
+	}
+	if upgrade.Implementation == "" {
+		lc.Log.Error("Upgraded event not found in transaction", tx.Hash().Hex())
+		return nil, check, errors.New("Upgraded event not found in transaction " + tx.Hash().Hex())
+	}
+
+	if err := cus.put(util.CreateKey(model.ContractUpgradeTable, fmt.Sprintf("%d_%s", upgrade.Created, upgrade.TxHash)), upgrade); err != nil {
+		lc.Log.Error("failed to store contract upgrade", err)
+		return upgrade, check, err
+	}
+	implementationSetting := &model.ContractImplementation{
+		Address:  upgrade.Implementation,
+		TxHash:   upgrade.TxHash,
+		Modified: time.Now().UnixMilli(),
+	}
+	if err := cus.put(util.CreateKey(model.ContractSettingTable, model.ContractImplementationID), implementationSetting); err != nil {
+		lc.Log.Error("failed to store contract implementation", err)
+		return upgrade, check, err
+	}
+	lc.Log.Info("upgraded Mailio NFT contract", upgrade.PreviousImplementation, "->", upgrade.Implementation)
+	return upgrade, check, nil
+}
+
+// GetImplementation returns the stored implementation address, or the one read from the proxy if no upgrade was recorded yet
+func (cus *ContractUpgradeService) GetImplementation() (*model.ContractImplementation, error) {
+	impl, err := cus.getStoredImplementation()
+	if err != nil {
+		if err != model.ErrNotFound {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+		defer cancel()
+		current, rErr := cus.readImplementation(ctx)
+		if rErr != nil {
+			return nil, rErr
+		}
+		return &model.ContractImplementation{Address: current.Hex()}, nil
+	}
+	return impl, nil
+}
+
+// getStoredImplementation returns implementation stored by the last upgrade or model.ErrNotFound
+func (cus *ContractUpgradeService) getStoredImplementation() (*model.ContractImplementation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	m, err := cus.environment.DB.Get(ctx, util.CreateKey(model.ContractSettingTable, model.ContractImplementationID))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrNotFound
+		}
+		lc.Log.Error("failed to get contract implementation", err)
+		return nil, err
+	}
+	implMap, err := util.UnmarshalFromBytes(m)
+	if err != nil {
+		lc.Log.Error("failed to unmarshal contract implementation", err)
+		return nil, err
+	}
+	var impl model.ContractImplementation
+	err = mapstructure.Decode(implMap, &impl)
+	return &impl, err
+}
+
+// ListUpgrades lists recorded proxy upgrades (latest first)
+func (cus *ContractUpgradeService) ListUpgrades(limit int) ([]*model.ContractUpgrade, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	q := query.Query{
+		Limit:  limit,
+		Prefix: "/" + model.ContractUpgradeTable,
+		Orders: []query.Order{query.OrderByKeyDescending{}},
+	}
+	qRes, err := cus.environment.DB.Query(ctx, q)
+	if err != nil {
+		lc.Log.Error("failed to list contract upgrades", err)
+		return nil, err
+	}
+	defer qRes.Close()
+
+	upgrades := []*model.ContractUpgrade{}
+	res, err := qRes.Rest()
+	if err != nil {
+		lc.Log.Error("failed to list contract upgrades", err)
+		return nil, err
+	}
+	for _, r := range res {
+		upMap, err := util.UnmarshalFromBytes(r.Value)
+		if err != nil {
+			lc.Log.Error("failed to unmarshal contract upgrade", err)
+			return nil, err
+		}
+		var upgrade model.ContractUpgrade
+		mapstructure.Decode(upMap, &upgrade)
+		upgrades = append(upgrades, &upgrade)
+	}
+	return upgrades, nil
+}
+
+// readImplementation reads current implementation address from the ERC-1967 slot of the proxy
+func (cus *ContractUpgradeService) readImplementation(ctx context.Context) (common.Address, error) {
+	proxy := common.HexToAddress(lc.Conf.BlockchainConfig.MailioNFTProxyAddress)
+	slot, err := cus.environment.EthClient.StorageAt(ctx, proxy, erc1967ImplementationSlot, nil)
+	if err != nil {
+		lc.Log.Error("failed to read implementation slot of the proxy", err)
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(slot), nil
+}
+
+// upgradeCallData packs upgradeTo or upgradeToAndCall (if callData is given)
+func (cus *ContractUpgradeService) upgradeCallData(implementation common.Address, callData string) ([]byte, error) {
+	if callData == "" {
+		return cus.mailioNftContractAbi.Pack("upgradeTo", implementation)
+	}
+	data, err := hexutil.Decode(callData)
+	if err != nil {
+		return nil, errors.New("invalid call data: " + err.Error())
+	}
+	return cus.mailioNftContractAbi.Pack("upgradeToAndCall", implementation, data)
+}
+
+// missingInBytecode returns methods and events of the embedded ABI whose selectors (topics) can't be found in the bytecode.
+// Solidity dispatcher usually pushes method selectors (PUSH1-PUSH4) and event topics (PUSH32) on stack
+func (cus *ContractUpgradeService) missingInBytecode(code []byte) []string {
+	missing := []string{}
+	for _, method := range cus.mailioNftContractAbi.Methods {
+		selector := bytes.TrimLeft(method.ID, "\x00")
+		push := append([]byte{byte(0x5f + len(selector))}, selector...)
+		if !bytes.Contains(code, push) {
+			missing = append(missing, "function "+method.Sig+" (not in bytecode)")
+		}
+	}
+	for _, event := range cus.mailioNftContractAbi.Events {
+		if event.Anonymous {
+			continue
+		}
+		push := append([]byte{0x7f}, event.ID.Bytes()...)
+		if !bytes.Contains(code, push) {
+			missing = append(missing, "event "+event.Sig+" (not in bytecode)")
+		}
+	}
+	return missing
+}
+
+// abiIncompatibilities compares methods and events of the current ABI with the new one
+func abiIncompatibilities(current *abi.ABI, next *abi.ABI) []string {
+	missing := []string{}
+	nextMethods := map[string]abi.Method{}
+	for _, m := range next.Methods {
+		nextMethods[m.Sig] = m
+	}
+	for _, m := range current.Methods {
+		nm, ok := nextMethods[m.Sig]
+		if !ok {
+			missing = append(missing, "function "+m.Sig)
+			continue
+		}
+		if argumentTypes(m.Outputs) != argumentTypes(nm.Outputs) {
+			missing = append(missing, "function "+m.Sig+" (outputs changed)")
+		}
+	}
+	nextEvents := map[string]abi.Event{}
+	for _, e := range next.Events {
+		nextEvents[e.Sig] = e
+	}
+	for _, e := range current.Events {
+		ne, ok := nextEvents[e.Sig]
+		if !ok {
+			missing = append(missing, "event "+e.Sig)
+			continue
+		}
+		if indexedArguments(e.Inputs) != indexedArguments(ne.Inputs) {
+			missing = append(missing, "event "+e.Sig+" (indexed arguments changed)")
+		}
+	}
+	return missing
+}
+
+func argumentTypes(args abi.Arguments) string {
+	types := []string{}
+	for _, a := range args {
+		types = append(types, a.Type.String())
+	}
+	return strings.Join(types, ",")
+}
+
+func indexedArguments(args abi.Arguments) string {
+	indexed := []string{}
+	for _, a := range args {
+		indexed = append(indexed, fmt.Sprintf("%t", a.Indexed))
+	}
+	return strings.Join(indexed, ",")
+}
+
+func (cus *ContractUpgradeService) put(key datastore.Key, obj interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+	m, err := util.MarshalToBytes(obj)
+	if err != nil {
+		return err
+	}
+	return cus.environment.DB.Put(ctx, key, m)
+}
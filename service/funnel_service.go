@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/rs/xid"
+)
+
+// timeout of computing a funnel report (and of pruning events)
+const funnelReportTimeout = time.Minute
+
+// how often events older than the retention are pruned
+const funnelPruneInterval = time.Hour
+
+// FunnelService records steps of claims (from requesting the signing payload to the confirmed mint)
+// and reports conversion rates. Events are ordered by time, so reports scan only events of their period.
+// Events older than funnel.retention days are pruned
+type FunnelService struct {
+	environment *model.Environment
+}
+
+func NewFunnelService(environment *model.Environment) *FunnelService {
+	return &FunnelService{
+		environment: environment,
+	}
+}
+
+// Passed records the claimant passing the step
+func (fs *FunnelService) Passed(step string, catalogId string, walletAddress string, visitorId string) {
+	fs.Record(&model.FunnelEvent{
+		Step:          step,
+		Passed:        true,
+		CatalogId:     catalogId,
+		WalletAddress: walletAddress,
+		VisitorId:     visitorId,
+	})
+}
+
+// Failed records the claimant failing the step for the reason (code)
+func (fs *FunnelService) Failed(step string, reason string, catalogId string, walletAddress string, visitorId string) {
+	fs.Record(&model.FunnelEvent{
+		Step:          step,
+		Reason:        reason,
+		CatalogId:     catalogId,
+		WalletAddress: walletAddress,
+		VisitorId:     visitorId,
+	})
+}
+
+// RecordMint records the mint step of the outcome of minting (error returned once signature and keywords are checked)
+func (fs *FunnelService) RecordMint(claim *model.Claim, catalogId string, txHash string, err error) {
+	if err != nil {
+		reason := model.FunnelReasonMintError
+		switch {
+		case errors.Is(err, model.ErrExists):
+			reason = model.FunnelReasonAlreadyClaimed
+		case errors.Is(err, model.ErrBudget):
+			reason = model.FunnelReasonBudgetExceeded
+		case errors.Is(err, model.ErrSoldOut):
+			reason = model.FunnelReasonSoldOut
+		}
+		fs.Failed(model.FunnelMint, reason, catalogId, claim.WalletAddress, claim.VisitorId)
+		return
+	}
+	fs.Record(&model.FunnelEvent{
+		Step:          model.FunnelMint,
+		Passed:        true,
+		CatalogId:     catalogId,
+		WalletAddress: claim.WalletAddress,
+		VisitorId:     claim.VisitorId,
+		TxHash:        txHash,
+	})
+}
+
+// Record stores the event (created now unless set). Failures are logged only, claiming doesn't depend on analytics
+func (fs *FunnelService) Record(event *model.FunnelEvent) {
+	if event.Created == 0 {
+		event.Created = time.Now().UnixMilli()
+	}
+	event.ID = fmt.Sprintf("%013d_%s", event.Created, xid.New().String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	if err := fs.environment.FunnelEvents.Put(ctx, event); err != nil {
+		lc.Log.Error("failed to store funnel event", err)
+	}
+}
+
+// WatchRetention prunes events older than the retention every hour
+func (fs *FunnelService) WatchRetention() {
+	for {
+		if _, err := fs.Prune(); err != nil {
+			lc.Log.Error("failed to prune funnel events", err)
+		}
+		time.Sleep(funnelPruneInterval)
+	}
+}
+
+// Prune removes events older than the retention and returns number of removed events
+func (fs *FunnelService) Prune() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), funnelReportTimeout)
+	defer cancel()
+
+	before := time.Now().Add(-time.Duration(funnelConfig().Retention) * 24 * time.Hour).UnixMilli()
+	pruned, err := fs.environment.FunnelEvents.Prune(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	if pruned > 0 {
+		lc.Log.Info("pruned funnel events", pruned)
+	}
+	return pruned, nil
+}
+
+// Report computes conversion rates of events within [from, to) of the catalog (all catalogs if empty).
+// To defaults to now and from to 7 days before to. Returns model.ErrInvalidRange if the range is empty
+func (fs *FunnelService) Report(catalogId string, from int64, to int64) (*model.FunnelReport, error) {
+	if to == 0 {
+		to = time.Now().UnixMilli()
+	}
+	if from == 0 {
+		from = to - int64(7*24*time.Hour/time.Millisecond)
+	}
+	if from < 0 || from >= to {
+		return nil, model.ErrInvalidRange
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), funnelReportTimeout)
+	defer cancel()
+
+	events, err := fs.environment.FunnelEvents.List(ctx, catalogId, from, to)
+	if err != nil {
+		lc.Log.Error("failed to list funnel events", err)
+		return nil, err
+	}
+
+	total := newFunnelCounter()
+	catalogs := map[string]*funnelCounter{}
+	for _, event := range events {
+		total.add(event)
+		// events without a catalog (e.g. invalid claim bodies) count in the total only
+		if event.CatalogId == "" {
+			continue
+		}
+		counter, ok := catalogs[event.CatalogId]
+		if !ok {
+			counter = newFunnelCounter()
+			catalogs[event.CatalogId] = counter
+		}
+		counter.add(event)
+	}
+
+	report := &model.FunnelReport{
+		From:     from,
+		To:       to,
+		Total:    total.conversion(catalogId),
+		Catalogs: []*model.FunnelConversion{},
+	}
+	for id, counter := range catalogs {
+		report.Catalogs = append(report.Catalogs, counter.conversion(id))
+	}
+	sort.Slice(report.Catalogs, func(i, j int) bool {
+		return report.Catalogs[i].CatalogId < report.Catalogs[j].CatalogId
+	})
+	return report, nil
+}
+
+func funnelConfig() lc.FunnelSubConfig {
+	conf := lc.Conf.Funnel
+	if conf.Retention <= 0 {
+		conf.Retention = 90
+	}
+	return conf
+}
+
+// funnelCounter counts events per step
+type funnelCounter struct {
+	steps map[string]*model.FunnelStepReport
+}
+
+func newFunnelCounter() *funnelCounter {
+	steps := map[string]*model.FunnelStepReport{}
+	for _, step := range model.FunnelSteps {
+		steps[step] = &model.FunnelStepReport{Step: step}
+	}
+	return &funnelCounter{steps: steps}
+}
+
+func (fc *funnelCounter) add(event *model.FunnelEvent) {
+	step, ok := fc.steps[event.Step]
+	if !ok {
+		return
+	}
+	if event.Passed {
+		step.Passed++
+		return
+	}
+	step.Failed++
+	if step.Reasons == nil {
+		step.Reasons = map[string]int{}
+	}
+	step.Reasons[event.Reason]++
+}
+
+// conversion computes conversion rates of the counted steps
+func (fc *funnelCounter) conversion(catalogId string) *model.FunnelConversion {
+	conversion := &model.FunnelConversion{
+		CatalogId: catalogId,
+		Steps:     []*model.FunnelStepReport{},
+	}
+	first := fc.steps[model.FunnelSteps[0]].Passed
+	previous := first
+	for _, name := range model.FunnelSteps {
+		step := fc.steps[name]
+		step.Conversion = ratio(step.Passed, first)
+		step.StepConversion = ratio(step.Passed, previous)
+		previous = step.Passed
+		conversion.Steps = append(conversion.Steps, step)
+	}
+	conversion.Conversion = ratio(fc.steps[model.FunnelConfirmed].Passed, first)
+	return conversion
+}
+
+// ratio of part to whole (0 if whole is 0)
+func ratio(part int, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
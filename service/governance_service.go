@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mitchellh/mapstructure"
+)
+
+// max number of blocks scanned within a single log filter request (RPC providers limit the range)
+const governanceMaxBlockRange = uint64(2000)
+
+// timeout for scanning a single block range
+const governanceScanTimeout = time.Minute
+
+// default number of governance events listed
+const governanceEventsPageSize = 50
+
+type GovernanceService struct {
+	environment            *model.Environment
+	notificationService    *NotificationService
+	contractUpgradeService *ContractUpgradeService
+	roleNames              map[common.Hash]string
+	pollMutex              sync.Mutex // single poll at a time
+}
+
+func NewGovernanceService(environment *model.Environment, notificationService *NotificationService, contractUpgradeService *ContractUpgradeService) *GovernanceService {
+	roleNames := map[common.Hash]string{
+		{}: "DEFAULT_ADMIN_ROLE",
+	}
+	for _, role := range []string{model.MinterRole, model.PauserRole, "UPGRADER_ROLE"} {
+		roleNames[crypto.Keccak256Hash([]byte(role))] = role
+	}
+	return &GovernanceService{
+		environment:            environment,
+		notificationService:    notificationService,
+		contractUpgradeService: contractUpgradeService,
+		roleNames:              roleNames,
+	}
+}
+
+// Watch polls the contract for new governance events (blocking, run as goroutine)
+func (gs *GovernanceService) Watch() {
+	interval := lc.Conf.Governance.PollInterval
+	if interval <= 0 {
+		interval = 60
+	}
+	for {
+		if err := gs.Poll(); err != nil {
+			lc.Log.Error("failed to poll governance events", err)
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
+// Poll scans all blocks since the last scanned block and stores governance events found
+func (gs *GovernanceService) Poll() error {
+	gs.pollMutex.Lock()
+	defer gs.pollMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	latest, err := gs.environment.EthClient.BlockNumber(ctx)
+	cancel()
+	if err != nil {
+		lc.Log.Error("failed to read latest block number", err)
+		return err
+	}
+
+	lastBlock, err := gs.getLastBlock()
+	if err != nil && err != model.ErrNotFound {
+		return err
+	}
+	var from uint64
+	if err == model.ErrNotFound {
+		from = lc.Conf.Governance.StartBlock
+		if from == 0 {
+			from = latest
+		}
+	} else {
+		from = lastBlock.BlockNumber + 1
+	}
+
+	for from <= latest {
+		to := from + governanceMaxBlockRange - 1
+		if to > latest {
+			to = latest
+		}
+		events, err := gs.scan(from, to)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			gs.alert(event)
+			if err := gs.put(util.CreateKey(model.GovernanceEventTable, event.ID), event); err != nil {
+				lc.Log.Error("failed to store governance event", err)
+				return err
+			}
+		}
+		err = gs.put(util.CreateKey(model.ContractSettingTable, model.GovernanceLastBlockID), &model.GovernanceLastBlock{
+			BlockNumber: to,
+			Modified:    time.Now().UnixMilli(),
+		})
+		if err != nil {
+			lc.Log.Error("failed to store last governance block", err)
+			return err
+		}
+		from = to + 1
+	}
+	return nil
+}
+
+// ListEvents lists stored governance events (latest first), optionally filtered by event name (default page size if limit isn't positive)
+func (gs *GovernanceService) ListEvents(limit int, eventName string) ([]*model.GovernanceEvent, error) {
+	if limit <= 0 {
+		limit = governanceEventsPageSize
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	q := query.Query{
+		Prefix: "/" + model.GovernanceEventTable,
+		Orders: []query.Order{query.OrderByKeyDescending{}},
+	}
+	if eventName == "" {
+		q.Limit = limit
+	}
+	qRes, err := gs.environment.DB.Query(ctx, q)
+	if err != nil {
+		lc.Log.Error("failed to list governance events", err)
+		return nil, err
+	}
+	defer qRes.Close()
+
+	events := []*model.GovernanceEvent{}
+	for r := range qRes.Next() {
+		if r.Error != nil {
+			lc.Log.Error("failed to list governance events", r.Error)
+			return nil, r.Error
+		}
+		evMap, err := util.UnmarshalFromBytes(r.Value)
+		if err != nil {
+			lc.Log.Error("failed to unmarshal governance event", err)
+			return nil, err
+		}
+		var event model.GovernanceEvent
+		mapstructure.Decode(evMap, &event)
+		if eventName != "" && event.Event != eventName {
+			continue
+		}
+		events = append(events, &event)
+		if len(events) >= limit {
+			break
+		}
+	}
+	return events, nil
+}
+
+// scan filters all governance events within the block range (inclusive) ordered by block and log index
+func (gs *GovernanceService) scan(from uint64, to uint64) ([]*model.GovernanceEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), governanceScanTimeout)
+	defer cancel()
+
+	opts := &bind.FilterOpts{Start: from, End: &to, Context: ctx}
+	contract := gs.environment.NftContract
+	events := []*model.GovernanceEvent{}
+
+	granted, err := contract.FilterRoleGranted(opts, nil, nil, nil)
+	if err != nil {
+		lc.Log.Error("failed to filter RoleGranted events", err)
+		return nil, err
+	}
+	for granted.Next() {
+		event := newGovernanceEvent(model.GovernanceRoleGranted, granted.Event.Raw)
+		event.Role = gs.roleName(granted.Event.Role)
+		event.Account = granted.Event.Account.Hex()
+		event.Sender = granted.Event.Sender.Hex()
+		events = append(events, event)
+	}
+	if err := granted.Error(); err != nil {
+		return nil, err
+	}
+
+	revoked, err := contract.FilterRoleRevoked(opts, nil, nil, nil)
+	if err != nil {
+		lc.Log.Error("failed to filter RoleRevoked events", err)
+		return nil, err
+	}
+	for revoked.Next() {
+		event := newGovernanceEvent(model.GovernanceRoleRevoked, revoked.Event.Raw)
+		event.Role = gs.roleName(revoked.Event.Role)
+		event.Account = revoked.Event.Account.Hex()
+		event.Sender = revoked.Event.Sender.Hex()
+		events = append(events, event)
+	}
+	if err := revoked.Error(); err != nil {
+		return nil, err
+	}
+
+	adminChanged, err := contract.FilterAdminChanged(opts)
+	if err != nil {
+		lc.Log.Error("failed to filter AdminChanged events", err)
+		return nil, err
+	}
+	for adminChanged.Next() {
+		event := newGovernanceEvent(model.GovernanceAdminChanged, adminChanged.Event.Raw)
+		event.PreviousAdmin = adminChanged.Event.PreviousAdmin.Hex()
+		event.NewAdmin = adminChanged.Event.NewAdmin.Hex()
+		events = append(events, event)
+	}
+	if err := adminChanged.Error(); err != nil {
+		return nil, err
+	}
+
+	paused, err := contract.FilterPaused(opts)
+	if err != nil {
+		lc.Log.Error("failed to filter Paused events", err)
+		return nil, err
+	}
+	for paused.Next() {
+		event := newGovernanceEvent(model.GovernancePaused, paused.Event.Raw)
+		event.Account = paused.Event.Account.Hex()
+		events = append(events, event)
+	}
+	if err := paused.Error(); err != nil {
+		return nil, err
+	}
+
+	unpaused, err := contract.FilterUnpaused(opts)
+	if err != nil {
+		lc.Log.Error("failed to filter Unpaused events", err)
+		return nil, err
+	}
+	for unpaused.Next() {
+		event := newGovernanceEvent(model.GovernanceUnpaused, unpaused.Event.Raw)
+		event.Account = unpaused.Event.Account.Hex()
+		events = append(events, event)
+	}
+	if err := unpaused.Error(); err != nil {
+		return nil, err
+	}
+
+	upgraded, err := contract.FilterUpgraded(opts, nil)
+	if err != nil {
+		lc.Log.Error("failed to filter Upgraded events", err)
+		return nil, err
+	}
+	for upgraded.Next() {
+		event := newGovernanceEvent(model.GovernanceUpgraded, upgraded.Event.Raw)
+		event.Implementation = upgraded.Event.Implementation.Hex()
+		events = append(events, event)
+	}
+	if err := upgraded.Error(); err != nil {
+		return nil, err
+	}
+
+	// block timestamps (cached, most events share few blocks)
+	blockTimes := map[uint64]int64{}
+	for _, event := range events {
+		blockTime, ok := blockTimes[event.BlockNumber]
+		if !ok {
+			header, err := gs.environment.EthClient.HeaderByNumber(ctx, new(big.Int).SetUint64(event.BlockNumber))
+			if err != nil {
+				lc.Log.Error("failed to read block header", event.BlockNumber, err)
+				return nil, err
+			}
+			blockTime = int64(header.Time)
+			blockTimes[event.BlockNumber] = blockTime
+		}
+		event.BlockTime = blockTime
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
+
+// alert sets the alert reason and notifies admins if the event is unexpected
+func (gs *GovernanceService) alert(event *model.GovernanceEvent) {
+	known := gs.knownAddresses()
+	switch event.Event {
+	case model.GovernanceRoleGranted:
+		if !known[common.HexToAddress(event.Account)] {
+			event.Alert = fmt.Sprintf("%s granted to unknown account %s", event.Role, event.Account)
+		}
+	case model.GovernanceRoleRevoked:
+		if known[common.HexToAddress(event.Account)] {
+			event.Alert = fmt.Sprintf("%s revoked from known account %s", event.Role, event.Account)
+		}
+	case model.GovernanceAdminChanged:
+		event.Alert = fmt.Sprintf("proxy admin changed from %s to %s", event.PreviousAdmin, event.NewAdmin)
+	case model.GovernancePaused, model.GovernanceUnpaused:
+		if !known[common.HexToAddress(event.Account)] {
+			event.Alert = fmt.Sprintf("contract %s by unknown account %s", strings.ToLower(event.Event), event.Account)
+		}
+	case model.GovernanceUpgraded:
+		impl, err := gs.contractUpgradeService.getStoredImplementation()
+		if err != nil && err != model.ErrNotFound {
+			return
+		}
+		if err == model.ErrNotFound || common.HexToAddress(impl.Address) != common.HexToAddress(event.Implementation) {
+			event.Alert = fmt.Sprintf("contract upgraded to %s outside of the bridge upgrade workflow", event.Implementation)
+		}
+	}
+	if event.Alert != "" {
+		gs.notificationService.Notify("Unexpected Mailio NFT governance event", fmt.Sprintf("%s (block %d, tx %s)", event.Alert, event.BlockNumber, event.TxHash))
+	}
+}
+
+// knownAddresses are the configured known addresses together with broker and admin wallets
+func (gs *GovernanceService) knownAddresses() map[common.Address]bool {
+	known := map[common.Address]bool{}
+	for _, address := range lc.Conf.Governance.KnownAddresses {
+		known[common.HexToAddress(address)] = true
+	}
+	for _, key := range []string{lc.Conf.BlockchainConfig.MailioNFTBrokerPrivateKey, lc.Conf.BlockchainConfig.MailioNFTAdminPrivateKey} {
+		if key == "" {
+			continue
+		}
+		if _, address, err := parsePrivateKey(key); err == nil {
+			known[address] = true
+		}
+	}
+	return known
+}
+
+// roleName returns human readable role name or the role hash if role is not known
+func (gs *GovernanceService) roleName(role [32]byte) string {
+	if name, ok := gs.roleNames[common.Hash(role)]; ok {
+		return name
+	}
+	return common.Hash(role).Hex()
+}
+
+func (gs *GovernanceService) getLastBlock() (*model.GovernanceLastBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	m, err := gs.environment.DB.Get(ctx, util.CreateKey(model.ContractSettingTable, model.GovernanceLastBlockID))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrNotFound
+		}
+		lc.Log.Error("failed to get last governance block", err)
+		return nil, err
+	}
+	lbMap, err := util.UnmarshalFromBytes(m)
+	if err != nil {
+		lc.Log.Error("failed to unmarshal last governance block", err)
+		return nil, err
+	}
+	var lastBlock model.GovernanceLastBlock
+	err = mapstructure.Decode(lbMap, &lastBlock)
+	return &lastBlock, err
+}
+
+func (gs *GovernanceService) put(key datastore.Key, value interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	b, err := util.MarshalToBytes(value)
+	if err != nil {
+		return err
+	}
+	return gs.environment.DB.Put(ctx, key, b)
+}
+
+func newGovernanceEvent(name string, raw types.Log) *model.GovernanceEvent {
+	return &model.GovernanceEvent{
+		ID:          fmt.Sprintf("%012d_%06d_%06d", raw.BlockNumber, raw.TxIndex, raw.Index),
+		Event:       name,
+		BlockNumber: raw.BlockNumber,
+		TxHash:      raw.TxHash.Hex(),
+		LogIndex:    raw.Index,
+		Created:     time.Now().UnixMilli(),
+	}
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/go-playground/validator/v10"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+)
+
+// max number of catalogs in a single import file
+const catalogImportMaxRows = 1000
+
+// catalogCSVColumns are columns of CSV files. Values of catalogCSVJSONColumns are JSON encoded
+var catalogCSVColumns = []string{
+	"id", "externalRef", "name", "type", "description", "contentLink", "keywords", "videoLink", "imageLink", "imageFile",
+	"defaultLanguage", "metadataMode", "status", "publishAt", "translations", "video", "article", "podcast",
+	"podcastEpisode", "virtualEvent", "metadataTemplate",
+}
+
+var catalogCSVJSONColumns = map[string]bool{
+	"publishAt":        true,
+	"translations":     true,
+	"video":            true,
+	"article":          true,
+	"podcast":          true,
+	"podcastEpisode":   true,
+	"virtualEvent":     true,
+	"metadataTemplate": true,
+}
+
+type NftCatalogImportService struct {
+	catalogService *NftCatalogService
+	imagesService  *NftImagesService
+}
+
+func NewNftCatalogImportService(catalogService *NftCatalogService, imagesService *NftImagesService) *NftCatalogImportService {
+	return &NftCatalogImportService{
+		catalogService: catalogService,
+		imagesService:  imagesService,
+	}
+}
+
+// Import validates all catalogs of the file and upserts them unless it's a dry-run or any of them is invalid.
+// Catalogs are matched to existing catalogs by ID or external reference. Images uploaded with the import
+// are referenced by their file name (imageFile) and uploaded before any catalog is stored. Catalogs are stored
+// in order of the file until the first failure, so the import can be re-run: stored rows are reported and
+// unchanged catalogs are skipped. Returns error wrapping model.ErrInvalidImport if the file can't be read
+func (ns *NftCatalogImportService) Import(data []byte, format string, images map[string]*multipart.FileHeader, dryRun bool, author string) (*model.CatalogImportResult, error) {
+	rows, err := CatalogImportRows(data, format)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := ns.catalogService.ListAllCatalogs(0)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]*model.Catalog{}
+	byRef := map[string]*model.Catalog{}
+	for _, catalog := range existing {
+		byID[catalog.ID] = catalog
+		if catalog.ExternalRef != "" && byRef[catalog.ExternalRef] == nil {
+			byRef[catalog.ExternalRef] = catalog
+		}
+	}
+
+	result := &model.CatalogImportResult{
+		DryRun: dryRun,
+		Rows:   []*model.CatalogImportRow{},
+	}
+	items := make([]*model.CatalogImportItem, len(rows))
+	previous := make([]*model.Catalog, len(rows)) // stored catalogs of rows (nil for new catalogs)
+	seenIDs := map[string]int{}
+	seenRefs := map[string]int{}
+	for i, raw := range rows {
+		row := &model.CatalogImportRow{Row: i + 1}
+		result.Rows = append(result.Rows, row)
+		item := &model.CatalogImportItem{}
+		if err := json.Unmarshal(raw, item); err != nil {
+			row.Errors = append(row.Errors, "invalid catalog: "+err.Error())
+			result.Failed++
+			continue
+		}
+		items[i] = item
+		row.ID = item.ID
+		row.ExternalRef = item.ExternalRef
+		row.Name = item.Name
+
+		if item.ID != "" {
+			previous[i] = byID[item.ID]
+			if previous[i] == nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("catalog %s not found", item.ID))
+			} else if other := byRef[item.ExternalRef]; item.ExternalRef != "" && other != nil && other.ID != item.ID {
+				row.Errors = append(row.Errors, fmt.Sprintf("external reference %s belongs to catalog %s", item.ExternalRef, other.ID))
+			}
+		} else if item.ExternalRef != "" {
+			previous[i] = byRef[item.ExternalRef]
+			if previous[i] != nil {
+				item.ID = previous[i].ID
+				row.ID = previous[i].ID
+			}
+		}
+		if first, ok := seenIDs[item.ID]; ok && item.ID != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("catalog %s already imported by row %d", item.ID, first))
+		} else if item.ID != "" {
+			seenIDs[item.ID] = row.Row
+		}
+		if first, ok := seenRefs[item.ExternalRef]; ok && item.ExternalRef != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("external reference %s already imported by row %d", item.ExternalRef, first))
+		} else if item.ExternalRef != "" {
+			seenRefs[item.ExternalRef] = row.Row
+		}
+		if item.ImageFile != "" && images[filepath.Base(item.ImageFile)] == nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("image %s was not uploaded with the import", item.ImageFile))
+		}
+		if err := ns.catalogService.ValidateCatalog(&item.Catalog); err != nil {
+			row.Errors = append(row.Errors, validationErrors(err)...)
+		}
+		// same status checks as on write (on a copy, status is defaulted by the check)
+		candidate := item.Catalog
+		keepPublishAt(previous[i], &candidate)
+		if err := checkStatusTransition(previous[i], &candidate); err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		}
+
+		if len(row.Errors) > 0 {
+			result.Failed++
+			continue
+		}
+		row.Action = model.CatalogImportCreate
+		if previous[i] != nil {
+			row.Action = model.CatalogImportUpdate
+			// rows with images are compared once the image is uploaded
+			if item.ImageFile == "" && catalogUnchanged(previous[i], &item.Catalog) {
+				row.Action = model.CatalogImportUnchanged
+			}
+		}
+	}
+	if dryRun || result.Failed > 0 {
+		countActions(result)
+		return result, nil
+	}
+
+	// a failed upload leaves storage unchanged (uploaded images are only pinned)
+	uploaded := map[string]string{} // image file name -> CID
+	for i, item := range items {
+		if item.ImageFile == "" {
+			continue
+		}
+		name := filepath.Base(item.ImageFile)
+		if _, ok := uploaded[name]; !ok {
+			response, err := ns.imagesService.Upload(images[name], author)
+			if err != nil {
+				result.Rows[i].Errors = append(result.Rows[i].Errors, "failed to upload image: "+err.Error())
+				result.Failed++
+				countActions(result)
+				return result, nil
+			}
+			uploaded[name] = response[0].Hash
+		}
+		item.ImageLink = uploaded[name]
+	}
+
+	// catalogs are stored in order of the file, a re-run after a failure skips unchanged catalogs
+	for i, item := range items {
+		row := result.Rows[i]
+		if result.Failed > 0 {
+			row.Errors = append(row.Errors, "not imported, import stopped at a failed row")
+			continue
+		}
+		if previous[i] != nil && catalogUnchanged(previous[i], &item.Catalog) {
+			row.Action = model.CatalogImportUnchanged
+			row.Stored = true
+			continue
+		}
+		catalog, err := ns.catalogService.PutCatalog(&item.Catalog, author)
+		if err != nil {
+			lc.Log.Error("failed to import catalog", row.Row, err)
+			row.Errors = append(row.Errors, "failed to store catalog: "+err.Error())
+			result.Failed++
+			continue
+		}
+		row.ID = catalog.ID
+		row.Stored = true
+	}
+	result.Imported = result.Failed == 0
+	countActions(result)
+	return result, nil
+}
+
+// Export writes all catalogs (or catalogs in the status) in the import format
+func (ns *NftCatalogImportService) Export(w io.Writer, format string, status string) error {
+	catalogs, err := ns.catalogService.ListAllCatalogs(0)
+	if err != nil {
+		return err
+	}
+	records := []map[string]interface{}{}
+	for _, catalog := range catalogs {
+		if status != "" && catalog.LifecycleStatus() != status {
+			continue
+		}
+		fields, err := catalogFields(catalog)
+		if err != nil {
+			lc.Log.Error("failed to export catalog", catalog.ID, err)
+			return err
+		}
+		for name := range catalogUntrackedFields {
+			delete(fields, name)
+		}
+		records = append(records, fields)
+	}
+
+	switch format {
+	case model.CatalogFormatJSON, model.CatalogFormatYAML:
+		out, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		if format == model.CatalogFormatYAML {
+			if out, err = yaml.JSONToYAML(out); err != nil {
+				return err
+			}
+		}
+		_, err = w.Write(out)
+		return err
+	case model.CatalogFormatCSV:
+		writer := csv.NewWriter(w)
+		header := []string{}
+		for _, column := range catalogCSVColumns {
+			if column != "imageFile" {
+				header = append(header, column)
+			}
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		for _, fields := range records {
+			line := make([]string, len(header))
+			for i, column := range header {
+				value, ok := fields[column]
+				if !ok || value == nil {
+					continue
+				}
+				if s, isString := value.(string); isString {
+					line[i] = s
+					continue
+				}
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return err
+				}
+				line[i] = string(encoded)
+			}
+			if err := writer.Write(line); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return fmt.Errorf("%w: unsupported format %s", model.ErrInvalidImport, format)
+}
+
+// CatalogImportRows splits the import file (JSON or YAML list of catalogs, CSV with a header row)
+// into JSON encoded catalogs. Returns error wrapping model.ErrInvalidImport if the file can't be read
+func CatalogImportRows(data []byte, format string) ([]json.RawMessage, error) {
+	rows := []json.RawMessage{}
+	switch format {
+	case model.CatalogFormatJSON, model.CatalogFormatYAML:
+		if format == model.CatalogFormatYAML {
+			converted, err := yaml.YAMLToJSON(data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", model.ErrInvalidImport, err.Error())
+			}
+			data = converted
+		}
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("%w: expected a list of catalogs: %s", model.ErrInvalidImport, err.Error())
+		}
+	case model.CatalogFormatCSV:
+		reader := csv.NewReader(bytes.NewReader(data))
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", model.ErrInvalidImport, err.Error())
+		}
+		if len(records) == 0 {
+			return nil, fmt.Errorf("%w: missing CSV header", model.ErrInvalidImport)
+		}
+		header := records[0]
+		for _, column := range header {
+			known := false
+			for _, c := range catalogCSVColumns {
+				known = known || c == column
+			}
+			if !known {
+				return nil, fmt.Errorf("%w: unknown CSV column %s", model.ErrInvalidImport, column)
+			}
+		}
+		for _, record := range records[1:] {
+			fields := map[string]json.RawMessage{}
+			for i, value := range record {
+				if strings.TrimSpace(value) == "" {
+					continue
+				}
+				// invalid JSON values are kept as strings, so they're reported as invalid field of the row
+				if catalogCSVJSONColumns[header[i]] && json.Valid([]byte(value)) {
+					fields[header[i]] = json.RawMessage(value)
+					continue
+				}
+				encoded, _ := json.Marshal(value)
+				fields[header[i]] = encoded
+			}
+			row, err := json.Marshal(fields)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %s", model.ErrInvalidImport, format)
+	}
+	if len(rows) > catalogImportMaxRows {
+		return nil, fmt.Errorf("%w: more than %d catalogs", model.ErrInvalidImport, catalogImportMaxRows)
+	}
+	return rows, nil
+}
+
+// CatalogFormat returns import format by the file extension (empty if unknown)
+func CatalogFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return model.CatalogFormatJSON
+	case ".yaml", ".yml":
+		return model.CatalogFormatYAML
+	case ".csv":
+		return model.CatalogFormatCSV
+	}
+	return ""
+}
+
+// catalogUnchanged returns true if storing the catalog wouldn't change the stored catalog
+func catalogUnchanged(previous *model.Catalog, catalog *model.Catalog) bool {
+	// same defaults as on write
+	candidate := *catalog
+	keepPublishAt(previous, &candidate)
+	if candidate.Status == "" {
+		candidate.Status = previous.LifecycleStatus()
+	}
+	changes, err := catalogChanges(previous, &candidate)
+	return err == nil && len(changes) == 0
+}
+
+// countActions counts created, updated and unchanged catalogs of the import (stored rows unless it's a dry-run)
+func countActions(result *model.CatalogImportResult) {
+	result.Created, result.Updated, result.Unchanged = 0, 0, 0
+	for _, row := range result.Rows {
+		if !result.DryRun && !row.Stored {
+			continue
+		}
+		switch row.Action {
+		case model.CatalogImportCreate:
+			result.Created++
+		case model.CatalogImportUpdate:
+			result.Updated++
+		case model.CatalogImportUnchanged:
+			result.Unchanged++
+		}
+	}
+}
+
+// validationErrors splits struct validation errors into an error per field
+func validationErrors(err error) []string {
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []string{err.Error()}
+	}
+	messages := []string{}
+	for _, fe := range fieldErrors {
+		messages = append(messages, fmt.Sprint(fe))
+	}
+	return messages
+}
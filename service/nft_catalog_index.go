@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+)
+
+// catalogIndex is an in-memory search index of catalogs. It's loaded from storage on first search,
+// updated on every catalog write and claim and reloaded after the reload interval (writes of other replicas)
+type catalogIndex struct {
+	mutex    sync.RWMutex
+	loaded   time.Time // zero until loaded
+	catalogs map[string]*model.Catalog
+	terms    map[string]map[string]int // term of names and descriptions (all languages) -> catalog ID -> number of occurrences
+	claims   map[string]int            // catalog ID -> number of claims (popularity)
+}
+
+func newCatalogIndex() *catalogIndex {
+	return &catalogIndex{
+		catalogs: map[string]*model.Catalog{},
+		terms:    map[string]map[string]int{},
+		claims:   map[string]int{},
+	}
+}
+
+// load fills the index with all catalogs and claim counts unless loaded within the reload interval
+func (ci *catalogIndex) load(nc *NftCatalogService) error {
+	reloadInterval := time.Duration(searchConfig().ReloadInterval) * time.Second
+	ci.mutex.RLock()
+	fresh := !ci.loaded.IsZero() && time.Since(ci.loaded) < reloadInterval
+	ci.mutex.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+	if !ci.loaded.IsZero() && time.Since(ci.loaded) < reloadInterval {
+		// loaded by a concurrent search
+		return nil
+	}
+	loaded := time.Now()
+	catalogs, err := nc.ListAllCatalogs(0)
+	if err != nil {
+		return err
+	}
+	claims, err := nc.countClaims()
+	if err != nil {
+		return err
+	}
+	// catalogs are indexed from scratch (removed catalogs and stale terms are dropped)
+	ci.catalogs = map[string]*model.Catalog{}
+	ci.terms = map[string]map[string]int{}
+	for _, catalog := range catalogs {
+		ci.index(catalog)
+	}
+	ci.claims = claims
+	ci.loaded = loaded
+	return nil
+}
+
+// put adds or replaces the catalog in the index (ignored until index is loaded)
+func (ci *catalogIndex) put(catalog *model.Catalog) {
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+	if !ci.loaded.IsZero() {
+		ci.index(catalog)
+	}
+}
+
+// addClaim increases popularity of the catalog (ignored until index is loaded)
+func (ci *catalogIndex) addClaim(catalogId string) {
+	ci.mutex.Lock()
+	defer ci.mutex.Unlock()
+	if !ci.loaded.IsZero() {
+		ci.claims[catalogId]++
+	}
+}
+
+func (ci *catalogIndex) index(catalog *model.Catalog) {
+	if previous, ok := ci.catalogs[catalog.ID]; ok {
+		for _, term := range tokenize(catalogText(previous)) {
+			delete(ci.terms[term], previous.ID)
+			if len(ci.terms[term]) == 0 {
+				delete(ci.terms, term)
+			}
+		}
+	}
+	indexed := *catalog
+	ci.catalogs[catalog.ID] = &indexed
+	for _, term := range tokenize(catalogText(catalog)) {
+		if ci.terms[term] == nil {
+			ci.terms[term] = map[string]int{}
+		}
+		ci.terms[term][catalog.ID]++
+	}
+}
+
+// search returns a page of catalogs matching all filters. Every term of the text query has to match
+// a term (or its prefix) of the name or description. Facets ignore the type filter
+func (ci *catalogIndex) search(search *model.CatalogSearch) (*model.CatalogSearchResult, error) {
+	ci.mutex.RLock()
+	defer ci.mutex.RUnlock()
+
+	var scores map[string]int
+	queryTerms := tokenize(search.Query)
+	if len(queryTerms) > 0 {
+		scores = ci.textScores(queryTerms)
+	}
+	keyword := strings.ToLower(strings.TrimSpace(search.Keyword))
+
+	result := &model.CatalogSearchResult{
+		Catalogs: []*model.Catalog{},
+		Facets:   map[string]int{},
+	}
+	for id, catalog := range ci.catalogs {
+		if search.Status != "" && catalog.LifecycleStatus() != search.Status {
+			continue
+		}
+		if keyword != "" && !hasKeyword(catalogKeywords(catalog), keyword) {
+			continue
+		}
+		if scores != nil {
+			if _, ok := scores[id]; !ok {
+				continue
+			}
+		}
+		result.Facets[catalog.Type]++
+		if search.Type != "" && !strings.EqualFold(catalog.Type, search.Type) {
+			continue
+		}
+		found := *catalog
+		result.Catalogs = append(result.Catalogs, &found)
+	}
+	result.Total = len(result.Catalogs)
+
+	sortBy := search.Sort
+	if sortBy == "" {
+		sortBy = model.CatalogSortCreated
+		if scores != nil {
+			sortBy = model.CatalogSortRelevance
+		}
+	}
+	value := func(catalog *model.Catalog) int64 {
+		switch sortBy {
+		case model.CatalogSortRelevance:
+			return int64(scores[catalog.ID])
+		case model.CatalogSortModified:
+			return catalog.Modified
+		case model.CatalogSortPopularity:
+			return int64(ci.claims[catalog.ID])
+		}
+		return catalog.Created
+	}
+	// catalogs are ordered by sort value and ID (unique position of the cursor)
+	before := func(aValue int64, aId string, bValue int64, bId string) bool {
+		if aValue == bValue {
+			return aId > bId
+		}
+		if search.Order == "asc" {
+			return aValue < bValue
+		}
+		return aValue > bValue
+	}
+	sort.Slice(result.Catalogs, func(i, j int) bool {
+		a, b := result.Catalogs[i], result.Catalogs[j]
+		return before(value(a), a.ID, value(b), b.ID)
+	})
+
+	if search.Cursor != "" {
+		position, err := util.DecodeCursor(search.Cursor)
+		if err != nil {
+			return nil, model.ErrInvalidCursor
+		}
+		parts := strings.SplitN(position, ":", 2)
+		cursorValue, err := strconv.ParseInt(parts[0], 10, 64)
+		if len(parts) != 2 || err != nil {
+			return nil, model.ErrInvalidCursor
+		}
+		start := sort.Search(len(result.Catalogs), func(i int) bool {
+			return before(cursorValue, parts[1], value(result.Catalogs[i]), result.Catalogs[i].ID)
+		})
+		result.Catalogs = result.Catalogs[start:]
+	}
+	if search.Limit > 0 && len(result.Catalogs) > search.Limit {
+		result.Catalogs = result.Catalogs[:search.Limit]
+		last := result.Catalogs[search.Limit-1]
+		result.Next = util.EncodeCursor(strconv.FormatInt(value(last), 10) + ":" + last.ID)
+	}
+	return result, nil
+}
+
+// textScores returns catalogs matching all query terms with their relevance
+// (exact term matches count more than prefix matches)
+func (ci *catalogIndex) textScores(queryTerms []string) map[string]int {
+	var scores map[string]int
+	for _, queryTerm := range queryTerms {
+		termScores := map[string]int{}
+		for term, occurrences := range ci.terms {
+			if !strings.HasPrefix(term, queryTerm) {
+				continue
+			}
+			weight := 1
+			if term == queryTerm {
+				weight = 2
+			}
+			for id, count := range occurrences {
+				termScores[id] += weight * count
+			}
+		}
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id := range scores {
+			if _, ok := termScores[id]; !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += termScores[id]
+		}
+	}
+	return scores
+}
+
+func searchConfig() lc.SearchSubConfig {
+	conf := lc.Conf.Search
+	if conf.ReloadInterval <= 0 {
+		conf.ReloadInterval = 60
+	}
+	return conf
+}
+
+// countClaims returns number of claims per catalog ID
+func (nc *NftCatalogService) countClaims() (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	claims, err := nc.environment.Claims.CountByCatalog(ctx)
+	if err != nil {
+		lc.Log.Error("failed to count claims", err)
+		return nil, err
+	}
+	return claims, nil
+}
+
+// catalogText returns names and descriptions of the catalog in all languages
+func catalogText(catalog *model.Catalog) string {
+	text := catalog.Name + " " + catalog.Description
+	for _, translation := range catalog.Translations {
+		if translation != nil {
+			text += " " + translation.Name + " " + translation.Description
+		}
+	}
+	return text
+}
+
+// catalogKeywords returns comma separated keywords of the catalog in all languages
+func catalogKeywords(catalog *model.Catalog) string {
+	keywords := catalog.Keywords
+	for _, translation := range catalog.Translations {
+		if translation != nil && translation.Keywords != "" {
+			keywords += "," + translation.Keywords
+		}
+	}
+	return keywords
+}
+
+// tokenize splits text into lower case words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func hasKeyword(keywords string, keyword string) bool {
+	for _, k := range strings.Split(keywords, ",") {
+		if strings.ToLower(strings.TrimSpace(k)) == keyword {
+			return true
+		}
+	}
+	return false
+}
@@ -3,11 +3,16 @@ package service
 import (
 	"bytes"
 	"context"
-	"strconv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/go-playground/validator/v10"
 	"github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-datastore/query"
 	lc "github.com/mailio/mailio-nft-server/config"
@@ -15,12 +20,21 @@ import (
 	"github.com/mailio/mailio-nft-server/onchain"
 	"github.com/mailio/mailio-nft-server/util"
 	"github.com/mitchellh/mapstructure"
-	"github.com/rs/xid"
 )
 
+// how often scheduled catalogs are checked for publishing
+const publishInterval = time.Minute
+
+// author of revisions created by publishing scheduled catalogs
+const scheduledPublishAuthor = "scheduler"
+
 type NftCatalogService struct {
 	environment          *model.Environment
 	mailioNftContractAbi *abi.ABI
+	revisionMutex        sync.Mutex // serializes catalog writes (sequential revision numbers)
+	searchIndex          *catalogIndex
+	tokenCounts          *tokenCountCache
+	validate             *validator.Validate
 }
 
 func NewNftCatalog(environment *model.Environment) *NftCatalogService {
@@ -37,11 +51,21 @@ func NewNftCatalog(environment *model.Environment) *NftCatalogService {
 	return &NftCatalogService{
 		environment:          environment,
 		mailioNftContractAbi: &mailioNftAbi,
+		searchIndex:          newCatalogIndex(),
+		tokenCounts:          newTokenCountCache(),
+		validate:             validator.New(),
 	}
 }
 
-// PutCatalog usperts a new catalog (insert is exists, or update existing)
-func (nc *NftCatalogService) PutCatalog(catalog *model.Catalog) (*model.Catalog, error) {
+// PutCatalog usperts a new catalog (insert is exists, or update existing). Every write is stored
+// as a new revision of the catalog with author and changed fields
+func (nc *NftCatalogService) PutCatalog(catalog *model.Catalog, author string) (*model.Catalog, error) {
+	nc.revisionMutex.Lock()
+	defer nc.revisionMutex.Unlock()
+	return nc.putCatalog(catalog, author, 0)
+}
+
+func (nc *NftCatalogService) putCatalog(catalog *model.Catalog, author string, restoredFrom int) (*model.Catalog, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
 	id := util.GenerateRandomID()
@@ -50,87 +74,472 @@ func (nc *NftCatalogService) PutCatalog(catalog *model.Catalog) (*model.Catalog,
 	}
 	catalog.ID = id
 	catalog.Modified = time.Now().UnixMilli()
-	catalog.Created = time.Now().UnixMilli()
+	catalog.Created = catalog.Modified
+
+	previous, err := nc.getStoredCatalog(id)
+	if err != nil && err != model.ErrNotFound {
+		return nil, err
+	}
+	if previous != nil {
+		catalog.Created = previous.Created
+	}
+	keepPublishAt(previous, catalog)
+	if err := checkStatusTransition(previous, catalog); err != nil {
+		return nil, err
+	}
+	lastRevision, err := nc.lastRevision(id)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := catalogChanges(previous, catalog)
+	if err != nil {
+		lc.Log.Error("failed to diff catalog", id, err)
+		return nil, err
+	}
 
-	m, err := util.MarshalToBytes(catalog)
-	err = nc.environment.DB.Put(ctx, util.CreateKey(model.CatalogTable, id), m)
+	err = nc.environment.Catalogs.Put(ctx, catalog)
 	if err != nil {
 		lc.Log.Error("failed to create new catalog", err)
 		return nil, err
 	}
+	nc.searchIndex.put(catalog)
+	snapshot := *catalog
+	revision := &model.CatalogRevision{
+		CatalogId:    id,
+		Revision:     lastRevision + 1,
+		Author:       author,
+		Changes:      changes,
+		RestoredFrom: restoredFrom,
+		Catalog:      &snapshot,
+		Created:      catalog.Modified,
+	}
+	r, err := util.MarshalToBytes(revision)
+	if err != nil {
+		lc.Log.Error("failed to marshal catalog revision", id, err)
+		return nil, err
+	}
+	if err := nc.environment.DB.Put(ctx, revisionKey(id, revision.Revision), r); err != nil {
+		lc.Log.Error("failed to store catalog revision", id, err)
+		return nil, err
+	}
 	return catalog, nil
 }
 
+// ValidateCatalog checks the catalog before it's stored (fields, type details, translations and metadata template)
+func (nc *NftCatalogService) ValidateCatalog(catalog *model.Catalog) error {
+	if err := nc.validate.Struct(catalog); err != nil {
+		return err
+	}
+	if err := nc.ValidateTypeDetails(catalog); err != nil {
+		return err
+	}
+	if err := nc.ValidateTranslations(catalog); err != nil {
+		return err
+	}
+	if err := validateMetadataTemplate(catalog.MetadataTemplate); err != nil {
+		return fmt.Errorf("invalid metadata template: %w", err)
+	}
+	return nil
+}
+
+// ValidateTypeDetails checks that only type-specific details of the catalog type are set
+// (contents of the details are checked by the struct validator)
+func (nc *NftCatalogService) ValidateTypeDetails(catalog *model.Catalog) error {
+	details := map[string]bool{
+		model.CatalogTypeVideo:          catalog.Video != nil,
+		model.CatalogTypeArticle:        catalog.Article != nil,
+		model.CatalogTypePodcast:        catalog.Podcast != nil,
+		model.CatalogTypePodcastEpisode: catalog.PodcastEpisode != nil,
+		model.CatalogTypeVirtualEvent:   catalog.VirtualEvent != nil,
+	}
+	for catalogType, set := range details {
+		if set && catalogType != catalog.Type {
+			return fmt.Errorf("%s details are not allowed for %s catalogs", catalogType, catalog.Type)
+		}
+	}
+	return nil
+}
+
+// ValidateTranslations checks language tags of the default language and translations
+func (nc *NftCatalogService) ValidateTranslations(catalog *model.Catalog) error {
+	if catalog.DefaultLanguage != "" && !model.LanguageTagRegex.MatchString(catalog.DefaultLanguage) {
+		return fmt.Errorf("invalid default language %s", catalog.DefaultLanguage)
+	}
+	for lang := range catalog.Translations {
+		if !model.LanguageTagRegex.MatchString(lang) {
+			return fmt.Errorf("invalid translation language %s", lang)
+		}
+		if strings.EqualFold(lang, catalog.Language()) {
+			return fmt.Errorf("translation of the default language %s is not allowed", lang)
+		}
+	}
+	return nil
+}
+
 // GetCatalog returns a catalog from datastore by ID
 func (nc *NftCatalogService) GetCatalog(id string) (*model.Catalog, error) {
+	catalog, err := nc.getStoredCatalog(id)
+	if err != nil {
+		return nil, err
+	}
+	cat := *catalog
+
+	// number of claimed NFTs (cached, read from the contract only if missing)
+	cat.NftTokensUsed = nc.tokenCount(cat.ID)
+
+	return &cat, nil
+}
+
+// getStoredCatalog returns a catalog as stored in datastore (without number of claimed NFTs)
+func (nc *NftCatalogService) getStoredCatalog(id string) (*model.Catalog, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
-	key := util.CreateKey(model.CatalogTable, id)
-	m, err := nc.environment.DB.Get(ctx, key)
+	catalog, err := nc.environment.Catalogs.Get(ctx, id)
 	if err != nil {
-		if err == datastore.ErrNotFound {
-			return nil, model.ErrNotFound
+		if err != model.ErrNotFound {
+			lc.Log.Error("failed to get catalog", err)
 		}
-		lc.Log.Error("failed to get catalog", err)
 		return nil, err
 	}
-	catalogMap, err := util.UnmarshalFromBytes(m)
+	return catalog, nil
+}
+
+// ListAllCatalogs retruns all catalogs from datastore
+func (nc *NftCatalogService) ListAllCatalogs(limit int) ([]*model.Catalog, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	catalogs, err := nc.environment.Catalogs.List(ctx, limit)
 	if err != nil {
-		lc.Log.Error("failed to unmarshal catalog", err)
+		lc.Log.Error("failed to list all catalogs", err)
 		return nil, err
 	}
-	var cat model.Catalog
-	err = mapstructure.Decode(catalogMap, &cat)
+	for _, catalog := range catalogs {
+		catalog.NftTokensUsed = nc.cachedTokenCount(catalog.ID)
+	}
+	return catalogs, nil
+}
+
+// SetStatus changes status of the catalog. Returns model.ErrInvalidStatus if transition is not allowed
+func (nc *NftCatalogService) SetStatus(catalogId string, change *model.CatalogStatusChange, author string) (*model.Catalog, error) {
+	nc.revisionMutex.Lock()
+	defer nc.revisionMutex.Unlock()
 
-	// querying smart contract to get number of claimed NFTs
-	xidID, _ := xid.FromString(cat.ID)
-	var catId [12]byte
-	copy(catId[:], xidID.Bytes())
-	categoryCountBigInt, cErr := nc.environment.NftContract.CategoryTokenCount(&bind.CallOpts{}, catId)
-	if cErr != nil {
-		lc.Log.Error("failed to retrieve category count on blockchain", cErr)
-		// ignores the error (it will fail within contract if more than 100 claimed)
+	catalog, err := nc.getStoredCatalog(catalogId)
+	if err != nil {
+		return nil, err
 	}
-	tokensClaimed, convErr := strconv.Atoi(strconv.FormatUint(categoryCountBigInt.Uint64(), 10))
-	if convErr != nil {
-		lc.Log.Error("failed to convert category count to int", convErr)
-		// ignores the error (it will fail within contract if more than 100 claimed)
+	catalog.Status = change.Status
+	if change.PublishAt != 0 {
+		catalog.PublishAt = change.PublishAt
 	}
-	cat.NftTokensUsed = tokensClaimed
+	return nc.putCatalog(catalog, author, 0)
+}
 
-	return &cat, err
+// WatchScheduled periodically publishes scheduled catalogs
+func (nc *NftCatalogService) WatchScheduled() {
+	for {
+		if err := nc.PublishScheduled(); err != nil {
+			lc.Log.Error("failed to publish scheduled catalogs", err)
+		}
+		time.Sleep(publishInterval)
+	}
 }
 
-// ListAllCatalogs retruns all catalogs from datastore
-func (nc *NftCatalogService) ListAllCatalogs(limit int) ([]*model.Catalog, error) {
+// PublishScheduled publishes scheduled catalogs with publishAt in the past
+func (nc *NftCatalogService) PublishScheduled() error {
+	catalogs, err := nc.ListCatalogsByStatus(0, model.CatalogStatusScheduled)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	for _, catalog := range catalogs {
+		if catalog.PublishAt > now {
+			continue
+		}
+		if _, err := nc.SetStatus(catalog.ID, &model.CatalogStatusChange{Status: model.CatalogStatusPublished}, scheduledPublishAuthor); err != nil {
+			lc.Log.Error("failed to publish scheduled catalog", catalog.ID, err)
+			return err
+		}
+		lc.Log.Info("published scheduled catalog", catalog.ID)
+	}
+	return nil
+}
+
+// SearchCatalogs returns a page of catalogs filtered by type, keyword and full text, sorted and with number of
+// matching catalogs per type. Returns model.ErrInvalidCursor if cursor can't be decoded
+func (nc *NftCatalogService) SearchCatalogs(search *model.CatalogSearch) (*model.CatalogSearchResult, error) {
+	if err := nc.searchIndex.load(nc); err != nil {
+		lc.Log.Error("failed to load catalog search index", err)
+		return nil, err
+	}
+	result, err := nc.searchIndex.search(search)
+	if err != nil {
+		return nil, err
+	}
+	for _, catalog := range result.Catalogs {
+		catalog.NftTokensUsed = nc.cachedTokenCount(catalog.ID)
+	}
+	return result, nil
+}
 
+// ClaimRecorded increases popularity of the catalog within the search index
+func (nc *NftCatalogService) ClaimRecorded(catalogId string) {
+	nc.searchIndex.addClaim(catalogId)
+}
+
+// ListCatalogsByStatus returns catalogs with the status (all if empty) from datastore
+func (nc *NftCatalogService) ListCatalogsByStatus(limit int, status string) ([]*model.Catalog, error) {
+	catalogs, err := nc.ListAllCatalogs(0)
+	if err != nil {
+		return nil, err
+	}
+	filtered := []*model.Catalog{}
+	for _, catalog := range catalogs {
+		if status != "" && catalog.LifecycleStatus() != status {
+			continue
+		}
+		filtered = append(filtered, catalog)
+		if limit > 0 && len(filtered) >= limit {
+			break
+		}
+	}
+	return filtered, nil
+}
+
+// keepPublishAt keeps publishAt of the stored catalog if the update omits it
+func keepPublishAt(previous *model.Catalog, catalog *model.Catalog) {
+	if previous != nil && catalog.PublishAt == 0 {
+		catalog.PublishAt = previous.PublishAt
+	}
+}
+
+// checkStatusTransition sets status of the catalog (keeps previous or draft if not given) and checks
+// if transition from the previous status is allowed. Catalogs becoming scheduled (or rescheduled) require
+// publishAt in the future, updates of scheduled catalogs waiting for the publish watcher keep their publishAt
+func checkStatusTransition(previous *model.Catalog, catalog *model.Catalog) error {
+	from := model.CatalogStatusDraft
+	if previous != nil {
+		from = previous.LifecycleStatus()
+	}
+	if catalog.Status == "" {
+		catalog.Status = from
+	}
+	if catalog.Status != from {
+		allowed := false
+		for _, to := range model.CatalogStatusTransitions[from] {
+			allowed = allowed || to == catalog.Status
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s to %s", model.ErrInvalidStatus, from, catalog.Status)
+		}
+	}
+	rescheduled := catalog.Status != from || previous == nil || catalog.PublishAt != previous.PublishAt
+	if catalog.Status == model.CatalogStatusScheduled && rescheduled && catalog.PublishAt <= time.Now().UnixMilli() {
+		return fmt.Errorf("%w: scheduled catalog requires publishAt in the future", model.ErrInvalidStatus)
+	}
+	return nil
+}
+
+// ListRevisions returns revisions of the catalog (newest first)
+func (nc *NftCatalogService) ListRevisions(catalogId string, limit int) ([]*model.CatalogRevision, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
 
 	q := query.Query{
 		Limit:  limit,
-		Prefix: "/" + model.CatalogTable,
+		Prefix: "/" + model.CatalogRevisionTable + "/" + catalogId,
 		Orders: []query.Order{query.OrderByKeyDescending{}},
 	}
-
 	qRes, err := nc.environment.DB.Query(ctx, q)
+	if err != nil {
+		lc.Log.Error("failed to list catalog revisions", catalogId, err)
+		return nil, err
+	}
 	defer qRes.Close()
+	res, err := qRes.Rest()
 	if err != nil {
-		lc.Log.Error("failed to list all catalogs", err)
+		lc.Log.Error("failed to list catalog revisions", catalogId, err)
 		return nil, err
 	}
 
-	catalogs := []*model.Catalog{}
-	res, err := qRes.Rest()
+	revisions := []*model.CatalogRevision{}
 	for _, r := range res {
-		cat, err := util.UnmarshalFromBytes(r.Value)
+		revision, err := decodeRevision(r.Value)
 		if err != nil {
-			lc.Log.Error("failed to unmarshal catalog", err)
 			return nil, err
 		}
-		var catalog model.Catalog
-		mapstructure.Decode(cat, &catalog)
-		catalogs = append(catalogs, &catalog)
+		revisions = append(revisions, revision)
 	}
-	return catalogs, nil
+	return revisions, nil
+}
+
+// GetRevision returns a revision of the catalog. Returns model.ErrNotFound if revision doesn't exist
+func (nc *NftCatalogService) GetRevision(catalogId string, revision int) (*model.CatalogRevision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	value, err := nc.environment.DB.Get(ctx, revisionKey(catalogId, revision))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrNotFound
+		}
+		lc.Log.Error("failed to get catalog revision", catalogId, revision, err)
+		return nil, err
+	}
+	return decodeRevision(value)
+}
+
+// DiffRevisions returns field changes between two revisions of the catalog (to = 0 is the latest revision)
+func (nc *NftCatalogService) DiffRevisions(catalogId string, from int, to int) (*model.CatalogRevisionDiff, error) {
+	if to == 0 {
+		last, err := nc.lastRevision(catalogId)
+		if err != nil {
+			return nil, err
+		}
+		to = last
+	}
+	fromRevision, err := nc.GetRevision(catalogId, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := nc.GetRevision(catalogId, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := catalogChanges(fromRevision.Catalog, toRevision.Catalog)
+	if err != nil {
+		lc.Log.Error("failed to diff catalog revisions", catalogId, err)
+		return nil, err
+	}
+	return &model.CatalogRevisionDiff{
+		CatalogId: catalogId,
+		From:      from,
+		To:        to,
+		Changes:   changes,
+	}, nil
+}
+
+// RestoreRevision stores the catalog as it was at the given revision (as a new revision). Returns error
+// wrapping model.ErrInvalidCatalog if the revision is invalid under the current validation rules
+func (nc *NftCatalogService) RestoreRevision(catalogId string, revision int, author string) (*model.Catalog, error) {
+	nc.revisionMutex.Lock()
+	defer nc.revisionMutex.Unlock()
+
+	restored, err := nc.GetRevision(catalogId, revision)
+	if err != nil {
+		return nil, err
+	}
+	if restored.Catalog == nil {
+		lc.Log.Error("catalog revision without snapshot", catalogId, revision)
+		return nil, model.ErrNotFound
+	}
+	catalog := *restored.Catalog
+	// restoring content doesn't change the lifecycle of the catalog
+	current, err := nc.getStoredCatalog(catalogId)
+	if err != nil && err != model.ErrNotFound {
+		return nil, err
+	}
+	if current != nil {
+		catalog.Status = current.Status
+		catalog.PublishAt = current.PublishAt
+	}
+	// revisions may predate current validation rules
+	if err := nc.ValidateCatalog(&catalog); err != nil {
+		return nil, fmt.Errorf("%w: %s", model.ErrInvalidCatalog, err.Error())
+	}
+	return nc.putCatalog(&catalog, author, revision)
+}
+
+// lastRevision returns number of the latest revision of the catalog (0 if catalog has no revisions)
+func (nc *NftCatalogService) lastRevision(catalogId string) (int, error) {
+	revisions, err := nc.ListRevisions(catalogId, 1)
+	if err != nil {
+		return 0, err
+	}
+	if len(revisions) == 0 {
+		return 0, nil
+	}
+	return revisions[0].Revision, nil
+}
+
+// revision numbers are zero padded so keys are ordered by revision
+func revisionKey(catalogId string, revision int) datastore.Key {
+	return util.CreateKey(model.CatalogRevisionTable, fmt.Sprintf("%s/%08d", catalogId, revision))
+}
+
+func decodeRevision(value []byte) (*model.CatalogRevision, error) {
+	revMap, err := util.UnmarshalFromBytes(value)
+	if err != nil {
+		lc.Log.Error("failed to unmarshal catalog revision", err)
+		return nil, err
+	}
+	var revision model.CatalogRevision
+	if err := mapstructure.Decode(revMap, &revision); err != nil {
+		lc.Log.Error("failed to decode catalog revision", err)
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// fields which change on every write or aren't set by the user
+var catalogUntrackedFields = map[string]bool{
+	"modified":      true,
+	"created":       true,
+	"nftTokensUsed": true,
+}
+
+// catalogChanges returns changed JSON fields (sorted by name) between two catalogs (old may be nil)
+func catalogChanges(old *model.Catalog, new *model.Catalog) ([]*model.CatalogFieldChange, error) {
+	oldFields, err := catalogFields(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := catalogFields(new)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []*model.CatalogFieldChange{}
+	for _, name := range names {
+		if catalogUntrackedFields[name] || reflect.DeepEqual(oldFields[name], newFields[name]) ||
+			(isEmptyField(oldFields[name]) && isEmptyField(newFields[name])) {
+			continue
+		}
+		changes = append(changes, &model.CatalogFieldChange{
+			Field: name,
+			Old:   oldFields[name],
+			New:   newFields[name],
+		})
+	}
+	return changes, nil
+}
+
+// empty fields are equal to missing fields
+func isEmptyField(value interface{}) bool {
+	return value == nil || value == "" || value == false || value == float64(0)
+}
+
+func catalogFields(catalog *model.Catalog) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if catalog == nil {
+		return fields, nil
+	}
+	b, err := json.Marshal(catalog)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &fields)
+	return fields, err
 }
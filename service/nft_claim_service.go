@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"context"
 	"crypto/ecdsa"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -18,23 +18,38 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
-	"github.com/ipfs/go-datastore"
-	"github.com/ipfs/go-datastore/query"
 	"github.com/jinzhu/copier"
 	lc "github.com/mailio/mailio-nft-server/config"
 	"github.com/mailio/mailio-nft-server/model"
-	"github.com/mailio/mailio-nft-server/util"
-	"github.com/mitchellh/mapstructure"
 	"github.com/rs/xid"
 )
 
+// gas limit of the SafeMint transaction
+const mintGasLimit = uint64(300000)
+
+// number of claims read at once while looking up the claim of a token
+const tokenClaimsPageSize = 500
+
 type NftClaimService struct {
-	environment *model.Environment
+	environment        *model.Environment
+	budgetService      *BudgetService
+	nftMetadataService *NftMetadataService
+	nftCatalogService  *NftCatalogService
+	funnelService      *FunnelService
+	editionMutex       sync.Mutex             // guards editionLocks
+	editionLocks       map[string]*sync.Mutex // serializes edition assignment per catalog
+	maxEditionsMutex   sync.Mutex
+	maxEditions        int // MAXTOKENSINCATEGORY of the contract (0 until read)
 }
 
-func NewNftClaimService(environment *model.Environment) *NftClaimService {
+func NewNftClaimService(environment *model.Environment, budgetService *BudgetService, nftMetadataService *NftMetadataService, nftCatalogService *NftCatalogService, funnelService *FunnelService) *NftClaimService {
 	return &NftClaimService{
-		environment: environment,
+		environment:        environment,
+		budgetService:      budgetService,
+		nftMetadataService: nftMetadataService,
+		nftCatalogService:  nftCatalogService,
+		funnelService:      funnelService,
+		editionLocks:       map[string]*sync.Mutex{},
 	}
 }
 
@@ -62,6 +77,41 @@ func (ecs *NftClaimService) GetBalance() (big.Int, error) {
 	return *balance, nil
 }
 
+// BrokerAddress returns the address of the broker wallet (payee of mint transactions)
+func (ecs *NftClaimService) BrokerAddress() (common.Address, error) {
+	privateKey, err := crypto.HexToECDSA(lc.Conf.BlockchainConfig.MailioNFTBrokerPrivateKey)
+	if err != nil {
+		lc.Log.Error("failed to parse private key", err)
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
+
+// PendingTransactions returns number of broker transactions waiting in the mempool
+func (ecs *NftClaimService) PendingTransactions() (uint64, error) {
+	address, err := ecs.BrokerAddress()
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	pending, err := ecs.environment.EthClient.PendingNonceAt(ctx, address)
+	if err != nil {
+		lc.Log.Error("failed to get pending nonce", err)
+		return 0, err
+	}
+	confirmed, err := ecs.environment.EthClient.NonceAt(ctx, address, nil)
+	if err != nil {
+		lc.Log.Error("failed to get nonce", err)
+		return 0, err
+	}
+	if pending < confirmed {
+		return 0, nil
+	}
+	return pending - confirmed, nil
+}
+
 // verify users signature in order to claim an NFT
 func verifySignature(fromAddress, signatureHex string, catalogId string) error {
 
@@ -126,56 +176,38 @@ func verifySignature(fromAddress, signatureHex string, catalogId string) error {
 // actual minting of the new Mailio NFT
 // throws ErrSignature if signature is invalid
 // throws ErrExists if NFT already claimed by user for this category
+// throws ErrBudget if the transaction fee would exceed any of the spending budgets
+// throws ErrKeyword if keywords don't match
+// signature, keywords and mint steps of the claim funnel are recorded as the checks run
 func (ecs *NftClaimService) MintForUser(claim *model.Claim, catalog *model.Catalog) (*types.Transaction, *model.Claim, error) {
 	// validate signature
 	signatureErr := verifySignature(claim.WalletAddress, claim.Signature, catalog.ID)
 	if signatureErr != nil {
 		lc.Log.Error("failed to verify signature", signatureErr)
+		ecs.funnelService.Failed(model.FunnelSignature, model.FunnelReasonInvalidSignature, catalog.ID, claim.WalletAddress, claim.VisitorId)
 		return nil, nil, model.ErrSignature
 	}
+	ecs.funnelService.Passed(model.FunnelSignature, catalog.ID, claim.WalletAddress, claim.VisitorId)
 
-	// validate keywords
-	isKeywordMatch := ecs.CheckKeywordsMatch(claim.Keywords, catalog.Keywords)
+	// validate keywords (in the claimant's language or the default language of the catalog)
+	isKeywordMatch := ecs.CheckKeywordsMatch(claim.Keywords, catalog.Localized(claim.Language).Keywords) ||
+		ecs.CheckKeywordsMatch(claim.Keywords, catalog.Keywords)
 	if !isKeywordMatch {
+		ecs.funnelService.Failed(model.FunnelKeywords, model.FunnelReasonInvalidKeywords, catalog.ID, claim.WalletAddress, claim.VisitorId)
 		return nil, nil, model.ErrKeyword
 	}
-	// upload NFT to IPFS (JSON File)
-	erc20JsonFile := model.Erc721Json{
-		Name:            catalog.Name,
-		Description:     catalog.Description,
-		Image:           "ipfs://" + catalog.ImageLink,
-		ExternalUrl:     catalog.ContentLink,
-		BackgroundColor: "212529",
-		Attributes: []*model.Erc721Attribute{
-			{
-				"display_type": "boost_number",
-				"trait_type":   "informed",
-				"value":        5,
-			},
-		},
-	}
+	ecs.funnelService.Passed(model.FunnelKeywords, catalog.ID, claim.WalletAddress, claim.VisitorId)
 
-	if catalog.VideoLink != "" {
-		erc20JsonFile.YoutubeUrl = catalog.VideoLink
+	tx, claimed, err := ecs.mintForUser(claim, catalog)
+	txHash := ""
+	if tx != nil {
+		txHash = tx.Hash().Hex()
 	}
+	ecs.funnelService.RecordMint(claim, catalog.ID, txHash, err)
+	return tx, claimed, err
+}
 
-	erc20Json, jErr := json.Marshal(erc20JsonFile)
-	if jErr != nil {
-		lc.Log.Error("failed to marshal ERC721 JSON", jErr)
-		return nil, nil, jErr
-	}
-	jsponReader := bytes.NewReader(erc20Json)
-	uploadedIpfs, upErr := util.UploadToIPFSAndPin(ecs.environment.IpfsInfuraClient, claim.WalletAddress+"_"+claim.CatalogId+".json", jsponReader)
-	if upErr != nil {
-		lc.Log.Error("failed to upload ERC721 JSON to IPFS", upErr)
-		return nil, nil, upErr
-	}
-	if len(uploadedIpfs) == 0 {
-		return nil, nil, errors.New("failed to upload ERC721 JSON to IPFS")
-	}
-	// always take the first item (link to file, not the folder)
-	tokenURI := "ipfs://" + uploadedIpfs[0].Hash
-
+func (ecs *NftClaimService) mintForUser(claim *model.Claim, catalog *model.Catalog) (*types.Transaction, *model.Claim, error) {
 	// convert catalogId to bytes ([12]byte)
 	catalogID, err := xid.FromString(catalog.ID)
 	if err != nil {
@@ -204,16 +236,56 @@ func (ecs *NftClaimService) MintForUser(claim *model.Claim, catalog *model.Catal
 	}
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
-	// we also need to figure out the gas price and the nonce
-	nonce, err := ecs.environment.EthClient.PendingNonceAt(context.Background(), fromAddress)
+	// gas price is needed upfront to check the max fee of the transaction against spending budgets
+	gasPrice, err := ecs.environment.EthClient.SuggestGasPrice(context.Background())
 	if err != nil {
-		lc.Log.Error("failed to get nonce", err)
+		lc.Log.Error("failed to get gas price", err)
 		return nil, nil, err
 	}
+	maxFee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(mintGasLimit))
+	budgetDay, bErr := ecs.budgetService.Reserve(catalog.ID, maxFee)
+	if bErr != nil {
+		return nil, nil, bErr
+	}
+	// reserved fee is returned back to the budget unless transaction is sent (reconciled once it's mined)
+	txSent := false
+	defer func() {
+		if !txSent {
+			ecs.budgetService.Release(catalog.ID, budgetDay, maxFee)
+		}
+	}()
+
+	// editions are assigned atomically by the repository (shared by replicas). Mints of the catalog are also
+	// serialized until the transaction is sent, so editions of failed mints are released without gaps
+	editionLock := ecs.editionLock(catalog.ID)
+	editionLock.Lock()
+	editionLocked := true
+	defer func() {
+		if editionLocked {
+			editionLock.Unlock()
+		}
+	}()
+	edition, maxEditions, eErr := ecs.nextEdition(catalog.ID, catalogID)
+	if eErr != nil {
+		return nil, nil, eErr
+	}
+	defer func() {
+		if !txSent {
+			ecs.releaseEdition(catalog.ID, edition)
+		}
+	}()
+	tokenClaim := newTokenClaim(catalog, claim, edition, maxEditions)
+
+	// token URI points either to metadata uploaded to IPFS or to the metadata endpoint of the bridge
+	tokenURI, tErr := ecs.nftMetadataService.TokenURI(catalog, tokenClaim)
+	if tErr != nil {
+		return nil, nil, tErr
+	}
 
-	gasPrice, err := ecs.environment.EthClient.SuggestGasPrice(context.Background())
+	// we also need to figure out the nonce
+	nonce, err := ecs.environment.EthClient.PendingNonceAt(context.Background(), fromAddress)
 	if err != nil {
-		lc.Log.Error("failed to get gas price", err)
+		lc.Log.Error("failed to get nonce", err)
 		return nil, nil, err
 	}
 
@@ -231,8 +303,8 @@ func (ecs *NftClaimService) MintForUser(claim *model.Claim, catalog *model.Catal
 		return nil, nil, aErr
 	}
 	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)     // in wei
-	auth.GasLimit = uint64(300000) // in units Expected gas cost for our contract is about 170000 units
+	auth.Value = big.NewInt(0)   // in wei
+	auth.GasLimit = mintGasLimit // in units Expected gas cost for our contract is about 170000 units
 	auth.GasPrice = gasPrice
 	auth.Context = ctx
 	auth.From = fromAddress
@@ -244,6 +316,9 @@ func (ecs *NftClaimService) MintForUser(claim *model.Claim, catalog *model.Catal
 		lc.Log.Error("failed to call contract method SafeMint: ", smErr)
 		return nil, nil, smErr
 	}
+	txSent = true
+	editionLock.Unlock()
+	editionLocked = false
 
 	// store minted tx to database
 	cl := &model.Claim{
@@ -254,7 +329,13 @@ func (ecs *NftClaimService) MintForUser(claim *model.Claim, catalog *model.Catal
 		ReCaptchaToken: claim.ReCaptchaToken,
 		VisitorId:      claim.VisitorId,
 		WalletAddress:  claim.WalletAddress,
+		Language:       claim.Language,
+		Edition:        edition,
+		MaxEditions:    maxEditions,
 		GasPrice:       tx.GasPrice().Uint64(),
+		ReservedFee:    maxFee.String(),
+		BudgetDay:      budgetDay,
+		MintStatus:     model.MintPending,
 		Created:        time.Now().UnixMilli(),
 	}
 	claimed, claimErr := ecs.PutClaimedNFT(cl)
@@ -267,20 +348,232 @@ func (ecs *NftClaimService) MintForUser(claim *model.Claim, catalog *model.Catal
 	return tx, claimed, nil
 }
 
+// WatchMetadataFreeze periodically freezes served metadata to IPFS (blocking, run as goroutine)
+// disabled if metadata freeze_interval is not configured
+func (ecs *NftClaimService) WatchMetadataFreeze() {
+	interval := lc.Conf.Metadata.FreezeInterval
+	if interval <= 0 {
+		return
+	}
+	for {
+		if err := ecs.FreezeMetadata(); err != nil {
+			lc.Log.Error("failed to freeze metadata", err)
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
+// FreezeMetadata uploads metadata of claims served by the metadata endpoint to IPFS
+// once they're older than metadata freeze_after hours. Frozen claims are served from IPFS
+func (ecs *NftClaimService) FreezeMetadata() error {
+	claims, err := ecs.ListClaims(0)
+	if err != nil {
+		return err
+	}
+	freezeBefore := time.Now().Add(-time.Duration(lc.Conf.Metadata.FreezeAfter) * time.Hour).UnixMilli()
+	for _, claim := range claims {
+		if claim.MetadataCid != "" || strings.HasPrefix(claim.TokenUri, "ipfs://") || claim.Created > freezeBefore {
+			continue
+		}
+		cid, fErr := ecs.nftMetadataService.Freeze(claim)
+		if fErr != nil {
+			lc.Log.Error("failed to freeze metadata of claim", claim.WalletAddress, claim.CatalogId, fErr)
+			continue
+		}
+		claim.MetadataCid = cid
+		if uErr := ecs.updateClaim(claim); uErr != nil {
+			return uErr
+		}
+		lc.Log.Info("frozen metadata of claim", claim.WalletAddress, claim.CatalogId, cid)
+	}
+	return nil
+}
+
+// GetClaimByOwner returns claim of the catalog by owners wallet address (any letter case)
+// or ErrNotFound error
+func (ecs *NftClaimService) GetClaimByOwner(catalogId string, owner string) (*model.Claim, error) {
+	claim, err := ecs.GetClaim(catalogId, owner)
+	if err == model.ErrNotFound && strings.ToLower(owner) != owner {
+		return ecs.GetClaim(catalogId, strings.ToLower(owner))
+	}
+	return claim, err
+}
+
+// GetClaimByToken returns the claim the on-chain token was minted for (owner of the token may have changed since).
+// The claim is found by the token URI stored on-chain, claim of the current owner is checked first.
+// Returns ErrNotFound if token wasn't minted by the bridge
+func (ecs *NftClaimService) GetClaimByToken(catalogId string, tokenId string, owner string) (*model.Claim, error) {
+	onchainId, ok := new(big.Int).SetString(tokenId, 10)
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	tokenURI, err := ecs.environment.NftContract.TokenURI(&bind.CallOpts{Context: ctx}, onchainId)
+	if err != nil {
+		lc.Log.Error("failed to read token URI", tokenId, err)
+		return nil, err
+	}
+	claim, err := ecs.GetClaimByOwner(catalogId, owner)
+	if err != nil && err != model.ErrNotFound {
+		return nil, err
+	}
+	if err == nil && claim.TokenUri == tokenURI {
+		return claim, nil
+	}
+	filter := &model.ClaimFilter{CatalogId: catalogId}
+	cursor := ""
+	for {
+		claims, next, err := ecs.environment.Claims.ListPage(ctx, filter, cursor, tokenClaimsPageSize)
+		if err != nil {
+			lc.Log.Error("failed to list claims", err)
+			return nil, err
+		}
+		for _, claim := range claims {
+			if claim.TokenUri == tokenURI {
+				return claim, nil
+			}
+		}
+		if next == "" {
+			return nil, model.ErrNotFound
+		}
+		cursor = next
+	}
+}
+
+// MaxEditions returns max number of tokens within a catalog (MAXTOKENSINCATEGORY of the contract)
+func (ecs *NftClaimService) MaxEditions() (int, error) {
+	ecs.maxEditionsMutex.Lock()
+	defer ecs.maxEditionsMutex.Unlock()
+	if ecs.maxEditions > 0 {
+		return ecs.maxEditions, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+	maxTokens, err := ecs.environment.NftContract.MAXTOKENSINCATEGORY(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		lc.Log.Error("failed to read max tokens in category", err)
+		return 0, err
+	}
+	ecs.maxEditions = int(maxTokens.Int64())
+	return ecs.maxEditions, nil
+}
+
+// editionLock returns the lock serializing edition assignment of the catalog
+func (ecs *NftClaimService) editionLock(catalogId string) *sync.Mutex {
+	ecs.editionMutex.Lock()
+	defer ecs.editionMutex.Unlock()
+	lock, ok := ecs.editionLocks[catalogId]
+	if !ok {
+		lock = &sync.Mutex{}
+		ecs.editionLocks[catalogId] = lock
+	}
+	return lock
+}
+
+// nextEdition assigns edition number of the next token of the catalog and returns it with max number of editions
+// throws ErrSoldOut if all editions have been claimed
+func (ecs *NftClaimService) nextEdition(catalogId string, catalogID xid.ID) (int, int, error) {
+	maxEditions, err := ecs.MaxEditions()
+	if err != nil {
+		return 0, 0, err
+	}
+	minted, err := ecs.mintedEditions(catalogID)
+	if err != nil {
+		return 0, 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	// minted tokens may not be counted on-chain yet, so the last assigned edition is taken into account too
+	edition, err := ecs.environment.Editions.Next(ctx, catalogId, minted, maxEditions)
+	if err != nil {
+		if err != model.ErrSoldOut {
+			lc.Log.Error("failed to assign claim edition", err)
+		}
+		return 0, 0, err
+	}
+	return edition, maxEditions, nil
+}
+
+// newTokenClaim is the claim the metadata of the minted token is built from (in the claimant's language)
+func newTokenClaim(catalog *model.Catalog, claim *model.Claim, edition int, maxEditions int) *model.Claim {
+	return &model.Claim{
+		CatalogId:     catalog.ID,
+		WalletAddress: claim.WalletAddress,
+		Language:      claim.Language,
+		Edition:       edition,
+		MaxEditions:   maxEditions,
+		Created:       time.Now().UnixMilli(),
+	}
+}
+
+// PreviewEdition returns the edition the next claim of the catalog would be assigned (from the same
+// sources as nextEdition, without assigning it)
+func (ecs *NftClaimService) PreviewEdition(catalogId string) (int, error) {
+	catalogID, err := xid.FromString(catalogId)
+	if err != nil {
+		lc.Log.Error("failed to parse catalog id", err)
+		return 0, err
+	}
+	minted, err := ecs.mintedEditions(catalogID)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	last, err := ecs.environment.Editions.Last(ctx, catalogId)
+	if err != nil {
+		lc.Log.Error("failed to read last claim edition", err)
+		return 0, err
+	}
+	if minted > last {
+		last = minted
+	}
+	return last + 1, nil
+}
+
+// mintedEditions returns number of tokens of the catalog minted on-chain
+func (ecs *NftClaimService) mintedEditions(catalogID xid.ID) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	var catId [12]byte
+	copy(catId[:], catalogID.Bytes())
+	count, err := ecs.environment.NftContract.CategoryTokenCount(&bind.CallOpts{Context: ctx}, catId)
+	if err != nil {
+		lc.Log.Error("failed to retrieve category count on blockchain", err)
+		return 0, err
+	}
+	return int(count.Int64()), nil
+}
+
+// releaseEdition takes back the edition of a mint transaction that wasn't sent
+func (ecs *NftClaimService) releaseEdition(catalogId string, edition int) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	if err := ecs.environment.Editions.Release(ctx, catalogId, edition); err != nil {
+		lc.Log.Error("failed to release claim edition", err)
+		// deliberately ignore this error (the edition is skipped)
+	}
+}
+
 // PutClaim only inserts a new claim to the database
 func (ecs *NftClaimService) PutClaimedNFT(claim *model.Claim) (*model.Claim, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
 
 	claim.Created = time.Now().UnixMilli()
-	id := claim.WalletAddress + "_" + claim.CatalogId // unique per catalogId
 
-	m, err := util.MarshalToBytes(claim)
-	err = ecs.environment.DB.Put(ctx, util.CreateKey(model.ClaimTable, id), m)
+	err := ecs.environment.Claims.Put(ctx, claim) // unique per wallet and catalogId
 	if err != nil {
 		lc.Log.Error("failed to create new catalog", err)
 		return nil, err
 	}
+	ecs.nftCatalogService.ClaimRecorded(claim.CatalogId)
 	// insert into the database users fingerprint of the claim
 	_, fErr := ecs.PutVisitorClaimFingerprint(&model.ClaimFingerprint{
 		CatalogId: claim.CatalogId,
@@ -293,15 +586,25 @@ func (ecs *NftClaimService) PutClaimedNFT(claim *model.Claim) (*model.Claim, err
 	return claim, nil
 }
 
+// updateClaim stores changes of an existing claim (preserving the created timestamp)
+func (ecs *NftClaimService) updateClaim(claim *model.Claim) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	err := ecs.environment.Claims.Put(ctx, claim)
+	if err != nil {
+		lc.Log.Error("failed to update claim", err)
+	}
+	return err
+}
+
 // PutVisitorClaimFingerprint inserts a new fingerprint to the database for the specified catalogId
 func (ecs *NftClaimService) PutVisitorClaimFingerprint(finger *model.ClaimFingerprint) (*model.ClaimFingerprint, error) {
 	if finger.CatalogId != "" && finger.VisitorId != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 		defer cancel()
 
-		id := finger.CatalogId + "_" + finger.VisitorId // unique per catalogId
-		m, err := util.MarshalToBytes(finger)
-		err = ecs.environment.DB.Put(ctx, util.CreateKey(model.ClaimFingerprintTable, id), m)
+		err := ecs.environment.Fingerprints.Put(ctx, finger) // unique per catalogId
 		if err != nil {
 			lc.Log.Error("failed to create new fingerprint claim", err)
 			return nil, err
@@ -316,119 +619,78 @@ func (ecs *NftClaimService) GetVisitorClaimFingerprint(catalogId, visitorId stri
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
 
-	id := catalogId + "_" + visitorId // unique per catalogId
-	m, err := ecs.environment.DB.Get(ctx, util.CreateKey(model.ClaimFingerprintTable, id))
-	if err != nil {
-		if err == datastore.ErrNotFound {
-			return nil, model.ErrNotFound
-		}
+	finger, err := ecs.environment.Fingerprints.Get(ctx, catalogId, visitorId)
+	if err != nil && err != model.ErrNotFound {
 		lc.Log.Error("failed to get fingerprint claim", err)
-		return nil, err
 	}
-
-	var finger model.ClaimFingerprint
-	fingerMap, err := util.UnmarshalFromBytes(m)
-	if err != nil {
-		lc.Log.Error("failed to unmarshal fingerprint claim", err)
-		return nil, err
-	}
-	var clm model.ClaimFingerprint
-	err = mapstructure.Decode(fingerMap, &clm)
-	return &finger, err
+	return finger, err
 }
 
 // returns stored claim if exists, or ErrNotFound error
 func (ecs *NftClaimService) GetClaim(catalogId string, walletAddress string) (*model.Claim, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
-	key := util.CreateKey(model.ClaimTable, walletAddress+"_"+catalogId)
-	m, err := ecs.environment.DB.Get(ctx, key)
-	if err != nil {
-		if err == datastore.ErrNotFound {
-			return nil, model.ErrNotFound
-		}
+	claim, err := ecs.environment.Claims.Get(ctx, catalogId, walletAddress)
+	if err != nil && err != model.ErrNotFound {
 		lc.Log.Error("failed to get claim", err)
-		return nil, err
 	}
-	claimMap, err := util.UnmarshalFromBytes(m)
-	if err != nil {
-		lc.Log.Error("failed to unmarshal catalog", err)
-		return nil, err
-	}
-	var clm model.Claim
-	err = mapstructure.Decode(claimMap, &clm)
-	return &clm, err
+	return claim, err
 }
 
-// lists all claims for a given catalogId order descending by created date
+// lists claims newest first (all if limit is 0)
 func (ecs *NftClaimService) ListClaims(limit int) ([]*model.Claim, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
 
-	q := query.Query{
-		Limit:  limit,
-		Prefix: "/" + model.ClaimTable,
-		Orders: []query.Order{query.OrderByKeyDescending{}},
+	claims, err := ecs.environment.Claims.List(ctx, limit)
+	if err != nil {
+		lc.Log.Error("failed to list all claims", err)
+		return nil, err
 	}
+	return claims, nil
+}
+
+// ListClaimsPage returns a page of claims selected by the filter newest first, starting after the cursor.
+// Number of all selected claims is counted only if requested. Returns model.ErrInvalidCursor if cursor can't be decoded
+func (ecs *NftClaimService) ListClaimsPage(filter *model.ClaimFilter, limit int, cursor string, withTotal bool) (*model.ClaimPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
 
-	qRes, err := ecs.environment.DB.Query(ctx, q)
-	defer qRes.Close()
+	claims, next, err := ecs.environment.Claims.ListPage(ctx, filter, cursor, limit)
 	if err != nil {
-		lc.Log.Error("failed to list all catalogs", err)
+		if err != model.ErrInvalidCursor {
+			lc.Log.Error("failed to list claims", err)
+		}
 		return nil, err
 	}
-
-	claims := []*model.Claim{}
-	res, err := qRes.Rest()
-	for _, r := range res {
-		clBytes, err := util.UnmarshalFromBytes(r.Value)
+	page := &model.ClaimPage{
+		Claims: claims,
+		Next:   next,
+	}
+	if withTotal {
+		total, err := ecs.environment.Claims.Count(ctx, filter)
 		if err != nil {
-			lc.Log.Error("failed to unmarshal catalog", err)
+			lc.Log.Error("failed to count claims", err)
 			return nil, err
 		}
-		var claim model.Claim
-		mapstructure.Decode(clBytes, &claim)
-		claims = append(claims, &claim)
+		page.Total = &total
 	}
-	return claims, nil
+	return page, nil
 }
 
-// list all claims belonding to single wallet
-func (ecs *NftClaimService) ListClaimsByUser(wallet string, limit int) ([]*model.Claim, error) {
+// list a page of claims belonding to single wallet newest first (starting after the cursor) and the cursor of the next page
+func (ecs *NftClaimService) ListClaimsByUser(wallet string, limit int, cursor string) ([]*model.Claim, string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
 
-	q := query.Query{
-		Limit:  limit,
-		Prefix: "/" + model.ClaimTable,
-		Orders: []query.Order{query.OrderByKeyDescending{}},
-		Filters: []query.Filter{
-			query.FilterKeyPrefix{
-				Prefix: "/" + model.ClaimTable + "/" + wallet,
-			},
-		},
-	}
-
-	qRes, err := ecs.environment.DB.Query(ctx, q)
-	defer qRes.Close()
+	claims, next, err := ecs.environment.Claims.ListPage(ctx, &model.ClaimFilter{WalletAddress: wallet}, cursor, limit)
 	if err != nil {
-		lc.Log.Error("failed to list all catalogs", err)
-		return nil, err
-	}
-
-	claims := []*model.Claim{}
-	res, err := qRes.Rest()
-	for _, r := range res {
-		clBytes, err := util.UnmarshalFromBytes(r.Value)
-		if err != nil {
-			lc.Log.Error("failed to unmarshal catalog", err)
-			return nil, err
+		if err != model.ErrInvalidCursor {
+			lc.Log.Error("failed to list claims of the user", err)
 		}
-		var claim model.Claim
-		mapstructure.Decode(clBytes, &claim)
-		claims = append(claims, &claim)
+		return nil, "", err
 	}
-	return claims, nil
+	return claims, next, nil
 }
 
 // checks if keywords match word by word (order of the words doesn't matter)
@@ -462,20 +724,33 @@ func (ecs *NftClaimService) CheckKeywordsMatch(claimKeywords []model.ClaimKeywor
 	return true
 }
 
-// reads and parses the transaction log
-func (ecs *NftClaimService) ReadClaimedTransactionLogs(walletAddress string, limit int) ([]*model.ClaimPreview, error) {
-	claims, err := ecs.ListClaimsByUser(walletAddress, limit)
+// reads and parses the transaction log of a page of user claims (number of all user claims only if requested)
+func (ecs *NftClaimService) ReadClaimedTransactionLogs(walletAddress string, limit int, cursor string, withTotal bool) (*model.ClaimPreviewPage, error) {
+	claims, next, err := ecs.ListClaimsByUser(walletAddress, limit, cursor)
 	if err != nil {
 		return nil, err
 	}
+	page := &model.ClaimPreviewPage{
+		Claims: []*model.ClaimPreview{},
+		Next:   next,
+	}
+	if withTotal {
+		ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+		total, err := ecs.environment.Claims.Count(ctx, &model.ClaimFilter{WalletAddress: walletAddress})
+		cancel()
+		if err != nil {
+			lc.Log.Error("failed to count claims of the user", err)
+			return nil, err
+		}
+		page.Total = &total
+	}
 	// return empty array
 	if len(claims) == 0 {
-		return []*model.ClaimPreview{}, nil
+		return page, nil
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
 
-	claimPreviews := []*model.ClaimPreview{}
 	for _, claim := range claims {
 		// in case transaction doesn't exist yet return 404
 		receipt, err := ecs.environment.EthClient.TransactionReceipt(ctx, common.HexToHash(claim.TxHash))
@@ -503,7 +778,7 @@ func (ecs *NftClaimService) ReadClaimedTransactionLogs(walletAddress string, lim
 				}
 			}
 		}
-		claimPreviews = append(claimPreviews, claimPreview)
+		page.Claims = append(page.Claims, claimPreview)
 	}
-	return claimPreviews, nil
+	return page, nil
 }
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/mailio/mailio-nft-server/model"
+)
+
+func TestTokenClaimMetadataLanguage(t *testing.T) {
+	catalog := &model.Catalog{
+		ID:          "c9p1s2ak0lk0d0b2bvj0",
+		Name:        "Privacy basics",
+		Description: "Learn the basics of email privacy",
+		Type:        "article",
+		Translations: map[string]*model.CatalogTranslation{
+			"de": {Name: "Datenschutz Grundlagen"},
+		},
+		MetadataTemplate: &model.MetadataTemplate{NameTemplate: "{catalog} #{edition}"},
+	}
+	claim := &model.Claim{
+		CatalogId:     catalog.ID,
+		WalletAddress: "0x0000000000000000000000000000000000000001",
+		Language:      catalog.MatchLanguage([]string{"de-AT"}),
+	}
+
+	tokenClaim := newTokenClaim(catalog, claim, 3, 100)
+	if tokenClaim.Language != "de" {
+		t.Fatalf("token claim language %q, expected de", tokenClaim.Language)
+	}
+	metadata := (&NftMetadataService{}).BuildMetadata(catalog, tokenClaim)
+	if metadata.Name != "Datenschutz Grundlagen #3" {
+		t.Fatalf("metadata name %q, expected the German name", metadata.Name)
+	}
+	if metadata.Description != catalog.Description {
+		t.Fatalf("metadata description %q, expected fallback to the default language", metadata.Description)
+	}
+}
@@ -1,81 +1,611 @@
 package service
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
 	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
 
 	lc "github.com/mailio/mailio-nft-server/config"
 	"github.com/mailio/mailio-nft-server/model"
 	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mitchellh/mapstructure"
 )
 
+// quality of re-encoded original JPEG images (metadata stripping)
+const originalJpegQuality = 95
+
 type NftImagesService struct {
-	environment *model.Environment
+	environment    *model.Environment
+	pinService     *PinService
+	catalogService *NftCatalogService
+	claimService   *NftClaimService
 }
 
-func NewNftImagesService(environment *model.Environment) *NftImagesService {
+func NewNftImagesService(environment *model.Environment, pinService *PinService, catalogService *NftCatalogService, claimService *NftClaimService) *NftImagesService {
 
 	return &NftImagesService{
-		environment: environment,
+		environment:    environment,
+		pinService:     pinService,
+		catalogService: catalogService,
+		claimService:   claimService,
 	}
 }
 
-// list all pinned nft images
-func (ni *NftImagesService) List() (*model.NftImage, error) {
+// List returns a page (starting with 1) of uploaded images, newest first. Search matches
+// (case insensitive) name, CID, MIME type or uploader
+func (ni *NftImagesService) List(page int, pageSize int, search string) (*model.NftImageList, error) {
+	all, err := ni.loadImages()
+	if err != nil {
+		return nil, err
+	}
+	search = strings.ToLower(strings.TrimSpace(search))
+	images := []*model.NftImage{}
+	for _, image := range all {
+		if search != "" && !strings.Contains(strings.ToLower(strings.Join([]string{image.Name, image.Cid, image.Mime, image.Uploader}, " ")), search) {
+			continue
+		}
+		images = append(images, image)
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Created == images[j].Created {
+			return images[i].Cid < images[j].Cid
+		}
+		return images[i].Created > images[j].Created
+	})
+
+	list := &model.NftImageList{
+		Images:   []*model.NftImageListItem{},
+		Total:    len(images),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	from := (page - 1) * pageSize
+	if from >= len(images) {
+		return list, nil
+	}
+	to := from + pageSize
+	if to > len(images) {
+		to = len(images)
+	}
 
-	resp, err := ni.environment.IpfsInfuraClient.R().
-		SetQueryParam("type", "all").
-		Post("/api/v0/pin/ls")
+	usage, err := ni.catalogImageUsage()
 	if err != nil {
-		lc.Log.Error("failed to list pinned images", err)
 		return nil, err
 	}
-	if resp.StatusCode() != 200 {
-		lc.Log.Error("failed to list pinned images", string(resp.Body()))
-		return nil, errors.New("failed to list pinned images")
+	for _, image := range images[from:to] {
+		item := &model.NftImageListItem{
+			NftImage:       image,
+			UsedByCatalogs: usage[image.Cid],
+		}
+		// catalogs may also use one of the image variants
+		for _, variant := range []*model.NftImageVariant{image.Optimized, image.Thumbnail} {
+			if variant != nil && variant.Cid != image.Cid {
+				item.UsedByCatalogs += usage[variant.Cid]
+			}
+		}
+		list.Images = append(list.Images, item)
 	}
-	var images model.NftImage
-	mErr := json.Unmarshal(resp.Body(), &images)
-	if mErr != nil {
-		lc.Log.Error("failed to unmarshal images", mErr)
-		return nil, mErr
+	return list, nil
+}
+
+// loadImages returns all records of the image library
+func (ni *NftImagesService) loadImages() ([]*model.NftImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	q := query.Query{
+		Prefix: "/" + model.NftImageTable,
 	}
-	return &images, nil
+	qRes, err := ni.environment.DB.Query(ctx, q)
+	if err != nil {
+		lc.Log.Error("failed to list images", err)
+		return nil, err
+	}
+	defer qRes.Close()
+	res, err := qRes.Rest()
+	if err != nil {
+		lc.Log.Error("failed to list images", err)
+		return nil, err
+	}
+
+	images := []*model.NftImage{}
+	for _, r := range res {
+		img, err := util.UnmarshalFromBytes(r.Value)
+		if err != nil {
+			lc.Log.Error("failed to unmarshal image", err)
+			return nil, err
+		}
+		var image model.NftImage
+		mapstructure.Decode(img, &image)
+		images = append(images, &image)
+	}
+	return images, nil
 }
 
-// Upload file to IPFS (on Infura) and pin it
-func (ni *NftImagesService) Upload(file *multipart.FileHeader) ([]*model.NftImageUploadResponse, error) {
+// imageCids returns the image library record the CID (original or one of its variants) belongs to together
+// with CIDs of the original, optimized and thumbnail. CIDs not in the library (e.g. metadata) are returned alone
+func (ni *NftImagesService) imageCids(cid string) (*model.NftImage, []string, error) {
+	image, err := ni.GetImage(cid)
+	if err == model.ErrNotFound {
+		image = nil
+		images, lErr := ni.loadImages()
+		if lErr != nil {
+			return nil, nil, lErr
+		}
+		for _, img := range images {
+			for _, variant := range []*model.NftImageVariant{img.Optimized, img.Thumbnail} {
+				if variant != nil && variant.Cid == cid {
+					image = img
+				}
+			}
+		}
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if image == nil {
+		return nil, []string{cid}, nil
+	}
+	cids := []string{image.Cid}
+	for _, variant := range []*model.NftImageVariant{image.Optimized, image.Thumbnail} {
+		if variant != nil && variant.Cid != image.Cid {
+			cids = append(cids, variant.Cid)
+		}
+	}
+	return image, cids, nil
+}
+
+// catalogImageUsage returns number of catalogs using each image CID
+func (ni *NftImagesService) catalogImageUsage() (map[string]int, error) {
+	catalogs, err := ni.catalogService.ListAllCatalogs(0)
+	if err != nil {
+		return nil, err
+	}
+	usage := map[string]int{}
+	for _, catalog := range catalogs {
+		if cid := strings.TrimPrefix(catalog.ImageLink, "ipfs://"); cid != "" {
+			usage[cid]++
+		}
+	}
+	return usage, nil
+}
+
+// Upload checks the uploaded file (sniffed type, size and pixel limits), strips metadata (EXIF, GPS)
+// of images by re-encoding them and pins the image together with its web optimized version and thumbnail.
+// Upload is recorded in the image library. Returns error wrapping model.ErrInvalidImage if file is not accepted
+func (ni *NftImagesService) Upload(file *multipart.FileHeader, uploader string) ([]*model.NftImageUploadResponse, error) {
+	conf := imagesConfig()
 	mpFile, err := file.Open()
+	if err != nil {
+		lc.Log.Error("failed to read file", err)
+		return nil, errors.New("failed to read file")
+	}
 	defer mpFile.Close()
 
+	data, err := ioutil.ReadAll(io.LimitReader(mpFile, conf.MaxSize+1))
 	if err != nil {
 		lc.Log.Error("failed to read file", err)
 		return nil, errors.New("failed to read file")
 	}
+	if int64(len(data)) > conf.MaxSize {
+		return nil, fmt.Errorf("%w: file larger than %d bytes", model.ErrInvalidImage, conf.MaxSize)
+	}
+	// content type is sniffed from the content, extension and multipart header are not trusted
+	mimeType := strings.Split(http.DetectContentType(data), ";")[0]
+	allowed := false
+	for _, t := range conf.AllowedTypes {
+		allowed = allowed || t == mimeType
+	}
+	if !allowed {
+		return nil, fmt.Errorf("%w: file type %s is not allowed", model.ErrInvalidImage, mimeType)
+	}
 
-	return util.UploadToIPFSAndPin(ni.environment.IpfsInfuraClient, file.Filename, mpFile)
+	response := &model.NftImageUploadResponse{
+		Name: file.Filename,
+	}
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif":
+		if err := ni.processImage(file.Filename, data, mimeType, conf, response); err != nil {
+			return nil, err
+		}
+	default:
+		// media types which can't be decoded are pinned as they are
+		response.Original, err = ni.pinVariant(file.Filename, data, mimeType, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	response.Hash = response.Original.Cid
+	response.Size = strconv.Itoa(response.Original.Size)
+
+	image := &model.NftImage{
+		Cid:       response.Original.Cid,
+		Name:      file.Filename,
+		Size:      response.Original.Size,
+		Mime:      response.Original.Mime,
+		Width:     response.Original.Width,
+		Height:    response.Original.Height,
+		Optimized: response.Optimized,
+		Thumbnail: response.Thumbnail,
+		Uploader:  uploader,
+		Created:   time.Now().UnixMilli(),
+	}
+	// re-upload of the same image keeps the original record
+	existing, err := ni.GetImage(image.Cid)
+	if err == nil {
+		image.Name = existing.Name
+		image.Uploader = existing.Uploader
+		image.Created = existing.Created
+	} else if err != model.ErrNotFound {
+		return nil, err
+	}
+	if err := ni.putImage(image); err != nil {
+		return nil, err
+	}
+	return []*model.NftImageUploadResponse{response}, nil
 }
 
-// deletes the pinned object
-func (ni *NftImagesService) RemovePin(hash string) (*model.NftPins, error) {
-	resp, err := ni.environment.IpfsInfuraClient.R().
-		SetQueryParam("arg", hash).
-		Post("/api/v0/pin/rm")
+// processImage re-encodes the image without metadata and creates its optimized version and thumbnail
+func (ni *NftImagesService) processImage(filename string, data []byte, mimeType string, conf lc.ImagesSubConfig, response *model.NftImageUploadResponse) error {
+	imgConf, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %s", model.ErrInvalidImage, err.Error())
+	}
+	// checked before decoding, so huge images are never decoded into memory
+	if imgConf.Width*imgConf.Height > conf.MaxPixels {
+		return fmt.Errorf("%w: image has more than %d pixels", model.ErrInvalidImage, conf.MaxPixels)
+	}
+
+	var (
+		img      image.Image
+		original bytes.Buffer
+	)
+	switch mimeType {
+	case "image/gif":
+		// every frame is decoded, so the pixel limit applies to all frames together
+		if util.GifPixels(data) > conf.MaxPixels {
+			return fmt.Errorf("%w: frames of the image have more than %d pixels", model.ErrInvalidImage, conf.MaxPixels)
+		}
+		// all frames are re-encoded (comments and application extensions are dropped)
+		g, gErr := gif.DecodeAll(bytes.NewReader(data))
+		if gErr != nil {
+			return fmt.Errorf("%w: %s", model.ErrInvalidImage, gErr.Error())
+		}
+		err = gif.EncodeAll(&original, g)
+		img = g.Image[0]
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("%w: %s", model.ErrInvalidImage, err.Error())
+		}
+		// orientation is applied to pixels since EXIF is stripped
+		img = util.ApplyOrientation(img, util.JpegOrientation(data))
+		err = jpeg.Encode(&original, img, &jpeg.Options{Quality: originalJpegQuality})
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("%w: %s", model.ErrInvalidImage, err.Error())
+		}
+		err = png.Encode(&original, img)
+	}
+	if err != nil {
+		lc.Log.Error("failed to re-encode image", err)
+		return err
+	}
+	bounds := img.Bounds()
+	response.Original, err = ni.pinVariant(filename, original.Bytes(), mimeType, bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return err
+	}
+
+	variants := []struct {
+		suffix  string
+		maxSize int
+		target  **model.NftImageVariant
+	}{
+		{"optimized", conf.OptimizedSize, &response.Optimized},
+		{"thumbnail", conf.ThumbnailSize, &response.Thumbnail},
+	}
+	for _, v := range variants {
+		resized := util.ResizeToFit(img, v.maxSize)
+		encoded, variantMime, eErr := util.EncodeImage(resized, conf.JpegQuality)
+		if eErr != nil {
+			lc.Log.Error("failed to encode image variant", v.suffix, eErr)
+			return eErr
+		}
+		b := resized.Bounds()
+		*v.target, err = ni.pinVariant(variantFilename(filename, v.suffix, variantMime), encoded, variantMime, b.Dx(), b.Dy())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+func (ni *NftImagesService) pinVariant(filename string, data []byte, mimeType string, width int, height int) (*model.NftImageVariant, error) {
+	pin, err := ni.pinService.Add(filename, data, 0)
 	if err != nil {
-		lc.Log.Error("failed to removed pinned image", err)
 		return nil, err
 	}
-	if resp.StatusCode() != 200 {
-		lc.Log.Error("failed to delete pinned images", string(resp.Body()))
-		return nil, errors.New("failed to delete pinned image")
+	return &model.NftImageVariant{
+		Cid:    pin.Cid,
+		Mime:   mimeType,
+		Width:  width,
+		Height: height,
+		Size:   len(data),
+	}, nil
+}
+
+// imagesConfig returns images config with defaults of missing values
+func imagesConfig() lc.ImagesSubConfig {
+	conf := lc.Conf.Images
+	if len(conf.AllowedTypes) == 0 {
+		conf.AllowedTypes = []string{"image/jpeg", "image/png", "image/gif"}
+	}
+	if conf.MaxSize <= 0 {
+		conf.MaxSize = 10 << 20
+	}
+	if conf.MaxPixels <= 0 {
+		conf.MaxPixels = 25000000
+	}
+	if conf.ThumbnailSize <= 0 {
+		conf.ThumbnailSize = 256
+	}
+	if conf.OptimizedSize <= 0 {
+		conf.OptimizedSize = 1200
+	}
+	if conf.JpegQuality <= 0 || conf.JpegQuality > 100 {
+		conf.JpegQuality = 85
+	}
+	return conf
+}
+
+// variantFilename e.g. cover.png -> cover_thumbnail.jpg
+func variantFilename(filename string, suffix string, mimeType string) string {
+	ext := ".jpg"
+	if mimeType == "image/png" {
+		ext = ".png"
+	}
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + "_" + suffix + ext
+}
+
+// GetImage returns image library record. Returns model.ErrNotFound if image wasn't uploaded through the bridge
+func (ni *NftImagesService) GetImage(cid string) (*model.NftImage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	value, err := ni.environment.DB.Get(ctx, util.CreateKey(model.NftImageTable, cid))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrNotFound
+		}
+		lc.Log.Error("failed to get image", cid, err)
+		return nil, err
+	}
+	img, err := util.UnmarshalFromBytes(value)
+	if err != nil {
+		lc.Log.Error("failed to unmarshal image", cid, err)
+		return nil, err
+	}
+	var image model.NftImage
+	mapstructure.Decode(img, &image)
+	return &image, nil
+}
+
+func (ni *NftImagesService) putImage(image *model.NftImage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	b, err := util.MarshalToBytes(image)
+	if err != nil {
+		lc.Log.Error("failed to marshal image", image.Cid, err)
+		return err
+	}
+	if err := ni.environment.DB.Put(ctx, util.CreateKey(model.NftImageTable, image.Cid), b); err != nil {
+		lc.Log.Error("failed to store image", image.Cid, err)
+		return err
+	}
+	return nil
+}
+
+func (ni *NftImagesService) deleteImage(cid string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	if err := ni.environment.DB.Delete(ctx, util.CreateKey(model.NftImageTable, cid)); err != nil {
+		lc.Log.Error("failed to delete image", cid, err)
+		return err
+	}
+	return nil
+}
+
+// RemovePin deletes the pinned object from all pinning providers. Images of the library are removed together
+// with their variants (optimized and thumbnail). Content referenced by catalogs or claims is removed only if
+// forced (with a reason), otherwise model.ErrPinReferenced is returned together with references.
+// Forced removals are stored to the audit log together with their outcome
+func (ni *NftImagesService) RemovePin(hash string, force bool, reason string, user string) (*model.NftPins, []*model.PinReference, error) {
+	image, cids, err := ni.imageCids(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	references, err := ni.references(cids)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(references) > 0 && !force {
+		return nil, references, model.ErrPinReferenced
+	}
+
+	rmErr := ni.removePin(image, cids)
+	if force {
+		removal := &model.PinRemoval{
+			ID:         util.GenerateRandomID(),
+			Cid:        hash,
+			Cids:       cids,
+			Reason:     reason,
+			User:       user,
+			References: references,
+			Removed:    rmErr == nil,
+			Created:    time.Now().UnixMilli(),
+		}
+		if rmErr != nil {
+			removal.Error = rmErr.Error()
+		}
+		lc.Log.Warn("forced removal of pinned content", hash, "user", user, "reason", reason, "references", len(references), "removed", removal.Removed)
+		if err := ni.putRemoval(removal); err != nil && rmErr == nil {
+			return nil, nil, err
+		}
+	}
+	if rmErr != nil {
+		return nil, nil, rmErr
+	}
+	return &model.NftPins{Pins: cids}, references, nil
+}
+
+// removePin unpins the CIDs from all pinning providers and deletes the image from the image library once all
+// of them are unpinned (so a failed removal can be repeated)
+func (ni *NftImagesService) removePin(image *model.NftImage, cids []string) error {
+	for _, cid := range cids {
+		pin, err := ni.pinService.Unpin(cid)
+		if err != nil {
+			lc.Log.Error("failed to removed pinned image", cid, err)
+			return err
+		}
+		if len(pin.Locations) > 0 {
+			lc.Log.Error("failed to delete pinned image from all providers", cid, pin.Locations)
+			return errors.New("failed to delete pinned image")
+		}
+	}
+	if image == nil {
+		return nil
+	}
+	return ni.deleteImage(image.Cid)
+}
+
+// References returns catalogs (image) and claims (token URI or frozen metadata) depending on the CID.
+// Catalogs using any variant of an image of the library reference the image
+func (ni *NftImagesService) References(cid string) ([]*model.PinReference, error) {
+	_, cids, err := ni.imageCids(cid)
+	if err != nil {
+		return nil, err
+	}
+	return ni.references(cids)
+}
+
+func (ni *NftImagesService) references(cids []string) ([]*model.PinReference, error) {
+	referenced := map[string]bool{}
+	for _, cid := range cids {
+		referenced[cid] = true
+	}
+	references := []*model.PinReference{}
+	catalogs, err := ni.catalogService.ListAllCatalogs(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, catalog := range catalogs {
+		if referenced[strings.TrimPrefix(catalog.ImageLink, "ipfs://")] {
+			references = append(references, &model.PinReference{
+				Type:  model.PinReferenceCatalog,
+				ID:    catalog.ID,
+				Field: "imageLink",
+			})
+		}
+	}
+	claims, err := ni.claimService.ListClaims(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, claim := range claims {
+		id := claim.WalletAddress + "_" + claim.CatalogId
+		if strings.HasPrefix(claim.TokenUri, "ipfs://") && referenced[strings.TrimPrefix(claim.TokenUri, "ipfs://")] {
+			references = append(references, &model.PinReference{
+				Type:  model.PinReferenceClaim,
+				ID:    id,
+				Field: "tokenUri",
+			})
+		} else if claim.MetadataCid != "" && referenced[claim.MetadataCid] {
+			references = append(references, &model.PinReference{
+				Type:  model.PinReferenceClaim,
+				ID:    id,
+				Field: "metadataCid",
+			})
+		}
+	}
+	return references, nil
+}
+
+// ListRemovals returns audit log of forced pin removals (newest first). Forced removals are rare,
+// so all entries are loaded and sorted by creation time
+func (ni *NftImagesService) ListRemovals(limit int) ([]*model.PinRemoval, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	q := query.Query{
+		Prefix: "/" + model.PinRemovalTable,
+	}
+	qRes, err := ni.environment.DB.Query(ctx, q)
+	if err != nil {
+		lc.Log.Error("failed to list pin removals", err)
+		return nil, err
+	}
+	defer qRes.Close()
+	res, err := qRes.Rest()
+	if err != nil {
+		lc.Log.Error("failed to list pin removals", err)
+		return nil, err
+	}
+
+	removals := []*model.PinRemoval{}
+	for _, r := range res {
+		rm, err := util.UnmarshalFromBytes(r.Value)
+		if err != nil {
+			lc.Log.Error("failed to unmarshal pin removal", err)
+			return nil, err
+		}
+		var removal model.PinRemoval
+		mapstructure.Decode(rm, &removal)
+		removals = append(removals, &removal)
+	}
+	sort.Slice(removals, func(i, j int) bool {
+		if removals[i].Created == removals[j].Created {
+			return removals[i].ID > removals[j].ID
+		}
+		return removals[i].Created > removals[j].Created
+	})
+	if limit > 0 && len(removals) > limit {
+		removals = removals[:limit]
+	}
+	return removals, nil
+}
+
+func (ni *NftImagesService) putRemoval(removal *model.PinRemoval) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	b, err := util.MarshalToBytes(removal)
+	if err != nil {
+		lc.Log.Error("failed to marshal pin removal", removal.Cid, err)
+		return err
 	}
-	var pins model.NftPins
-	mErr := json.Unmarshal(resp.Body(), &pins)
-	if mErr != nil {
-		lc.Log.Error("failed to unmarshal images", mErr)
-		return nil, mErr
+	if err := ni.environment.DB.Put(ctx, util.CreateKey(model.PinRemovalTable, removal.ID), b); err != nil {
+		lc.Log.Error("failed to store pin removal", removal.Cid, err)
+		return err
 	}
-	return &pins, nil
+	return nil
 }
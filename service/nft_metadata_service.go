@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+	"github.com/rs/xid"
+)
+
+type NftMetadataService struct {
+	environment       *model.Environment
+	nftCatalogService *NftCatalogService
+	pinService        *PinService
+}
+
+func NewNftMetadataService(environment *model.Environment, nftCatalogService *NftCatalogService, pinService *PinService) *NftMetadataService {
+	return &NftMetadataService{
+		environment:       environment,
+		nftCatalogService: nftCatalogService,
+		pinService:        pinService,
+	}
+}
+
+// default background color of the token if catalog has no metadata template
+const defaultMetadataBackgroundColor = "212529"
+
+// top level fields generated from the catalog which can't be overridden by extra fields
+var reservedMetadataFields = []string{"name", "description", "image", "attributes", "background_color", "external_url", "youtube_url"}
+
+// placeholders supported by the name template
+var nameTemplatePlaceholders = map[string]bool{"{catalog}": true, "{edition}": true, "{maxEditions}": true, "{type}": true}
+
+var (
+	metadataFieldNameRegex       = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	metadataBackgroundColorRegex = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+	metadataPlaceholderRegex     = regexp.MustCompile(`\{[^{}]*\}`)
+)
+
+// BuildMetadata builds ERC-721 metadata of the catalog as received by the claimant together
+// with per-token attributes of the claim (edition, claim date and catalog type)
+func (nms *NftMetadataService) BuildMetadata(catalog *model.Catalog, claim *model.Claim) *model.Erc721Json {
+	// content in the claimant's language
+	catalog = catalog.Localized(catalog.MatchLanguage([]string{claim.Language}))
+	metadata := &model.Erc721Json{
+		Name:            catalog.Name,
+		Description:     catalog.Description,
+		Image:           "ipfs://" + catalog.ImageLink,
+		ExternalUrl:     catalog.ContentLink,
+		BackgroundColor: defaultMetadataBackgroundColor,
+		Attributes: []*model.Erc721Attribute{
+			{
+				"display_type": "boost_number",
+				"trait_type":   "informed",
+				"value":        5,
+			},
+		},
+	}
+	if catalog.VideoLink != "" {
+		metadata.YoutubeUrl = catalog.VideoLink
+	}
+
+	template := catalog.MetadataTemplate
+	if template == nil {
+		metadata.Attributes = append(metadata.Attributes, tokenAttributes(catalog, claim)...)
+		return metadata
+	}
+	if template.NameTemplate != "" && claim.Edition > 0 {
+		metadata.Name = renderName(template.NameTemplate, catalog, claim)
+	}
+	metadata.BackgroundColor = template.BackgroundColor
+	if template.ExternalUrlPattern != "" {
+		metadata.ExternalUrl = renderExternalUrl(template.ExternalUrlPattern, catalog, claim.WalletAddress)
+	}
+	metadata.Attributes = []*model.Erc721Attribute{}
+	for _, attr := range template.Attributes {
+		attribute := model.Erc721Attribute{
+			"value": attr.Value,
+		}
+		if attr.TraitType != "" {
+			attribute["trait_type"] = attr.TraitType
+		}
+		if attr.DisplayType != "" {
+			attribute["display_type"] = attr.DisplayType
+		}
+		if attr.MaxValue != 0 {
+			attribute["max_value"] = attr.MaxValue
+		}
+		metadata.Attributes = append(metadata.Attributes, &attribute)
+	}
+	// per-token attributes unless defined by the template
+	for _, attribute := range tokenAttributes(catalog, claim) {
+		if !hasTraitType(template.Attributes, (*attribute)["trait_type"].(string)) {
+			metadata.Attributes = append(metadata.Attributes, attribute)
+		}
+	}
+	metadata.ExtraFields = template.ExtraFields
+	return metadata
+}
+
+// validateMetadataTemplate checks metadata template against OpenSea metadata standards
+// (https://docs.opensea.io/docs/metadata-standards)
+func validateMetadataTemplate(template *model.MetadataTemplate) error {
+	if template == nil {
+		return nil
+	}
+	for _, placeholder := range metadataPlaceholderRegex.FindAllString(template.NameTemplate, -1) {
+		if !nameTemplatePlaceholders[placeholder] {
+			return fmt.Errorf("nameTemplate contains unknown placeholder %s", placeholder)
+		}
+	}
+	if template.BackgroundColor != "" && !metadataBackgroundColorRegex.MatchString(template.BackgroundColor) {
+		return fmt.Errorf("backgroundColor must be a six-character hexadecimal without a pre-pended #")
+	}
+	if template.ExternalUrlPattern != "" {
+		rendered := renderExternalUrl(template.ExternalUrlPattern, &model.Catalog{ID: "catalog", ContentLink: "https://example.com"}, "0x0")
+		u, err := url.Parse(rendered)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("externalUrlPattern must render to an absolute http(s) URL")
+		}
+	}
+	traitTypes := map[string]bool{}
+	for i, attr := range template.Attributes {
+		if attr.TraitType != "" {
+			if traitTypes[attr.TraitType] {
+				return fmt.Errorf("attribute %d: duplicate trait type %s", i, attr.TraitType)
+			}
+			traitTypes[attr.TraitType] = true
+		} else if attr.DisplayType != "" {
+			return fmt.Errorf("attribute %d: displayType requires a traitType", i)
+		}
+		value, isNumeric := toFloat(attr.Value)
+		if !isNumeric {
+			if _, isString := attr.Value.(string); !isString {
+				return fmt.Errorf("attribute %d: value must be a string or a number", i)
+			}
+		}
+		if attr.DisplayType != "" && !isNumeric {
+			return fmt.Errorf("attribute %d: displayType %s requires a numeric value", i, attr.DisplayType)
+		}
+		if attr.MaxValue != 0 {
+			if !isNumeric || attr.DisplayType == "date" {
+				return fmt.Errorf("attribute %d: maxValue is allowed only for numeric values", i)
+			}
+			if value > attr.MaxValue {
+				return fmt.Errorf("attribute %d: value is greater than maxValue", i)
+			}
+		}
+	}
+	for field := range template.ExtraFields {
+		if !metadataFieldNameRegex.MatchString(field) {
+			return fmt.Errorf("extra field %s must be snake_case", field)
+		}
+		for _, reserved := range reservedMetadataFields {
+			if field == reserved {
+				return fmt.Errorf("extra field %s is generated from the catalog", field)
+			}
+		}
+	}
+	return nil
+}
+
+// TokenURI returns token URI for a new claim depending on the catalogs metadata mode
+// ipfs: metadata is uploaded to IPFS, https: token URI points to the metadata endpoint
+func (nms *NftMetadataService) TokenURI(catalog *model.Catalog, claim *model.Claim) (string, error) {
+	claimId := claim.WalletAddress + "_" + catalog.ID
+	if catalog.MetadataMode == model.MetadataModeHttps {
+		baseUrl := lc.Conf.Metadata.BaseUrl
+		if baseUrl == "" {
+			lc.Log.Error("metadata base_url not configured for https metadata mode", catalog.ID)
+			return "", errors.New("metadata base_url not configured")
+		}
+		return strings.TrimSuffix(baseUrl, "/") + "/" + claimId, nil
+	}
+	cid, err := nms.Upload(claimId, nms.BuildMetadata(catalog, claim))
+	if err != nil {
+		return "", err
+	}
+	return "ipfs://" + cid, nil
+}
+
+// Upload uploads metadata JSON to IPFS and returns its CID
+// metadata is serialized canonically, so identical metadata (e.g. of a retried claim) reuses the existing pin.
+// Returns model.ErrCidMismatch if IPFS returns a different CID than computed locally
+func (nms *NftMetadataService) Upload(claimId string, metadata *model.Erc721Json) (string, error) {
+	metadataJson, err := util.CanonicalJSON(metadata)
+	if err != nil {
+		lc.Log.Error("failed to marshal ERC721 JSON", err)
+		return "", err
+	}
+	pin, err := nms.pinService.Add(claimId+".json", metadataJson, lc.Conf.Metadata.CidVersion)
+	if err != nil {
+		lc.Log.Error("failed to upload ERC721 JSON to IPFS", err)
+		return "", err
+	}
+	return pin.Cid, nil
+}
+
+// Freeze uploads currently served metadata of the claim to IPFS and returns its CID
+func (nms *NftMetadataService) Freeze(claim *model.Claim) (string, error) {
+	catalog, err := nms.nftCatalogService.GetCatalog(claim.CatalogId)
+	if err != nil {
+		return "", err
+	}
+	return nms.Upload(claim.WalletAddress+"_"+claim.CatalogId, nms.BuildMetadata(catalog, claim))
+}
+
+// ResolveToken resolves a token id (on-chain token id or claim id walletAddress_catalogId)
+// into the catalog ID and the wallet address of the owner
+// returns model.ErrNotFound if token doesn't exist
+func (nms *NftMetadataService) ResolveToken(tokenId string) (string, string, error) {
+	onchainId, isNumeric := new(big.Int).SetString(tokenId, 10)
+	if !isNumeric {
+		idx := strings.LastIndex(tokenId, "_")
+		if idx <= 0 {
+			return "", "", model.ErrNotFound
+		}
+		return tokenId[idx+1:], tokenId[:idx], nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	owner, err := nms.environment.NftContract.OwnerOf(&bind.CallOpts{Context: ctx}, onchainId)
+	if err != nil {
+		// contract reverts for non existing tokens
+		lc.Log.Warn("failed to read owner of token", tokenId, err)
+		return "", "", model.ErrNotFound
+	}
+	categoryId, err := nms.environment.NftContract.TokenIdToCategoryId(&bind.CallOpts{Context: ctx}, onchainId)
+	if err != nil {
+		lc.Log.Error("failed to read category of token", tokenId, err)
+		return "", "", err
+	}
+	catalogId, err := xid.FromBytes(categoryId[:])
+	if err != nil {
+		lc.Log.Error("failed to parse category id of token", tokenId, err)
+		return "", "", err
+	}
+	return catalogId.String(), owner.Hex(), nil
+}
+
+// tokenAttributes are computed per token at mint time: edition, claim date (day granularity), catalog type
+// and type-specific details
+func tokenAttributes(catalog *model.Catalog, claim *model.Claim) []*model.Erc721Attribute {
+	attributes := []*model.Erc721Attribute{}
+	if claim.Edition > 0 {
+		edition := model.Erc721Attribute{
+			"display_type": "number",
+			"trait_type":   "Edition",
+			"value":        claim.Edition,
+		}
+		if claim.MaxEditions > 0 {
+			edition["max_value"] = claim.MaxEditions
+		}
+		attributes = append(attributes, &edition)
+	}
+	if claim.Created > 0 {
+		claimed := time.UnixMilli(claim.Created).UTC().Truncate(24 * time.Hour)
+		attributes = append(attributes, &model.Erc721Attribute{
+			"display_type": "date",
+			"trait_type":   "Claimed",
+			"value":        claimed.Unix(),
+		})
+	}
+	if catalog.Type != "" {
+		attributes = append(attributes, &model.Erc721Attribute{
+			"trait_type": "Type",
+			"value":      catalog.Type,
+		})
+	}
+	return append(attributes, typeAttributes(catalog)...)
+}
+
+// typeAttributes are attributes of type-specific catalog details (dates in seconds)
+func typeAttributes(catalog *model.Catalog) []*model.Erc721Attribute {
+	attributes := []*model.Erc721Attribute{}
+	add := func(traitType string, displayType string, value interface{}) {
+		if value == "" || value == 0 || value == int64(0) {
+			return
+		}
+		attribute := model.Erc721Attribute{
+			"trait_type": traitType,
+			"value":      value,
+		}
+		if displayType != "" {
+			attribute["display_type"] = displayType
+		}
+		attributes = append(attributes, &attribute)
+	}
+	switch {
+	case catalog.Type == model.CatalogTypeVideo && catalog.Video != nil:
+		add("Platform", "", catalog.Video.Platform)
+		add("Duration (seconds)", "number", catalog.Video.DurationSeconds)
+	case catalog.Type == model.CatalogTypeArticle && catalog.Article != nil:
+		add("Author", "", catalog.Article.Author)
+		add("Published", "date", catalog.Article.PublishedAt/1000)
+		add("Reading Time (minutes)", "number", catalog.Article.ReadingMinutes)
+	case catalog.Type == model.CatalogTypePodcast && catalog.Podcast != nil:
+		add("Host", "", catalog.Podcast.Host)
+	case catalog.Type == model.CatalogTypePodcastEpisode && catalog.PodcastEpisode != nil:
+		add("Season", "number", catalog.PodcastEpisode.Season)
+		add("Episode", "number", catalog.PodcastEpisode.EpisodeNumber)
+		add("Duration (seconds)", "number", catalog.PodcastEpisode.DurationSeconds)
+	case catalog.Type == model.CatalogTypeVirtualEvent && catalog.VirtualEvent != nil:
+		add("Event Date", "date", catalog.VirtualEvent.StartTime/1000)
+		add("Event End", "date", catalog.VirtualEvent.EndTime/1000)
+		add("Location", "", catalog.VirtualEvent.Location)
+	}
+	return attributes
+}
+
+func hasTraitType(attributes []*model.MetadataAttribute, traitType string) bool {
+	for _, attr := range attributes {
+		if attr.TraitType == traitType {
+			return true
+		}
+	}
+	return false
+}
+
+// renderName replaces placeholders of the name template (see nameTemplatePlaceholders)
+func renderName(nameTemplate string, catalog *model.Catalog, claim *model.Claim) string {
+	return strings.NewReplacer(
+		"{catalog}", catalog.Name,
+		"{edition}", strconv.Itoa(claim.Edition),
+		"{maxEditions}", strconv.Itoa(claim.MaxEditions),
+		"{type}", catalog.Type,
+	).Replace(nameTemplate)
+}
+
+// renderExternalUrl replaces placeholders of the external URL pattern
+func renderExternalUrl(pattern string, catalog *model.Catalog, walletAddress string) string {
+	return strings.NewReplacer(
+		"{catalogId}", catalog.ID,
+		"{wallet}", walletAddress,
+		"{contentLink}", catalog.ContentLink,
+	).Replace(pattern)
+}
+
+// toFloat converts numeric attribute values (float64 when decoded from JSON)
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
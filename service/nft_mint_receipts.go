@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+)
+
+// timeout of a single receipts poll
+const mintReceiptsTimeout = time.Minute
+
+// number of claims read at once while scanning for pending mints
+const mintReceiptsPageSize = 500
+
+// WatchMintReceipts periodically records outcomes of mint transactions of claims (blocking, run as goroutine).
+// The first poll checks all claims, following polls only claims of the receipt window
+func (ecs *NftClaimService) WatchMintReceipts() {
+	conf := receiptsConfig()
+	scanned := false // all claims are checked until the first successful poll
+	for {
+		since := int64(0)
+		if scanned {
+			since = time.Now().Add(-time.Duration(conf.Window) * time.Hour).UnixMilli()
+		}
+		if err := ecs.PollMintReceipts(since); err != nil {
+			lc.Log.Error("failed to poll mint receipts", err)
+		} else {
+			scanned = true
+		}
+		time.Sleep(time.Duration(conf.PollInterval) * time.Second)
+	}
+}
+
+// PollMintReceipts records status and block time of mined transactions of claims created since the time (ms)
+// without an outcome (claims stored before outcomes were recorded included)
+func (ecs *NftClaimService) PollMintReceipts(since int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mintReceiptsTimeout)
+	defer cancel()
+
+	blockTimes := map[uint64]int64{}
+	filter := &model.ClaimFilter{Since: since}
+	cursor := ""
+	for {
+		claims, next, err := ecs.environment.Claims.ListPage(ctx, filter, cursor, mintReceiptsPageSize)
+		if err != nil {
+			lc.Log.Error("failed to list claims", err)
+			return err
+		}
+		for _, claim := range claims {
+			if claim.TxHash == "" || claim.MintStatus == model.MintSuccess || claim.MintStatus == model.MintFailed {
+				continue
+			}
+			if err := ecs.recordMintReceipt(ctx, claim, blockTimes); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// recordMintReceipt stores the outcome of the mint transaction of the claim (nothing if it isn't mined yet)
+func (ecs *NftClaimService) recordMintReceipt(ctx context.Context, claim *model.Claim, blockTimes map[uint64]int64) error {
+	receipt, err := ecs.environment.EthClient.TransactionReceipt(ctx, common.HexToHash(claim.TxHash))
+	if err == ethereum.NotFound {
+		return nil
+	}
+	if err != nil {
+		lc.Log.Error("failed to get transaction receipt", claim.TxHash, err)
+		return err
+	}
+	block := receipt.BlockNumber.Uint64()
+	mined, ok := blockTimes[block]
+	if !ok {
+		header, err := ecs.environment.EthClient.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+		if err != nil {
+			lc.Log.Error("failed to get block header", block, err)
+			return err
+		}
+		mined = int64(header.Time) * 1000
+		blockTimes[block] = mined
+	}
+
+	// the claim may have changed since it was listed (e.g. metadata freeze)
+	stored, err := ecs.GetClaim(claim.CatalogId, claim.WalletAddress)
+	if err != nil {
+		return err
+	}
+	pending := stored.MintStatus == model.MintPending
+	stored.MintStatus = model.MintFailed
+	if receipt.Status == 1 {
+		stored.MintStatus = model.MintSuccess
+	}
+	stored.Mined = mined
+	// mint transactions are legacy transactions paying their gas price
+	paid := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), new(big.Int).SetUint64(stored.GasPrice))
+	stored.Fee = paid.String()
+	reserved, reservedOk := new(big.Int).SetString(stored.ReservedFee, 10)
+	budgetDay := stored.BudgetDay
+	stored.ReservedFee = ""
+	stored.BudgetDay = ""
+	if err := ecs.updateClaim(stored); err != nil {
+		return err
+	}
+	// reserved fee is reconciled once (claims stored before reservations were recorded have none)
+	if reservedOk && budgetDay != "" {
+		ecs.budgetService.Reconcile(stored.CatalogId, budgetDay, reserved, paid)
+	}
+	// claims stored before mints were tracked aren't part of the funnel
+	if pending {
+		event := &model.FunnelEvent{
+			Step:          model.FunnelConfirmed,
+			Passed:        stored.MintStatus == model.MintSuccess,
+			CatalogId:     stored.CatalogId,
+			WalletAddress: stored.WalletAddress,
+			VisitorId:     stored.VisitorId,
+			TxHash:        stored.TxHash,
+			Created:       mined,
+		}
+		if !event.Passed {
+			event.Reason = model.FunnelReasonReverted
+		}
+		ecs.funnelService.Record(event)
+	}
+	return nil
+}
+
+// receiptsConfig returns receipts config with defaults of missing values
+func receiptsConfig() lc.ReceiptsSubConfig {
+	conf := lc.Conf.Receipts
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = 30
+	}
+	if conf.Window <= 0 {
+		conf.Window = 24
+	}
+	return conf
+}
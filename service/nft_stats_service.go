@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+)
+
+// max number of buckets of claim statistics (31 days of hourly buckets)
+const maxStatsBuckets = 744
+
+// number of claims read at once while computing statistics
+const statsPageSize = 1000
+
+// timeout of computing statistics
+const statsTimeout = time.Minute
+
+var statsIntervals = map[string]int64{
+	model.StatsIntervalHour: int64(time.Hour / time.Millisecond),
+	model.StatsIntervalDay:  int64(24 * time.Hour / time.Millisecond),
+}
+
+// NftStatsService computes claim statistics from the claim indexes (by catalog and creation time)
+type NftStatsService struct {
+	environment       *model.Environment
+	nftClaimService   *NftClaimService
+	nftCatalogService *NftCatalogService
+}
+
+func NewNftStatsService(environment *model.Environment, nftClaimService *NftClaimService, nftCatalogService *NftCatalogService) *NftStatsService {
+	return &NftStatsService{
+		environment:       environment,
+		nftClaimService:   nftClaimService,
+		nftCatalogService: nftCatalogService,
+	}
+}
+
+// ClaimStats of claims of the catalog (all catalogs if empty) created within [from, to) in hourly or daily buckets.
+// Interval defaults to day, to defaults to now and from to 7 days (24 hours for hourly buckets) before to.
+// Returns model.ErrInvalidRange if interval is unknown, the range is empty or has too many buckets
+func (ss *NftStatsService) ClaimStats(catalogId string, from int64, to int64, interval string) (*model.ClaimStats, error) {
+	if interval == "" {
+		interval = model.StatsIntervalDay
+	}
+	size, ok := statsIntervals[interval]
+	if !ok {
+		return nil, model.ErrInvalidRange
+	}
+	if to == 0 {
+		to = time.Now().UnixMilli()
+	}
+	if from == 0 {
+		days := 7
+		if interval == model.StatsIntervalHour {
+			days = 1
+		}
+		from = to - int64(days)*statsIntervals[model.StatsIntervalDay]
+	}
+	if from < 0 || from >= to {
+		return nil, model.ErrInvalidRange
+	}
+	first := from - from%size // buckets start at full UTC hours or days
+	count := (to - first + size - 1) / size
+	if count > maxStatsBuckets {
+		return nil, model.ErrInvalidRange
+	}
+
+	stats := &model.ClaimStats{
+		CatalogId: catalogId,
+		From:      from,
+		To:        to,
+		Interval:  interval,
+		Buckets:   make([]*model.ClaimStatsBucket, count),
+	}
+	for i := range stats.Buckets {
+		stats.Buckets[i] = &model.ClaimStatsBucket{Start: first + int64(i)*size}
+	}
+	wallets := map[string]bool{}
+	visitors := map[string]bool{}
+	latencies := []int64{}
+	err := ss.scanClaims(&model.ClaimFilter{CatalogId: catalogId, Since: from, Until: to}, func(claim *model.Claim) {
+		bucket := stats.Buckets[(claim.Created-first)/size]
+		bucket.Claims++
+		stats.Claims++
+		wallets[claim.WalletAddress] = true
+		if claim.VisitorId != "" {
+			visitors[claim.VisitorId] = true
+		}
+		switch claim.MintStatus {
+		case model.MintSuccess:
+			bucket.Succeeded++
+			stats.Succeeded++
+		case model.MintFailed:
+			bucket.Failed++
+			stats.Failed++
+		case model.MintPending:
+			bucket.Pending++
+			stats.Pending++
+		default:
+			bucket.Unknown++
+			stats.Unknown++
+		}
+		if claim.Mined > 0 {
+			latencies = append(latencies, claim.Mined-claim.Created)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.UniqueWallets = len(wallets)
+	stats.UniqueVisitors = len(visitors)
+	stats.MintLatency = mintLatencyStats(latencies)
+	return stats, nil
+}
+
+// TopCatalogs ranks up to limit catalogs by number of claims created within [from, to).
+// To defaults to now and from to 7 days before to. Returns model.ErrInvalidRange if the range is empty
+// or longer than the range of daily claim statistics
+func (ss *NftStatsService) TopCatalogs(from int64, to int64, limit int) (*model.CatalogRanking, error) {
+	if to == 0 {
+		to = time.Now().UnixMilli()
+	}
+	if from == 0 {
+		from = to - 7*statsIntervals[model.StatsIntervalDay]
+	}
+	if from < 0 || from >= to || to-from > maxStatsBuckets*statsIntervals[model.StatsIntervalDay] {
+		return nil, model.ErrInvalidRange
+	}
+	entries := map[string]*model.CatalogRankingEntry{}
+	wallets := map[string]map[string]bool{}
+	err := ss.scanClaims(&model.ClaimFilter{Since: from, Until: to}, func(claim *model.Claim) {
+		entry, ok := entries[claim.CatalogId]
+		if !ok {
+			entry = &model.CatalogRankingEntry{CatalogId: claim.CatalogId}
+			entries[claim.CatalogId] = entry
+			wallets[claim.CatalogId] = map[string]bool{}
+		}
+		entry.Claims++
+		wallets[claim.CatalogId][claim.WalletAddress] = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	ranking := &model.CatalogRanking{
+		From:     from,
+		To:       to,
+		Catalogs: []*model.CatalogRankingEntry{},
+	}
+	for catalogId, entry := range entries {
+		entry.UniqueWallets = len(wallets[catalogId])
+		ranking.Catalogs = append(ranking.Catalogs, entry)
+	}
+	sort.Slice(ranking.Catalogs, func(i, j int) bool {
+		a, b := ranking.Catalogs[i], ranking.Catalogs[j]
+		if a.Claims != b.Claims {
+			return a.Claims > b.Claims
+		}
+		return a.CatalogId < b.CatalogId
+	})
+	if len(ranking.Catalogs) > limit {
+		ranking.Catalogs = ranking.Catalogs[:limit]
+	}
+	for _, entry := range ranking.Catalogs {
+		if catalog, err := ss.nftCatalogService.GetCatalog(entry.CatalogId); err == nil {
+			entry.Name = catalog.Name
+		}
+	}
+	return ranking, nil
+}
+
+// Summary combines broker balance and pending transactions with claim counts
+func (ss *NftStatsService) Summary() (*model.StatsSummary, error) {
+	address, err := ss.nftClaimService.BrokerAddress()
+	if err != nil {
+		return nil, err
+	}
+	balance, err := ss.nftClaimService.GetBalance()
+	if err != nil {
+		return nil, err
+	}
+	pendingTransactions, err := ss.nftClaimService.PendingTransactions()
+	if err != nil {
+		return nil, err
+	}
+	summary := &model.StatsSummary{
+		BrokerAddress:       address.Hex(),
+		Balance:             balance.String(),
+		BalanceNative:       util.WeiToNative(&balance),
+		PendingTransactions: pendingTransactions,
+	}
+
+	now := time.Now()
+	window := now.Add(-time.Duration(receiptsConfig().Window) * time.Hour).UnixMilli()
+	today := now.Add(-24 * time.Hour).UnixMilli()
+	err = ss.scanClaims(&model.ClaimFilter{Since: window}, func(claim *model.Claim) {
+		if claim.MintStatus == model.MintPending {
+			summary.PendingClaims++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+	if summary.ClaimsToday, err = ss.environment.Claims.Count(ctx, &model.ClaimFilter{Since: today}); err != nil {
+		lc.Log.Error("failed to count claims", err)
+		return nil, err
+	}
+	if summary.ClaimsTotal, err = ss.environment.Claims.Count(ctx, &model.ClaimFilter{}); err != nil {
+		lc.Log.Error("failed to count claims", err)
+		return nil, err
+	}
+	return summary, nil
+}
+
+// PublicCatalogStats returns claim counts of a published catalog or model.ErrNotFound
+func (ss *NftStatsService) PublicCatalogStats(catalogId string) (*model.PublicCatalogStats, error) {
+	catalog, err := ss.nftCatalogService.GetCatalog(catalogId)
+	if err != nil {
+		return nil, err
+	}
+	if !catalog.IsPublished() {
+		return nil, model.ErrNotFound
+	}
+	stats := &model.PublicCatalogStats{
+		CatalogId:     catalog.ID,
+		NftTokensUsed: catalog.NftTokensUsed,
+	}
+	if maxEditions, err := ss.nftClaimService.MaxEditions(); err == nil {
+		stats.MaxEditions = maxEditions
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+	filter := &model.ClaimFilter{CatalogId: catalog.ID}
+	if stats.Claims, err = ss.environment.Claims.Count(ctx, filter); err != nil {
+		lc.Log.Error("failed to count claims of the catalog", err)
+		return nil, err
+	}
+	today := &model.ClaimFilter{CatalogId: catalog.ID, Since: time.Now().Add(-24 * time.Hour).UnixMilli()}
+	if stats.ClaimsToday, err = ss.environment.Claims.Count(ctx, today); err != nil {
+		lc.Log.Error("failed to count claims of the catalog", err)
+		return nil, err
+	}
+	latest, _, err := ss.environment.Claims.ListPage(ctx, filter, "", 1)
+	if err != nil {
+		lc.Log.Error("failed to list claims of the catalog", err)
+		return nil, err
+	}
+	if len(latest) > 0 {
+		stats.LastClaimed = latest[0].Created
+	}
+	return stats, nil
+}
+
+// scanClaims calls fn with all claims selected by the filter (newest first)
+func (ss *NftStatsService) scanClaims(filter *model.ClaimFilter, fn func(claim *model.Claim)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+
+	cursor := ""
+	for {
+		claims, next, err := ss.environment.Claims.ListPage(ctx, filter, cursor, statsPageSize)
+		if err != nil {
+			lc.Log.Error("failed to list claims", err)
+			return err
+		}
+		for _, claim := range claims {
+			fn(claim)
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// mintLatencyStats summarizes latencies (nil if there are none)
+func mintLatencyStats(latencies []int64) *model.MintLatencyStats {
+	if len(latencies) == 0 {
+		return nil
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	sum := int64(0)
+	for _, latency := range latencies {
+		sum += latency
+	}
+	n := len(latencies)
+	return &model.MintLatencyStats{
+		Count:   n,
+		Average: sum / int64(n),
+		Median:  latencies[n/2],
+		P95:     latencies[(n*95-1)/100],
+		Max:     latencies[n-1],
+	}
+}
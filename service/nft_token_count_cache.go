@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/rs/xid"
+)
+
+// max number of blocks scanned for mint events within a single log filter request
+const tokenCountMaxBlockRange = uint64(2000)
+
+// timeout of a token count refresh or mint events scan
+const tokenCountTimeout = time.Minute
+
+// tokenCountCache caches number of tokens minted per category (catalog). Counts are read for all
+// categories in batches and kept fresh from mint (Transfer from zero address) events
+type tokenCountCache struct {
+	mutex     sync.RWMutex
+	counts    map[string]int // catalog ID -> number of minted tokens
+	lastBlock uint64         // last block included in counts (0 until first refresh)
+	refreshed time.Time      // time of the last full refresh
+}
+
+func newTokenCountCache() *tokenCountCache {
+	return &tokenCountCache{
+		counts: map[string]int{},
+	}
+}
+
+func (tc *tokenCountCache) get(catalogId string) (int, bool) {
+	tc.mutex.RLock()
+	defer tc.mutex.RUnlock()
+	count, ok := tc.counts[catalogId]
+	return count, ok
+}
+
+// setAt caches count read at the block unless counts moved past the block meanwhile
+func (tc *tokenCountCache) setAt(catalogId string, count int, block uint64) {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	if tc.lastBlock == block {
+		tc.counts[catalogId] = count
+	}
+}
+
+// WatchTokenCounts periodically refreshes counts of all categories and applies mint events in between
+func (nc *NftCatalogService) WatchTokenCounts() {
+	conf := tokenCountsConfig()
+	for {
+		nc.tokenCounts.mutex.RLock()
+		refresh := nc.tokenCounts.lastBlock == 0 || time.Since(nc.tokenCounts.refreshed) > time.Duration(conf.RefreshInterval)*time.Second
+		nc.tokenCounts.mutex.RUnlock()
+		if refresh {
+			if err := nc.RefreshTokenCounts(); err != nil {
+				lc.Log.Error("failed to refresh category token counts", err)
+			}
+		} else if err := nc.pollMints(); err != nil {
+			lc.Log.Error("failed to poll mint events", err)
+		}
+		time.Sleep(time.Duration(conf.PollInterval) * time.Second)
+	}
+}
+
+// RefreshTokenCounts reads token counts of all catalogs at the latest block with batched eth_call requests
+func (nc *NftCatalogService) RefreshTokenCounts() error {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCountTimeout)
+	defer cancel()
+
+	latest, err := nc.environment.EthClient.BlockNumber(ctx)
+	if err != nil {
+		lc.Log.Error("failed to read latest block number", err)
+		return err
+	}
+	catalogs, err := nc.ListAllCatalogs(0)
+	if err != nil {
+		return err
+	}
+
+	contract := common.HexToAddress(lc.Conf.BlockchainConfig.MailioNFTProxyAddress)
+	block := hexutil.EncodeUint64(latest)
+	batchSize := tokenCountsConfig().BatchSize
+	counts := map[string]int{}
+	for start := 0; start < len(catalogs); start += batchSize {
+		end := start + batchSize
+		if end > len(catalogs) {
+			end = len(catalogs)
+		}
+		batch := []rpc.BatchElem{}
+		results := make([]hexutil.Bytes, end-start)
+		for i, catalog := range catalogs[start:end] {
+			data, err := nc.mailioNftContractAbi.Pack("categoryTokenCount", categoryId(catalog.ID))
+			if err != nil {
+				lc.Log.Error("failed to pack categoryTokenCount call", catalog.ID, err)
+				return err
+			}
+			batch = append(batch, rpc.BatchElem{
+				Method: "eth_call",
+				Args: []interface{}{
+					map[string]interface{}{"to": contract, "data": hexutil.Bytes(data)},
+					block,
+				},
+				Result: &results[i],
+			})
+		}
+		if err := nc.environment.RpcClient.BatchCallContext(ctx, batch); err != nil {
+			lc.Log.Error("failed to batch categoryTokenCount calls", err)
+			return err
+		}
+		for i, catalog := range catalogs[start:end] {
+			if batch[i].Error != nil {
+				lc.Log.Error("failed to read category token count", catalog.ID, batch[i].Error)
+				continue
+			}
+			out, err := nc.mailioNftContractAbi.Unpack("categoryTokenCount", results[i])
+			if err != nil || len(out) == 0 {
+				lc.Log.Error("failed to unpack category token count", catalog.ID, err)
+				continue
+			}
+			if count, ok := out[0].(*big.Int); ok {
+				counts[catalog.ID] = int(count.Int64())
+			}
+		}
+	}
+
+	nc.tokenCounts.mutex.Lock()
+	defer nc.tokenCounts.mutex.Unlock()
+	for id, count := range counts {
+		nc.tokenCounts.counts[id] = count
+	}
+	nc.tokenCounts.lastBlock = latest
+	nc.tokenCounts.refreshed = time.Now()
+	return nil
+}
+
+// pollMints increases token counts by mint events since the last block included in counts
+func (nc *NftCatalogService) pollMints() error {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCountTimeout)
+	defer cancel()
+
+	latest, err := nc.environment.EthClient.BlockNumber(ctx)
+	if err != nil {
+		lc.Log.Error("failed to read latest block number", err)
+		return err
+	}
+	nc.tokenCounts.mutex.RLock()
+	from := nc.tokenCounts.lastBlock + 1
+	nc.tokenCounts.mutex.RUnlock()
+
+	for from <= latest {
+		to := from + tokenCountMaxBlockRange - 1
+		if to > latest {
+			to = latest
+		}
+		iter, err := nc.environment.NftContract.FilterTransfer(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, []common.Address{{}}, nil, nil)
+		if err != nil {
+			lc.Log.Error("failed to filter mint events", err)
+			return err
+		}
+		minted := map[string]int{}
+		for iter.Next() {
+			category, err := nc.environment.NftContract.TokenIdToCategoryId(&bind.CallOpts{Context: ctx}, iter.Event.TokenId)
+			if err != nil {
+				iter.Close()
+				lc.Log.Error("failed to read category of minted token", iter.Event.TokenId, err)
+				return err
+			}
+			id, err := xid.FromBytes(category[:])
+			if err != nil {
+				continue
+			}
+			minted[id.String()]++
+		}
+		if err := iter.Error(); err != nil {
+			iter.Close()
+			lc.Log.Error("failed to iterate mint events", err)
+			return err
+		}
+		iter.Close()
+
+		nc.tokenCounts.mutex.Lock()
+		if nc.tokenCounts.lastBlock != from-1 {
+			// counts were refreshed meanwhile (already include these blocks)
+			nc.tokenCounts.mutex.Unlock()
+			return nil
+		}
+		for id, count := range minted {
+			nc.tokenCounts.counts[id] += count
+		}
+		nc.tokenCounts.lastBlock = to
+		nc.tokenCounts.mutex.Unlock()
+		from = to + 1
+	}
+	return nil
+}
+
+// tokenCount returns cached number of minted tokens of the catalog. Catalogs missing in the cache are read
+// from the contract. Returns 0 if the contract can't be reached
+func (nc *NftCatalogService) tokenCount(catalogId string) int {
+	if count, ok := nc.tokenCounts.get(catalogId); ok {
+		return count
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+	// read at the last block included in the cache, so following mint events are not counted twice
+	nc.tokenCounts.mutex.RLock()
+	lastBlock := nc.tokenCounts.lastBlock
+	nc.tokenCounts.mutex.RUnlock()
+	opts := &bind.CallOpts{Context: ctx}
+	if lastBlock > 0 {
+		opts.BlockNumber = new(big.Int).SetUint64(lastBlock)
+	}
+	count, err := nc.environment.NftContract.CategoryTokenCount(opts, categoryId(catalogId))
+	if err != nil || count == nil {
+		lc.Log.Error("failed to retrieve category count on blockchain", catalogId, err)
+		return 0
+	}
+	if lastBlock > 0 {
+		nc.tokenCounts.setAt(catalogId, int(count.Int64()), lastBlock)
+	}
+	return int(count.Int64())
+}
+
+// cachedTokenCount returns cached number of minted tokens of the catalog (0 if not cached)
+func (nc *NftCatalogService) cachedTokenCount(catalogId string) int {
+	count, _ := nc.tokenCounts.get(catalogId)
+	return count
+}
+
+// categoryId converts catalog ID (xid) to the contract category ID
+func categoryId(catalogId string) [12]byte {
+	var catId [12]byte
+	xidID, _ := xid.FromString(catalogId)
+	copy(catId[:], xidID.Bytes())
+	return catId
+}
+
+// tokenCountsConfig returns token counts config with defaults of missing values
+func tokenCountsConfig() lc.TokenCountsSubConfig {
+	conf := lc.Conf.TokenCounts
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = 15
+	}
+	if conf.RefreshInterval <= 0 {
+		conf.RefreshInterval = 3600
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = 100
+	}
+	return conf
+}
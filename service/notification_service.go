@@ -0,0 +1,42 @@
+package service
+
+import (
+	"github.com/go-resty/resty/v2"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+)
+
+type NotificationService struct {
+	httpClient *resty.Client
+}
+
+func NewNotificationService() *NotificationService {
+	return &NotificationService{
+		httpClient: resty.New().SetTimeout(model.DefaultTimeout),
+	}
+}
+
+// Notify admins about an event that needs their attention
+// message is always logged and in addition sent to the webhook (if configured)
+func (ns *NotificationService) Notify(subject string, message string) {
+	lc.Log.Warn("admin notification", subject, message)
+
+	webhookUrl := lc.Conf.Notifications.WebhookUrl
+	if webhookUrl == "" {
+		return
+	}
+	go func() {
+		resp, err := ns.httpClient.R().
+			SetHeader("Content-Type", "application/json").
+			SetBody(map[string]string{
+				"text": "*" + subject + "*\n" + message,
+			}).Post(webhookUrl)
+		if err != nil {
+			lc.Log.Error("failed to send admin notification", err)
+			return
+		}
+		if resp.StatusCode() >= 300 {
+			lc.Log.Error("failed to send admin notification", string(resp.Body()))
+		}
+	}()
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/ipfs/go-datastore"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mitchellh/mapstructure"
+)
+
+type PinHealthService struct {
+	environment         *model.Environment
+	nftCatalogService   *NftCatalogService
+	nftClaimService     *NftClaimService
+	pinService          *PinService
+	notificationService *NotificationService
+	gatewayClient       *resty.Client
+	checkMutex          sync.Mutex // single check at a time
+}
+
+func NewPinHealthService(environment *model.Environment, nftCatalogService *NftCatalogService, nftClaimService *NftClaimService, pinService *PinService, notificationService *NotificationService) *PinHealthService {
+	gatewayTimeout := lc.Conf.Pinning.GatewayTimeout
+	if gatewayTimeout <= 0 {
+		gatewayTimeout = 30
+	}
+	return &PinHealthService{
+		environment:         environment,
+		nftCatalogService:   nftCatalogService,
+		nftClaimService:     nftClaimService,
+		pinService:          pinService,
+		notificationService: notificationService,
+		gatewayClient: resty.New().
+			SetHostURL(strings.TrimSuffix(lc.Conf.BlockchainConfig.InfuraIpfsGateway, "/")).
+			SetTimeout(time.Duration(gatewayTimeout) * time.Second),
+	}
+}
+
+// Watch periodically checks health of all pins (blocking, run as goroutine)
+// disabled if pinning health_interval is not configured
+func (phs *PinHealthService) Watch() {
+	interval := lc.Conf.Pinning.HealthInterval
+	if interval <= 0 {
+		return
+	}
+	for {
+		if _, err := phs.Check(); err != nil {
+			lc.Log.Error("failed to check pin health", err)
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
+// Check checks that every CID the bridge depends on (catalog images and claim metadata) is pinned
+// with all pinning providers and retrievable through the IPFS gateway. Missing pins are re-pinned.
+// Report is stored and admins are notified about content that couldn't be recovered
+func (phs *PinHealthService) Check() (*model.PinHealthReport, error) {
+	phs.checkMutex.Lock()
+	defer phs.checkMutex.Unlock()
+
+	report := &model.PinHealthReport{
+		Repinned:  []*model.PinHealthIssue{},
+		Unhealthy: []*model.PinHealthIssue{},
+		Started:   time.Now().UnixMilli(),
+	}
+	cids, err := phs.collectCids()
+	if err != nil {
+		return nil, err
+	}
+	for cid, source := range cids {
+		report.Checked++
+		issue := phs.checkCid(cid, source)
+		if issue == nil {
+			report.Healthy++
+		} else if issue.Error == "" {
+			report.Repinned = append(report.Repinned, issue)
+		} else {
+			report.Unhealthy = append(report.Unhealthy, issue)
+		}
+	}
+	report.Finished = time.Now().UnixMilli()
+
+	if err := phs.putReport(report); err != nil {
+		lc.Log.Error("failed to store pin health report", err)
+		return nil, err
+	}
+	if len(report.Unhealthy) > 0 {
+		phs.notificationService.Notify("Unhealthy IPFS pins", fmt.Sprintf("%d of %d CIDs couldn't be recovered, e.g. %s (%s): %s",
+			len(report.Unhealthy), report.Checked, report.Unhealthy[0].Cid, report.Unhealthy[0].Source, report.Unhealthy[0].Error))
+	}
+	lc.Log.Info("pin health checked", "checked", report.Checked, "healthy", report.Healthy, "repinned", len(report.Repinned), "unhealthy", len(report.Unhealthy))
+	return report, nil
+}
+
+// GetReport returns the latest pin health report or model.ErrNotFound if no check was run yet
+func (phs *PinHealthService) GetReport() (*model.PinHealthReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	m, err := phs.environment.DB.Get(ctx, util.CreateKey(model.PinHealthTable, model.PinHealthReportID))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrNotFound
+		}
+		lc.Log.Error("failed to get pin health report", err)
+		return nil, err
+	}
+	reportMap, err := util.UnmarshalFromBytes(m)
+	if err != nil {
+		lc.Log.Error("failed to unmarshal pin health report", err)
+		return nil, err
+	}
+	var report model.PinHealthReport
+	err = mapstructure.Decode(reportMap, &report)
+	return &report, err
+}
+
+// collectCids returns all CIDs the bridge depends on together with their source
+func (phs *PinHealthService) collectCids() (map[string]string, error) {
+	cids := map[string]string{}
+	catalogs, err := phs.nftCatalogService.ListAllCatalogs(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, catalog := range catalogs {
+		if cid := strings.TrimPrefix(catalog.ImageLink, "ipfs://"); cid != "" {
+			cids[cid] = "catalog/" + catalog.ID
+		}
+	}
+	claims, err := phs.nftClaimService.ListClaims(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, claim := range claims {
+		source := "claim/" + claim.WalletAddress + "_" + claim.CatalogId
+		if strings.HasPrefix(claim.TokenUri, "ipfs://") {
+			cids[strings.TrimPrefix(claim.TokenUri, "ipfs://")] = source
+		}
+		if claim.MetadataCid != "" {
+			cids[claim.MetadataCid] = source
+		}
+	}
+	return cids, nil
+}
+
+// checkCid returns nil if content is healthy, issue without error if it was re-pinned
+// or issue with the error if it couldn't be recovered
+func (phs *PinHealthService) checkCid(cid string, source string) *model.PinHealthIssue {
+	ctx, cancel := context.WithTimeout(context.Background(), pinTimeout)
+	defer cancel()
+
+	issue := &model.PinHealthIssue{
+		Cid:     cid,
+		Source:  source,
+		Missing: []string{},
+	}
+	pinnedTo := []string{}
+	for _, pinner := range phs.environment.Pinning.Pinners() {
+		pinned, err := pinner.IsPinned(ctx, cid)
+		if err != nil {
+			lc.Log.Warn("failed to check pin", pinner.Name(), cid, err)
+		}
+		if pinned {
+			pinnedTo = append(pinnedTo, pinner.Name())
+		} else {
+			issue.Missing = append(issue.Missing, pinner.Name())
+		}
+	}
+
+	resp, err := phs.gatewayClient.R().SetContext(ctx).Head("/ipfs/" + cid)
+	issue.Retrievable = err == nil && resp.StatusCode() == 200
+
+	if len(issue.Missing) == 0 {
+		if issue.Retrievable {
+			return nil
+		}
+		issue.Error = "not retrievable through the IPFS gateway"
+		return issue
+	}
+
+	// providers fetch content by CID from the IPFS network (including the providers still pinning it)
+	locations, err := phs.environment.Pinning.Replicate(ctx, cid, source, pinnedTo)
+	if sErr := phs.pinService.SetLocations(cid, source, locations); sErr != nil {
+		lc.Log.Error("failed to record pin locations", cid, sErr)
+	}
+	if err != nil {
+		issue.Error = err.Error()
+		return issue
+	}
+	if len(locations) < len(phs.environment.Pinning.Pinners()) {
+		issue.Error = "re-pinning failed with some of the providers"
+	} else if !issue.Retrievable {
+		issue.Error = "re-pinned, but not retrievable through the IPFS gateway"
+	}
+	return issue
+}
+
+func (phs *PinHealthService) putReport(report *model.PinHealthReport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	m, err := util.MarshalToBytes(report)
+	if err != nil {
+		return err
+	}
+	return phs.environment.DB.Put(ctx, util.CreateKey(model.PinHealthTable, model.PinHealthReportID), m)
+}
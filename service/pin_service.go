@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	lc "github.com/mailio/mailio-nft-server/config"
+	"github.com/mailio/mailio-nft-server/model"
+	"github.com/mailio/mailio-nft-server/util"
+	"github.com/mitchellh/mapstructure"
+)
+
+// timeout of uploading and replicating content to all pinning providers
+const pinTimeout = 3 * time.Minute
+
+type PinService struct {
+	environment *model.Environment
+}
+
+func NewPinService(environment *model.Environment) *PinService {
+	return &PinService{
+		environment: environment,
+	}
+}
+
+// Add uploads content to IPFS, replicates it to all pinning providers and records where it's pinned.
+// CID is computed locally (if content fits a single chunk), so already pinned content is not uploaded again.
+// Returns model.ErrCidMismatch if pinning provider returns a different CID
+func (ps *PinService) Add(filename string, data []byte, cidVersion int) (*model.Pin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pinTimeout)
+	defer cancel()
+
+	cid, cidErr := util.ComputeCID(data, cidVersion)
+	if cidErr == nil {
+		existing, err := ps.GetPin(cid)
+		if err == nil {
+			return existing, nil
+		}
+		if err != model.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	addedCid, locations, err := ps.environment.Pinning.Add(ctx, filename, data, cidVersion)
+	if addedCid == "" {
+		lc.Log.Error("failed to upload to IPFS", filename, err)
+		return nil, err
+	}
+	if cidErr == nil && addedCid != cid {
+		lc.Log.Error("CID returned by IPFS doesn't match the computed CID", addedCid, cid)
+		return nil, model.ErrCidMismatch
+	}
+	pin := &model.Pin{
+		Cid:       addedCid,
+		Name:      filename,
+		Size:      len(data),
+		Locations: locations,
+		Created:   time.Now().UnixMilli(),
+		Modified:  time.Now().UnixMilli(),
+	}
+	// record also partially replicated content
+	if pErr := ps.put(pin); pErr != nil {
+		lc.Log.Error("failed to store pin", pin.Cid, pErr)
+		return nil, pErr
+	}
+	if err != nil {
+		lc.Log.Error("failed to replicate pin", pin.Cid, err)
+		return nil, err
+	}
+	return pin, nil
+}
+
+// Unpin removes content from all pinning providers it's pinned to. Pin record is removed
+// once content is unpinned everywhere, otherwise remaining locations are recorded
+func (ps *PinService) Unpin(cid string) (*model.Pin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pinTimeout)
+	defer cancel()
+
+	pin, err := ps.GetPin(cid)
+	if err == model.ErrNotFound {
+		// content pinned outside of the bridge
+		locations := []string{}
+		for _, p := range ps.environment.Pinning.Pinners() {
+			locations = append(locations, p.Name())
+		}
+		pin = &model.Pin{Cid: cid, Locations: locations}
+	} else if err != nil {
+		return nil, err
+	}
+
+	remaining, err := ps.environment.Pinning.Unpin(ctx, cid, pin.Locations)
+	if len(remaining) == 0 {
+		if dErr := ps.delete(cid); dErr != nil {
+			return nil, dErr
+		}
+		pin.Locations = remaining
+		return pin, nil
+	}
+	pin.Locations = remaining
+	pin.Modified = time.Now().UnixMilli()
+	if pErr := ps.put(pin); pErr != nil {
+		lc.Log.Error("failed to store pin", pin.Cid, pErr)
+	}
+	return pin, err
+}
+
+// SetLocations records pinning providers content is pinned to (creates the pin record if it doesn't exist)
+func (ps *PinService) SetLocations(cid string, name string, locations []string) error {
+	pin, err := ps.GetPin(cid)
+	if err == model.ErrNotFound {
+		pin = &model.Pin{Cid: cid, Name: name, Created: time.Now().UnixMilli()}
+	} else if err != nil {
+		return err
+	}
+	pin.Locations = locations
+	pin.Modified = time.Now().UnixMilli()
+	if err := ps.put(pin); err != nil {
+		lc.Log.Error("failed to store pin", pin.Cid, err)
+		return err
+	}
+	return nil
+}
+
+// GetPin returns pin record by CID or model.ErrNotFound
+func (ps *PinService) GetPin(cid string) (*model.Pin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	m, err := ps.environment.DB.Get(ctx, util.CreateKey(model.PinTable, cid))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrNotFound
+		}
+		lc.Log.Error("failed to get pin", err)
+		return nil, err
+	}
+	pinMap, err := util.UnmarshalFromBytes(m)
+	if err != nil {
+		lc.Log.Error("failed to unmarshal pin", err)
+		return nil, err
+	}
+	var pin model.Pin
+	err = mapstructure.Decode(pinMap, &pin)
+	return &pin, err
+}
+
+// ListPins lists all pin records
+func (ps *PinService) ListPins() ([]*model.Pin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	q := query.Query{
+		Prefix: "/" + model.PinTable,
+	}
+	qRes, err := ps.environment.DB.Query(ctx, q)
+	if err != nil {
+		lc.Log.Error("failed to list pins", err)
+		return nil, err
+	}
+	defer qRes.Close()
+
+	res, err := qRes.Rest()
+	if err != nil {
+		lc.Log.Error("failed to list pins", err)
+		return nil, err
+	}
+	pins := []*model.Pin{}
+	for _, r := range res {
+		pinMap, err := util.UnmarshalFromBytes(r.Value)
+		if err != nil {
+			lc.Log.Error("failed to unmarshal pin", err)
+			return nil, err
+		}
+		var pin model.Pin
+		mapstructure.Decode(pinMap, &pin)
+		pins = append(pins, &pin)
+	}
+	return pins, nil
+}
+
+func (ps *PinService) put(pin *model.Pin) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	m, err := util.MarshalToBytes(pin)
+	if err != nil {
+		return err
+	}
+	return ps.environment.DB.Put(ctx, util.CreateKey(model.PinTable, pin.Cid), m)
+}
+
+func (ps *PinService) delete(cid string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
+	defer cancel()
+
+	err := ps.environment.DB.Delete(ctx, util.CreateKey(model.PinTable, cid))
+	if err != nil {
+		lc.Log.Error("failed to delete pin", cid, err)
+	}
+	return err
+}
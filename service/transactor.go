@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	lc "github.com/mailio/mailio-nft-server/config"
+)
+
+// parsePrivateKey parses hex encoded private key and returns it with its wallet address
+func parsePrivateKey(privateKeyHex string) (*ecdsa.PrivateKey, common.Address, error) {
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		lc.Log.Error("failed to parse private key", err)
+		return nil, common.Address{}, err
+	}
+	publicKeyECDSA, ok := privateKey.Public().(*ecdsa.PublicKey)
+	if !ok {
+		lc.Log.Error("error casting public key to ECDSA")
+		return nil, common.Address{}, errors.New("error casting public key to ECDSA")
+	}
+	return privateKey, crypto.PubkeyToAddress(*publicKeyECDSA), nil
+}
+
+// newTransactOpts creates options for a transaction signed with the given private key
+// gas limit is left empty so it's estimated by simulating the transaction (reverts are caught before sending)
+func newTransactOpts(ctx context.Context, ethClient *ethclient.Client, privateKeyHex string) (*bind.TransactOpts, error) {
+	privateKey, fromAddress, err := parsePrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := ethClient.PendingNonceAt(ctx, fromAddress)
+	if err != nil {
+		lc.Log.Error("failed to get nonce", err)
+		return nil, err
+	}
+	gasPrice, err := ethClient.SuggestGasPrice(ctx)
+	if err != nil {
+		lc.Log.Error("failed to get gas price", err)
+		return nil, err
+	}
+	chainID, err := ethClient.ChainID(ctx)
+	if err != nil {
+		lc.Log.Error("failed to get chain id", err)
+		return nil, err
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	if err != nil {
+		lc.Log.Error("failed to create tx options", err)
+		return nil, err
+	}
+	auth.Nonce = new(big.Int).SetUint64(nonce)
+	auth.Value = big.NewInt(0) // in wei
+	auth.GasPrice = gasPrice
+	auth.Context = ctx
+	auth.From = fromAddress
+	return auth, nil
+}
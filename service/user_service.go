@@ -8,11 +8,9 @@ import (
 	"github.com/chryscloud/go-microkit-plugins/crypto"
 	jwtModels "github.com/chryscloud/go-microkit-plugins/models/jwt"
 	"github.com/dgrijalva/jwt-go"
-	"github.com/ipfs/go-datastore"
 	lc "github.com/mailio/mailio-nft-server/config"
 	"github.com/mailio/mailio-nft-server/model"
 	"github.com/mailio/mailio-nft-server/util"
-	"github.com/mitchellh/mapstructure"
 )
 
 type UserService struct {
@@ -56,23 +54,11 @@ func (us *UserService) Login(email string, password string) (*model.JwtTokenOutp
 func (us *UserService) getUser(email string) (*model.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), model.DefaultTimeout)
 	defer cancel()
-	key := util.CreateKey(model.UserTable, email)
-	m, err := us.environment.DB.Get(ctx, key)
-	if err != nil {
-		if err == datastore.ErrNotFound {
-			return nil, model.ErrNotFound
-		}
-		lc.Log.Error("failed to get catalog", err)
-		return nil, err
-	}
-	userMap, err := util.UnmarshalFromBytes(m)
-	if err != nil {
-		lc.Log.Error("failed to unmarshal catalog", err)
-		return nil, err
+	user, err := us.environment.Users.Get(ctx, email)
+	if err != nil && err != model.ErrNotFound {
+		lc.Log.Error("failed to get user", err)
 	}
-	var usr model.User
-	err = mapstructure.Decode(userMap, &usr)
-	return &usr, err
+	return user, err
 }
 
 // putUser usperts a new user (insert is exists, or update existing)
@@ -87,10 +73,9 @@ func (us *UserService) putUser(user *model.User) (*model.User, error) {
 	user.Modified = time.Now().UnixMilli()
 	user.Created = time.Now().UnixMilli()
 
-	m, err := util.MarshalToBytes(user)
-	err = us.environment.DB.Put(ctx, util.CreateKey(model.UserTable, id), m)
+	err := us.environment.Users.Put(ctx, user)
 	if err != nil {
-		lc.Log.Error("failed to create new catalog", err)
+		lc.Log.Error("failed to create new user", err)
 		return nil, err
 	}
 	return user, nil
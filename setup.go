@@ -5,25 +5,28 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/go-resty/resty/v2"
+	"github.com/ethereum/go-ethereum/rpc"
 	leveldb "github.com/ipfs/go-ds-leveldb"
-	"github.com/mailio/mailio-nft-server/config"
 	lc "github.com/mailio/mailio-nft-server/config"
 	"github.com/mailio/mailio-nft-server/model"
 	nft "github.com/mailio/mailio-nft-server/onchain/mailionft"
+	"github.com/mailio/mailio-nft-server/pinning"
+	"github.com/mailio/mailio-nft-server/repository"
 )
 
 // setupEnvironment init of datastore
 func setupEnvironment(confg *lc.Config) *model.Environment {
-	db := setupDatastore(confg)
-	ethClient := setupEthClient(confg)
+	repositories := setupRepositories(confg)
+	rpcClient := setupRpcClient(confg)
+	ethClient := ethclient.NewClient(rpcClient)
 	contract := loadContract(ethClient, confg.BlockchainConfig.MailioNFTProxyAddress)
-	ipfsInfuraClient := setupIPFSInfuraClient()
+	replicator := setupPinning(confg)
 	env := &model.Environment{
-		DB:               db,
-		EthClient:        ethClient,
-		NftContract:      contract,
-		IpfsInfuraClient: ipfsInfuraClient,
+		Repositories: repositories,
+		EthClient:    ethClient,
+		RpcClient:    rpcClient,
+		NftContract:  contract,
+		Pinning:      replicator,
 	}
 
 	return env
@@ -39,9 +42,9 @@ func loadContract(client *ethclient.Client, address string) *nft.Mailionft {
 	return contractInstance
 }
 
-// setup ETH client (connection to the blockchain)
-func setupEthClient(config *lc.Config) *ethclient.Client {
-	cl, err := ethclient.Dial(config.BlockchainConfig.Endpoint)
+// setup RPC client (connection to the blockchain) shared by the ETH client
+func setupRpcClient(config *lc.Config) *rpc.Client {
+	cl, err := rpc.Dial(config.BlockchainConfig.Endpoint)
 	if err != nil {
 		panic(err)
 	}
@@ -61,14 +64,52 @@ func setupDatastore(config *lc.Config) *leveldb.Datastore {
 	return ds
 }
 
-func setupIPFSInfuraClient() *resty.Client {
-	return resty.New().
-		SetHostURL(config.Conf.BlockchainConfig.InfuraIpfsApiEndpoint).
-		SetBasicAuth(config.Conf.BlockchainConfig.InfuraKey, config.Conf.BlockchainConfig.InfuraSecret)
+// setupRepositories creates repositories and the key-value DB in the configured storage backend
+// (leveldb datastore is opened only by the leveldb backend)
+func setupRepositories(config *lc.Config) *model.Repositories {
+	var db *leveldb.Datastore
+	if config.Storage.Backend == "" || config.Storage.Backend == repository.BackendLevelDB {
+		db = setupDatastore(config)
+	}
+	repositories, err := repository.New(config.Storage, db)
+	if err != nil {
+		panic(err)
+	}
+	return repositories
+}
+
+// setupPinning creates pinners of all configured pinning providers (infura from blockchain config if none configured)
+func setupPinning(config *lc.Config) *pinning.Replicator {
+	providers := config.Pinning.Providers
+	if len(providers) == 0 {
+		providers = []lc.PinningProviderSubConfig{
+			{
+				Type:     pinning.ProviderInfura,
+				Endpoint: config.BlockchainConfig.InfuraIpfsApiEndpoint,
+				Key:      config.BlockchainConfig.InfuraKey,
+				Secret:   config.BlockchainConfig.InfuraSecret,
+			},
+		}
+	}
+	pinners := []pinning.Pinner{}
+	for _, provider := range providers {
+		pinner, err := pinning.New(provider)
+		if err != nil {
+			panic(err)
+		}
+		pinners = append(pinners, pinner)
+	}
+	return pinning.NewReplicator(pinners, config.Pinning.MinReplicas)
 }
 
-// tearDownEnvironemnt closes the leveldb datastore
+// tearDownEnvironemnt closes the storage backend and the key-value DB
 func tearDownEnvironment(env *model.Environment) {
+	if env.Repositories == nil {
+		return
+	}
+	if env.Closer != nil {
+		env.Closer.Close()
+	}
 	if env.DB != nil {
 		env.DB.Close()
 	}
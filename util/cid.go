@@ -0,0 +1,82 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// max size of content stored by IPFS as a single chunk (default chunker size-262144)
+const ipfsChunkSize = 262144
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ComputeCID computes the CID IPFS assigns to the content when added with default settings:
+// CIDv0 (dag-pb UnixFS file, base58btc) or CIDv1 (raw leaves, base32).
+// Only content fitting into a single chunk is supported
+func ComputeCID(data []byte, cidVersion int) (string, error) {
+	if len(data) > ipfsChunkSize {
+		return "", errors.New("content larger than a single IPFS chunk")
+	}
+	switch cidVersion {
+	case 0:
+		// UnixFS Data{Type: File, Data: data, filesize: len(data)}
+		unixfs := []byte{0x08, 0x02}
+		if len(data) > 0 {
+			unixfs = append(unixfs, 0x12)
+			unixfs = appendUvarint(unixfs, uint64(len(data)))
+			unixfs = append(unixfs, data...)
+		}
+		unixfs = append(unixfs, 0x18)
+		unixfs = appendUvarint(unixfs, uint64(len(data)))
+		// PBNode{Data: unixfs} without links
+		node := []byte{0x0a}
+		node = appendUvarint(node, uint64(len(unixfs)))
+		node = append(node, unixfs...)
+		return base58Encode(sha256Multihash(node)), nil
+	case 1:
+		// version 1, raw codec, sha2-256 multihash
+		cid := append([]byte{0x01, 0x55}, sha256Multihash(data)...)
+		return "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(cid)), nil
+	}
+	return "", errors.New("unsupported CID version")
+}
+
+// sha256Multihash returns sha2-256 multihash of the data
+func sha256Multihash(data []byte) []byte {
+	digest := sha256.Sum256(data)
+	return append([]byte{0x12, 0x20}, digest[:]...)
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	for x >= 0x80 {
+		buf = append(buf, byte(x)|0x80)
+		x >>= 7
+	}
+	return append(buf, byte(x))
+}
+
+// base58Encode encodes bytes with the bitcoin base58 alphabet
+func base58Encode(input []byte) string {
+	x := new(big.Int).SetBytes(input)
+	base := big.NewInt(58)
+	mod := new(big.Int)
+	encoded := []byte{}
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+	// leading zero bytes are encoded as leading 1s
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var weiPerNative = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// NativeToWei converts amount in native currency (e.g. "0.5" MATIC) to wei
+func NativeToWei(amount string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return nil, errors.New("invalid amount: " + amount)
+	}
+	if r.Sign() < 0 {
+		return nil, errors.New("amount must not be negative: " + amount)
+	}
+	r.Mul(r, new(big.Rat).SetInt(weiPerNative))
+	return new(big.Int).Quo(r.Num(), r.Denom()), nil
+}
+
+// WeiToNative formats amount in wei as amount in native currency (e.g. 500000000000000000 -> "0.5")
+func WeiToNative(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+	s := new(big.Rat).SetFrac(wei, weiPerNative).FloatString(18)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,17 @@
+package util
+
+import "encoding/base64"
+
+// EncodeCursor encodes a position within a list (e.g. datastore key) as an opaque continuation cursor
+func EncodeCursor(position string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+// DecodeCursor returns the position encoded by EncodeCursor
+func DecodeCursor(cursor string) (string, error) {
+	position, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	return string(position), nil
+}
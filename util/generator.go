@@ -1,6 +1,7 @@
 package util
 
 import (
+	"bytes"
 	"encoding/json"
 
 	"github.com/ipfs/go-datastore"
@@ -27,3 +28,25 @@ func UnmarshalFromBytes(obj []byte) (interface{}, error) {
 	err := json.Unmarshal(obj, &out)
 	return out, err
 }
+
+// CanonicalJSON serializes object into a stable form (sorted keys, no insignificant whitespace,
+// no HTML escaping), so equal objects always produce equal bytes (and equal IPFS CIDs)
+func CanonicalJSON(obj interface{}) ([]byte, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber() // keep numbers exactly as serialized
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(generic); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}